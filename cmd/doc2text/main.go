@@ -2,14 +2,39 @@ package main
 
 import (
 	"context"
+	"doc2text/internal/core/abstraction/cache"
 	"doc2text/internal/core/abstraction/convert"
 	"doc2text/internal/core/abstraction/cqrs"
-	"doc2text/internal/core/abstraction/download"
+	"doc2text/internal/core/abstraction/extract"
+	"doc2text/internal/core/abstraction/job"
 	"doc2text/internal/core/abstraction/logger"
+	"doc2text/internal/core/abstraction/normalize"
+	"doc2text/internal/core/abstraction/queue"
 	"doc2text/internal/core/abstraction/recognize"
+	"doc2text/internal/core/abstraction/split"
+	"doc2text/internal/core/abstraction/storage"
+	"doc2text/internal/core/abstraction/unpack"
+	"doc2text/internal/core/usecase/extractjobs"
 	"doc2text/internal/core/usecase/extracttext"
+	"doc2text/internal/infrastructure/boltjobs"
+	"doc2text/internal/infrastructure/diskcache"
+	"doc2text/internal/infrastructure/imagefit"
+	"doc2text/internal/infrastructure/imagenorm"
+	"doc2text/internal/infrastructure/imagerotate"
+	"doc2text/internal/infrastructure/kafka"
+	"doc2text/internal/infrastructure/memcache"
 	"doc2text/internal/infrastructure/nativeconv"
+	"doc2text/internal/infrastructure/nats"
+	"doc2text/internal/infrastructure/ocrcache"
+	"doc2text/internal/infrastructure/ocrchain"
+	"doc2text/internal/infrastructure/officetext"
+	"doc2text/internal/infrastructure/pdfsplit"
+	"doc2text/internal/infrastructure/pdftext"
+	"doc2text/internal/infrastructure/plaintext"
 	"doc2text/internal/infrastructure/s3"
+	"doc2text/internal/infrastructure/tesseract"
+	"doc2text/internal/infrastructure/unarchive"
+	"doc2text/internal/infrastructure/webhook"
 	"doc2text/internal/infrastructure/yocr"
 	"doc2text/internal/infrastructure/zaplogger"
 	"doc2text/internal/presentation/api"
@@ -17,11 +42,15 @@ import (
 	config "doc2text/internal/presentation/config"
 	ocrv1 "doc2text/internal/presentation/proto/ocr/v1"
 	"doc2text/internal/presentation/server/ocr/v1"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -36,15 +65,36 @@ func main() {
 
 	convertor := registerConverter()
 
-	downloader := registerDownloader(cfg, logger)
+	store := registerStorage(cfg, logger)
 
-	recognizer := registerRecognizer(cfg)
+	recognizer, closeRecognizer := registerRecognizer(cfg, logger)
+	defer closeRecognizer()
 
-	bus := registerCqrs(CqrsOptions{Logger: logger, Converter: convertor, Downloader: downloader, Recognizer: recognizer})
+	extractors := registerExtractors(cfg)
+
+	unpackers := registerUnpackers(cfg)
+
+	normalizers := registerNormalizers(cfg)
+
+	splitters := registerSplitters(cfg)
+
+	jobStore, closeJobStore := registerJobStore(cfg, logger)
+	defer closeJobStore()
+
+	bus, jobs := registerCqrs(CqrsOptions{Logger: logger, Converter: convertor, Storage: store, Sidecars: sidecarOptions(cfg), Recognizer: recognizer, Extractors: extractors, Unpackers: unpackers, Normalizers: normalizers, Splitters: splitters, PageConcurrency: cfg.Extract.PageConcurrency, BatchConcurrency: cfg.Batch.Concurrency, JobStore: jobStore, Jobs: jobOptions(cfg, store)})
+
+	stopJobs := startJobs(jobs, logger)
+	defer stopJobs()
 
 	grpcSrv := startGRPCServer(cfg, bus, logger)
 
-	httpSrv := startHTTPServer(cfg.HttpServer.Addr, "/healthz", logger)
+	stopKafka := startKafkaWorkers(cfg, bus, logger)
+	defer stopKafka()
+
+	stopNATS := startNATSWorkers(cfg, bus, logger)
+	defer stopNATS()
+
+	httpSrv := startHTTPServer(cfg, bus, "/healthz", logger)
 
 	waitForShutdown(logger, grpcSrv, httpSrv)
 }
@@ -59,6 +109,10 @@ func mustLoadConfig() *config.Config {
 }
 
 func startGRPCServer(cfg *config.Config, bus *cqrs.Bus, l logger.Logger) *grpc.Server {
+	if !cfg.GRpcServer.Enabled {
+		l.Info("gRPC disabled")
+		return nil
+	}
 	lis, err := net.Listen("tcp", cfg.GRpcServer.Addr)
 	if err != nil {
 		l.Error("listen: %v", err)
@@ -75,9 +129,20 @@ func startGRPCServer(cfg *config.Config, bus *cqrs.Bus, l logger.Logger) *grpc.S
 	} else {
 		l.Info("Auth: OIDC not configured; gRPC runs without auth")
 	}
+	if interceptor, err := auth.NewStreamAuthInterceptor(cfg.OIDC); err != nil {
+		l.Error("auth init: %v", err)
+		os.Exit(1)
+	} else if interceptor != nil {
+		serverOpts = append(serverOpts, grpc.StreamInterceptor(interceptor))
+	}
 
 	grpcSrv := grpc.NewServer(serverOpts...)
-	ocrv1.RegisterOcrServiceServer(grpcSrv, ocr.New(bus))
+	ocrv1.RegisterOcrServiceServer(grpcSrv, ocr.New(bus, ocr.Options{
+		MaxUploadSize: int64(cfg.GRpcServer.MaxUploadSize),
+		MaxBatchItems: cfg.Batch.MaxItems,
+		Jobs:          cfg.Jobs.Enabled,
+		CallbackHosts: cfg.Webhook.AllowedHosts,
+	}))
 
 	l.Info("gRPC listening on %s", cfg.GRpcServer.Addr)
 	go func() {
@@ -97,8 +162,8 @@ func registerConverter() convert.FileConverter {
 	converter := nativeconv.NewFileConverter()
 	return converter
 }
-func registerDownloader(cfg *config.Config, l logger.Logger) download.Downloader {
-	downloader, err := s3.NewDownloader(s3.Config{
+func registerStorage(cfg *config.Config, l logger.Logger) storage.Storage {
+	st, err := s3.NewStorage(s3.Config{
 		Endpoint:  cfg.S3.Endpoint,
 		AccessKey: cfg.S3.AccessKey,
 		SecretKey: cfg.S3.SecretKey,
@@ -106,38 +171,564 @@ func registerDownloader(cfg *config.Config, l logger.Logger) download.Downloader
 		UseSSL:    cfg.S3.UseSSL,
 	})
 	if err != nil {
-		l.Error("s3.NewDownloader: %v", err)
+		l.Error("s3.NewStorage: %v", err)
 		os.Exit(1)
 	}
-	return downloader
+	return st
+}
+
+func registerRecognizer(cfg *config.Config, l logger.Logger) (recognize.Recognizer, func()) {
+	defaults := append([]string{cfg.Recognizer.Backend}, cfg.Recognizer.Fallback...)
+	routes := make([]ocrchain.Route, 0, len(cfg.Recognizer.Routes))
+	for _, s := range cfg.Recognizer.Routes {
+		rt, err := ocrchain.ParseRoute(s)
+		if err != nil {
+			l.Error("ocrchain.ParseRoute: %v", err)
+			os.Exit(1)
+		}
+		routes = append(routes, rt)
+	}
+
+	used := map[string]bool{}
+	for _, n := range defaults {
+		used[n] = true
+	}
+	for _, rt := range routes {
+		for _, n := range rt.Backends {
+			used[n] = true
+		}
+	}
+
+	backends := map[string]recognize.Recognizer{}
+	cleanup := func() {}
+	if used["yandex"] {
+		var yandex recognize.Recognizer
+		yandex, cleanup = registerYandexRecognizer(cfg, l)
+		backends["yandex"] = imagefit.Wrap(yandex, yandexLimits(cfg))
+	}
+	if used["tesseract"] {
+		backends["tesseract"] = imagefit.Wrap(registerTesseractRecognizer(cfg, l), imagefit.Limits{MaxSide: cfg.Tesseract.MaxImageSide})
+	}
+
+	recognizer, err := ocrchain.New(ocrchain.Options{
+		Backends:   backends,
+		Default:    defaults,
+		Routes:     routes,
+		FallbackOn: cfg.Recognizer.FallbackOn,
+		Logger:     l,
+	})
+	if err != nil {
+		l.Error("ocrchain.New: %v", err)
+		os.Exit(1)
+	}
+	l.Info("Recognizer: chain %v, %d route(s), fallback on %v", defaults, len(routes), cfg.Recognizer.FallbackOn)
+	recognizer = imagerotate.Wrap(recognizer, imagerotate.Options{
+		AutoRotate:    cfg.Image.AutoRotate,
+		MinConfidence: cfg.Image.AutoRotateMinConfidence,
+		Deskew:        cfg.Image.Deskew,
+		MaxSkew:       cfg.Image.MaxSkew,
+		Logger:        l,
+	})
+
+	store, closeCache := registerCache(cfg, l)
+	if store == nil {
+		return recognizer, cleanup
+	}
+	recognizer = ocrcache.Wrap(recognizer, store, ocrcache.Options{
+		TTL:         cfg.Cache.TTL,
+		Fingerprint: recognizerFingerprint(cfg),
+		Logger:      l,
+	})
+	return recognizer, func() {
+		closeCache()
+		cleanup()
+	}
+}
+
+func registerCache(cfg *config.Config, l logger.Logger) (cache.Store, func()) {
+	switch cfg.Cache.Backend {
+	case "memory":
+		l.Info("Cache: in memory, up to %d bytes, ttl %s", cfg.Cache.MaxBytes, cfg.Cache.TTL)
+		return memcache.New(int64(cfg.Cache.MaxBytes)), func() {}
+	case "disk":
+		store, err := diskcache.Open(diskcache.Options{Dir: cfg.Cache.Dir, Logger: l})
+		if err != nil {
+			l.Error("diskcache.Open: %v", err)
+			os.Exit(1)
+		}
+		l.Info("Cache: directory %s, ttl %s", cfg.Cache.Dir, cfg.Cache.TTL)
+		return store, func() {
+			if err := store.Close(); err != nil {
+				l.Error("cache close: %v", err)
+			}
+		}
+	case "s3":
+		s3cfg := s3.Config{
+			Endpoint:  cfg.S3.Endpoint,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			Bucket:    cfg.S3.Bucket,
+			UseSSL:    cfg.S3.UseSSL,
+		}
+		if cfg.Cache.S3Bucket != "" {
+			s3cfg.Bucket = cfg.Cache.S3Bucket
+		}
+		store, err := s3.NewCache(s3cfg, cfg.Cache.S3Prefix)
+		if err != nil {
+			l.Error("s3.NewCache: %v", err)
+			os.Exit(1)
+		}
+		l.Info("Cache: s3://%s/%s, ttl %s", s3cfg.Bucket, cfg.Cache.S3Prefix, cfg.Cache.TTL)
+		return store, func() {}
+	}
+	return nil, func() {}
 }
-func registerRecognizer(cfg *config.Config) recognize.Recognizer {
+
+// recognizerFingerprint names every setting that changes recognized text,
+// so cached results are not reused across them.
+func recognizerFingerprint(cfg *config.Config) string {
+	// Images over the limits are scaled down, which changes the text.
+	yl := yandexLimits(cfg)
+	parts := []string{
+		"chain=" + strings.Join(append([]string{cfg.Recognizer.Backend}, cfg.Recognizer.Fallback...), ","),
+		"routes=" + strings.Join(cfg.Recognizer.Routes, ";"),
+		fmt.Sprintf("yandex=%s,%s,%s,%s,%g,%d,%d", cfg.Yandex.API, yandexEndpoint(cfg), cfg.Yandex.Model, strings.Join(cfg.Yandex.Languages, ","), cfg.Yandex.MinConfidence, yl.MaxBytes, yl.MaxPixels),
+		fmt.Sprintf("tesseract=%s,%d,%g,%d", strings.Join(cfg.Tesseract.Languages, ","), cfg.Tesseract.PSM, cfg.Tesseract.MinConfidence, cfg.Tesseract.MaxImageSide),
+		fmt.Sprintf("image=%t,%g,%t,%g", cfg.Image.AutoRotate, cfg.Image.AutoRotateMinConfidence, cfg.Image.Deskew, cfg.Image.MaxSkew),
+	}
+	return strings.Join(parts, "|")
+}
+
+// yandexLimits defaults to the documented limits: 1 MB per image for
+// batchAnalyze, 10 MB for recognizeText.
+func yandexLimits(cfg *config.Config) imagefit.Limits {
+	maxBytes := int64(cfg.Yandex.MaxImageBytes)
+	if maxBytes == 0 {
+		maxBytes = 1 << 20
+		if cfg.Yandex.API == "recognizeText" {
+			maxBytes = 10 << 20
+		}
+	}
+	return imagefit.Limits{MaxBytes: maxBytes, MaxPixels: cfg.Yandex.MaxImagePixels}
+}
+
+func registerTesseractRecognizer(cfg *config.Config, l logger.Logger) recognize.Recognizer {
+	l.Info("Recognizer: local tesseract (%s, langs=%v, psm=%d)", cfg.Tesseract.Binary, cfg.Tesseract.Languages, cfg.Tesseract.PSM)
+	return tesseract.New(tesseract.Options{
+		Binary:        cfg.Tesseract.Binary,
+		Languages:     cfg.Tesseract.Languages,
+		PSM:           cfg.Tesseract.PSM,
+		MinConfidence: cfg.Tesseract.MinConfidence,
+	})
+}
+
+func registerYandexRecognizer(cfg *config.Config, l logger.Logger) (recognize.Recognizer, func()) {
+	creds, cleanup := registerYandexCredentials(cfg, l)
+	endpoint := yandexEndpoint(cfg)
+	if cfg.Yandex.API == "recognizeText" {
+		l.Info("Yandex OCR: recognizeText API (%s)", endpoint)
+		return yocr.NewTextRecognizer(yocr.TextOptions{
+			OcrEndpoint: endpoint,
+			FolderID:    cfg.Yandex.FolderID,
+			Model:       cfg.Yandex.Model,
+			Languages:   cfg.Yandex.Languages,
+			Credentials: creds,
+
+			AsyncPDF:          cfg.Yandex.AsyncPDF,
+			AsyncEndpoint:     cfg.Yandex.AsyncEndpoint,
+			ResultEndpoint:    cfg.Yandex.ResultEndpoint,
+			OperationEndpoint: cfg.Yandex.OperationEndpoint,
+			PollInterval:      cfg.Yandex.PollInterval,
+			MaxPollInterval:   cfg.Yandex.MaxPollInterval,
+		}), cleanup
+	}
+	l.Info("Yandex OCR: batchAnalyze API (%s)", endpoint)
 	recognizer := yocr.New(yocr.Options{
-		OcrEndpoint: cfg.Yandex.Endpoint,
-		ApiKey:      cfg.Yandex.APIKey,
-		FolderID:    cfg.Yandex.FolderID,
-		Model:       cfg.Yandex.Model,
-		Languages:   cfg.Yandex.Languages,
+		OcrEndpoint:   endpoint,
+		FolderID:      cfg.Yandex.FolderID,
+		Model:         cfg.Yandex.Model,
+		Languages:     cfg.Yandex.Languages,
+		Credentials:   creds,
+		MinConfidence: cfg.Yandex.MinConfidence,
 	})
-	return recognizer
+	return recognizer, cleanup
+}
+
+// yandexEndpoint is YC_ENDPOINT, or the public endpoint of YC_API.
+func yandexEndpoint(cfg *config.Config) string {
+	switch {
+	case cfg.Yandex.Endpoint != "":
+		return cfg.Yandex.Endpoint
+	case cfg.Yandex.API == "recognizeText":
+		return yocr.TextEndpoint
+	}
+	return yocr.VisionEndpoint
+}
+
+func registerYandexCredentials(cfg *config.Config, l logger.Logger) (yocr.Credentials, func()) {
+	switch {
+	case cfg.Yandex.SAKeyFile != "":
+		key, err := yocr.LoadServiceAccountKey(cfg.Yandex.SAKeyFile)
+		if err != nil {
+			l.Error("yocr.LoadServiceAccountKey: %v", err)
+			os.Exit(1)
+		}
+		creds, err := yocr.NewServiceAccountCredentials(yocr.ServiceAccountOptions{
+			Key:           key,
+			TokenEndpoint: cfg.Yandex.IAMEndpoint,
+		})
+		if err != nil {
+			l.Error("yocr.NewServiceAccountCredentials: %v", err)
+			os.Exit(1)
+		}
+		l.Info("Yandex OCR: service account key auth (sa=%s)", key.ServiceAccountID)
+		return creds, creds.Close
+	case cfg.Yandex.IAMToken != "":
+		l.Info("Yandex OCR: static IAM token auth")
+		return yocr.IAMToken(cfg.Yandex.IAMToken), func() {}
+	default:
+		return yocr.APIKey(cfg.Yandex.APIKey), func() {}
+	}
+}
+
+func registerExtractors(cfg *config.Config) map[string]extract.Extractor {
+	extractors := map[string]extract.Extractor{}
+	if cfg.Extract.PDFTextLayer {
+		extractors["application/pdf"] = pdftext.New(pdftext.Options{MinChars: cfg.Extract.PDFMinChars})
+	}
+	if cfg.Extract.Office {
+		office := officetext.New()
+		for _, m := range officetext.MimeTypes {
+			extractors[m] = office
+		}
+	}
+	if cfg.Extract.Text {
+		// Mail attachments are read with whatever is registered here.
+		text := plaintext.New(plaintext.Options{Attachments: extractors})
+		for _, m := range plaintext.MimeTypes {
+			extractors[m] = text
+		}
+	}
+	return extractors
+}
+
+func registerUnpackers(cfg *config.Config) map[string]unpack.Unpacker {
+	unpackers := map[string]unpack.Unpacker{}
+	if !cfg.Archive.Enabled {
+		return unpackers
+	}
+	u := unarchive.New(unarchive.Options{
+		MaxEntries:   cfg.Archive.MaxEntries,
+		MaxTotalSize: int64(cfg.Archive.MaxTotalSize),
+		MaxDepth:     cfg.Archive.MaxDepth,
+	})
+	for _, m := range unarchive.MimeTypes {
+		unpackers[m] = u
+	}
+	return unpackers
+}
+
+func registerNormalizers(cfg *config.Config) map[string]normalize.Normalizer {
+	normalizers := map[string]normalize.Normalizer{}
+	if !cfg.Image.Normalize {
+		return normalizers
+	}
+	n := imagenorm.New(imagenorm.Options{MaxPages: cfg.Image.MaxPages, HEICConverter: cfg.Image.HEICConverter, MaxPixels: cfg.Image.MaxPixels})
+	for _, m := range imagenorm.MimeTypes {
+		normalizers[m] = n
+	}
+	return normalizers
+}
+
+func registerSplitters(cfg *config.Config) map[string]split.Splitter {
+	splitters := map[string]split.Splitter{}
+	if cfg.Extract.PDFSplit {
+		splitters[pdfsplit.MimePDF] = pdfsplit.New()
+	}
+	return splitters
 }
 
 type CqrsOptions struct {
-	Logger     logger.Logger
-	Converter  convert.FileConverter
-	Downloader download.Downloader
-	Recognizer recognize.Recognizer
+	Logger    logger.Logger
+	Converter convert.FileConverter
+	Storage   storage.Storage
+	// Sidecars, if set, stores the outcome of every object next to it.
+	Sidecars    *extracttext.SidecarOptions
+	Recognizer  recognize.Recognizer
+	Extractors  map[string]extract.Extractor
+	Unpackers   map[string]unpack.Unpacker
+	Normalizers map[string]normalize.Normalizer
+	Splitters   map[string]split.Splitter
+	// PageConcurrency bounds how many pages of a document are recognized
+	// at once.
+	PageConcurrency int
+	// BatchConcurrency bounds how many items of a batch run at once.
+	BatchConcurrency int
+	// JobStore enables the job API when set.
+	JobStore job.Store
+	Jobs     extractjobs.Options
 }
 
-func registerCqrs(o CqrsOptions) *cqrs.Bus {
-	extractH := extracttext.NewHandler(o.Converter, o.Downloader, o.Logger, o.Recognizer)
+func registerCqrs(o CqrsOptions) (*cqrs.Bus, *extractjobs.Service) {
+	var extractH extracttext.Handler = extracttext.NewHandler(o.Converter, o.Storage, o.Logger, o.Recognizer, o.Extractors, o.Unpackers, o.Normalizers, o.Splitters, o.PageConcurrency)
+	if o.Sidecars != nil {
+		extractH = extracttext.NewSidecarHandler(extractH, o.Storage, o.Logger, *o.Sidecars)
+	}
+	extractH = extracttext.NewCoalescingHandler(extractH)
 	bus := cqrs.NewBus()
 	cqrs.RegisterQuery(bus, extractH)
-	return bus
+	cqrs.RegisterQuery(bus, extracttext.NewBatchHandler(extractH, o.BatchConcurrency))
+
+	if o.JobStore == nil {
+		return bus, nil
+	}
+	jobs := extractjobs.New(o.JobStore, extractH, o.Logger, o.Jobs)
+	cqrs.RegisterCommand(bus, extractjobs.SubmitHandler{Service: jobs})
+	cqrs.RegisterCommand(bus, extractjobs.CancelHandler{Service: jobs})
+	cqrs.RegisterQuery(bus, extractjobs.GetHandler{Service: jobs})
+	cqrs.RegisterQuery(bus, extractjobs.ListHandler{Service: jobs})
+	return bus, jobs
+}
+
+func registerJobStore(cfg *config.Config, l logger.Logger) (job.Store, func()) {
+	if !cfg.Jobs.Enabled {
+		return nil, func() {}
+	}
+	store, err := boltjobs.Open(cfg.Jobs.StorePath)
+	if err != nil {
+		l.Error("boltjobs.Open: %v", err)
+		os.Exit(1)
+	}
+	return store, func() {
+		if err := store.Close(); err != nil {
+			l.Error("job store close: %v", err)
+		}
+	}
 }
 
-func startHTTPServer(httpAddr string, healthPath string, l logger.Logger) *http.Server {
-	httpMux := api.NewRouter(api.Options{HealthCheckPath: healthPath})
+func sidecarOptions(cfg *config.Config) *extracttext.SidecarOptions {
+	if !cfg.Sidecar.Enabled {
+		return nil
+	}
+	return &extracttext.SidecarOptions{
+		Bucket:  cfg.Sidecar.Bucket,
+		Prefix:  cfg.Sidecar.Prefix,
+		Version: buildVersion(),
+		Encode:  ocr.ResultJSON,
+	}
+}
+
+// buildVersion is the module version, or the VCS revision for builds from
+// a checkout.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+func jobOptions(cfg *config.Config, st storage.Storage) extractjobs.Options {
+	o := extractjobs.Options{
+		Workers:      cfg.Jobs.Workers,
+		Timeout:      cfg.Jobs.Timeout,
+		ResultTTL:    cfg.Jobs.ResultTTL,
+		PollInterval: cfg.Jobs.PollInterval,
+	}
+	if cfg.Webhook.Secret != "" {
+		o.Callback = extractjobs.CallbackOptions{
+			Sender:      webhook.New(webhook.Options{Secret: cfg.Webhook.Secret, Timeout: cfg.Webhook.Timeout}),
+			Encode:      ocr.JobJSON,
+			MaxAttempts: cfg.Webhook.MaxAttempts,
+			Backoff:     cfg.Webhook.Backoff,
+			MaxBackoff:  cfg.Webhook.MaxBackoff,
+		}
+	}
+	// With sidecars on, notified objects get theirs instead.
+	if cfg.Notify.Enabled && !cfg.Sidecar.Enabled {
+		o.Output = st
+	}
+	return o
+}
+
+func startJobs(jobs *extractjobs.Service, l logger.Logger) func() {
+	if jobs == nil {
+		return func() {}
+	}
+	if err := jobs.Start(context.Background()); err != nil {
+		l.Error("jobs start: %v", err)
+		os.Exit(1)
+	}
+	l.Info("Jobs: background workers started")
+	return jobs.Stop
+}
+
+func startKafkaWorkers(cfg *config.Config, bus *cqrs.Bus, l logger.Logger) func() {
+	if !cfg.Kafka.Enabled {
+		return func() {}
+	}
+	pub := kafka.NewPublisher(kafka.PublisherOptions{Brokers: cfg.Kafka.Brokers})
+	ctx, cancel := context.WithCancel(context.Background())
+	var (
+		wg   sync.WaitGroup
+		subs []queue.Subscription
+	)
+	for range cfg.Kafka.Consumers {
+		sub := kafka.NewSubscription(kafka.SubscriptionOptions{
+			Brokers: cfg.Kafka.Brokers,
+			GroupID: cfg.Kafka.GroupID,
+			Topic:   cfg.Kafka.RequestTopic,
+		})
+		subs = append(subs, sub)
+		w := ocr.NewWorker(bus, sub, pub, ocr.WorkerOptions{
+			ResultTopic:     cfg.Kafka.ResultTopic,
+			DeadLetterTopic: cfg.Kafka.DeadLetterTopic,
+			MaxAttempts:     cfg.Kafka.MaxAttempts,
+			Backoff:         cfg.Kafka.Backoff,
+			Logger:          l,
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.Run(ctx); err != nil {
+				l.Error("kafka worker: %v", err)
+			}
+		}()
+	}
+	l.Info("Kafka: %d consumer(s) on %s -> %s", cfg.Kafka.Consumers, cfg.Kafka.RequestTopic, cfg.Kafka.ResultTopic)
+
+	return func() {
+		cancel()
+		wg.Wait()
+		for _, sub := range subs {
+			if err := sub.Close(); err != nil {
+				l.Error("kafka reader close: %v", err)
+			}
+		}
+		if err := pub.Close(); err != nil {
+			l.Error("kafka writer close: %v", err)
+		}
+	}
+}
+
+func startNATSWorkers(cfg *config.Config, bus *cqrs.Bus, l logger.Logger) func() {
+	if !cfg.NATS.Enabled {
+		return func() {}
+	}
+	conn, err := nats.Connect(nats.Options{URL: cfg.NATS.URL, Name: "doc2text", CredsFile: cfg.NATS.CredsFile})
+	if err != nil {
+		l.Error("nats: %v", err)
+		os.Exit(1)
+	}
+	pub := conn.Publisher()
+	ctx, cancel := context.WithCancel(context.Background())
+	var (
+		wg   sync.WaitGroup
+		subs []queue.Subscription
+	)
+	run := func(sub queue.Subscription, o ocr.WorkerOptions) {
+		subs = append(subs, sub)
+		o.Backoff = cfg.NATS.Backoff
+		o.Logger = l
+		w := ocr.NewWorker(bus, sub, pub, o)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.Run(ctx); err != nil {
+				l.Error("nats worker: %v", err)
+			}
+		}()
+	}
+
+	if cfg.NATS.RequestSubject != "" {
+		for range cfg.NATS.Consumers {
+			sub, err := conn.Subscribe(cfg.NATS.RequestSubject, cfg.NATS.QueueGroup)
+			if err != nil {
+				l.Error("%v", err)
+				os.Exit(1)
+			}
+			// Requesters get the outcome, failures included, as the reply.
+			run(sub, ocr.WorkerOptions{MaxAttempts: 1})
+		}
+		l.Info("NATS: %d responder(s) on %s (group %s)", cfg.NATS.Consumers, cfg.NATS.RequestSubject, cfg.NATS.QueueGroup)
+	}
+
+	if cfg.NATS.JobSubject != "" {
+		if cfg.NATS.CreateStream {
+			subjects := []string{cfg.NATS.JobSubject}
+			for _, s := range []string{cfg.NATS.ResultSubject, cfg.NATS.DeadLetterSubject} {
+				if s != "" {
+					subjects = append(subjects, s)
+				}
+			}
+			if err := conn.EnsureStream(ctx, nats.StreamOptions{Name: cfg.NATS.Stream, Subjects: subjects, MaxAge: cfg.NATS.StreamMaxAge}); err != nil {
+				l.Error("%v", err)
+				os.Exit(1)
+			}
+		}
+		for range cfg.NATS.Consumers {
+			sub, err := conn.Consume(ctx, nats.ConsumerOptions{
+				Stream:     cfg.NATS.Stream,
+				Durable:    cfg.NATS.Durable,
+				Subject:    cfg.NATS.JobSubject,
+				MaxDeliver: cfg.NATS.MaxDeliver,
+				AckWait:    cfg.NATS.AckWait,
+			})
+			if err != nil {
+				l.Error("%v", err)
+				os.Exit(1)
+			}
+			run(sub, ocr.WorkerOptions{
+				ResultTopic:     cfg.NATS.ResultSubject,
+				DeadLetterTopic: cfg.NATS.DeadLetterSubject,
+				MaxAttempts:     cfg.NATS.MaxDeliver,
+			})
+		}
+		l.Info("NATS: %d consumer(s) %s on %s/%s -> %s", cfg.NATS.Consumers, cfg.NATS.Durable, cfg.NATS.Stream, cfg.NATS.JobSubject, cfg.NATS.ResultSubject)
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+		for _, sub := range subs {
+			if err := sub.Close(); err != nil {
+				l.Error("nats unsubscribe: %v", err)
+			}
+		}
+		if err := conn.Close(); err != nil {
+			l.Error("nats close: %v", err)
+		}
+	}
+}
+
+func startHTTPServer(cfg *config.Config, bus *cqrs.Bus, healthPath string, l logger.Logger) *http.Server {
+	httpAddr := cfg.HttpServer.Addr
+	opts := api.Options{HealthCheckPath: healthPath, Bus: bus}
+	if cfg.Notify.Enabled {
+		opts.Notify = &api.NotifyOptions{
+			Path:         cfg.Notify.Path,
+			AuthToken:    cfg.Notify.AuthToken,
+			Bucket:       cfg.S3.Bucket,
+			Prefix:       cfg.Notify.Prefix,
+			Suffixes:     cfg.Notify.Suffixes,
+			OutputSuffix: cfg.Notify.OutputSuffix,
+			Logger:       l,
+		}
+		if cfg.Sidecar.Enabled {
+			opts.Notify.OutputSuffix = ""
+			opts.Notify.IgnoreSuffixes = []string{extracttext.SidecarTextSuffix, extracttext.SidecarJSONSuffix}
+		}
+		l.Info("Notify: bucket events on %s for %s/%s*", cfg.Notify.Path, cfg.S3.Bucket, cfg.Notify.Prefix)
+	}
+	httpMux := api.NewRouter(opts)
 	srv := &http.Server{Addr: httpAddr, Handler: httpMux}
 	l.Info("HTTP listening on %s (health: %s)", httpAddr, healthPath)
 	go func() {
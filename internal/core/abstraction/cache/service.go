@@ -0,0 +1,15 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store keeps opaque values by key. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Get reports false when the key is missing or has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value for ttl; zero keeps it until it is evicted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
@@ -0,0 +1,23 @@
+package callback
+
+import "context"
+
+// Sender delivers a notification to a URL supplied by the caller.
+type Sender interface {
+	// Send returns an error for anything but a 2xx response; Response is
+	// filled in whenever the receiver answered.
+	Send(ctx context.Context, req Request) (Response, error)
+}
+
+type Request struct {
+	URL  string
+	Body []byte
+	// ID names what the notification is about (a job ID) and Attempt counts
+	// deliveries of it from 1, so receivers can drop duplicates.
+	ID      string
+	Attempt int
+}
+
+type Response struct {
+	Status int
+}
@@ -0,0 +1,24 @@
+package extract
+
+import (
+	"context"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+// Extractor reads text straight from a document format without OCR.
+type Extractor interface {
+	Extract(ctx context.Context, req Request) (Response, error)
+}
+
+type Request struct {
+	Content  []byte
+	MimeType string
+}
+
+type Response struct {
+	Pages []recognize.Page
+	// NeedsOCR lists indexes into Pages that carry no usable text, e.g.
+	// scanned pages of a PDF.
+	NeedsOCR []int
+}
@@ -0,0 +1,103 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Final reports whether a job in this state will not change any more.
+func (s State) Final() bool {
+	return s == StateSucceeded || s == StateFailed || s == StateCancelled
+}
+
+// Callback delivery states.
+const (
+	CallbackPending   = "pending"
+	CallbackDelivered = "delivered"
+	CallbackFailed    = "failed"
+)
+
+var ErrNotFound = errors.New("job not found")
+
+type Job struct {
+	ID            string
+	State         State
+	ObjectKey     string
+	MinConfidence *float64
+	Backend       string
+	// Result is the encoded outcome of a succeeded job; Error explains a
+	// failed one.
+	Result []byte
+	Error  string
+	// CancelRequested is set when a running job is asked to stop.
+	CancelRequested bool
+	CreatedAt       time.Time
+	StartedAt       time.Time
+	FinishedAt      time.Time
+	// ExpiresAt is when a finished job is removed from the store.
+	ExpiresAt time.Time
+	// IdempotencyKey, if set, is unique among stored jobs.
+	IdempotencyKey string
+	// OutputKey is the object the recognized text is written to.
+	OutputKey string
+
+	// CallbackURL is notified once the job finishes. Callback is the
+	// delivery state, NextCallbackAt when a pending one is due, and
+	// Deliveries every attempt made so far.
+	CallbackURL    string
+	Callback       string
+	NextCallbackAt time.Time
+	Deliveries     []Delivery
+}
+
+// Delivery is one attempt to notify the callback URL.
+type Delivery struct {
+	At       time.Time
+	Duration time.Duration
+	// Status is the HTTP status, zero when there was no response.
+	Status int
+	Error  string
+}
+
+// Store persists jobs. Implementations must be safe for concurrent use.
+type Store interface {
+	// Create stores j, unless a job with the same IdempotencyKey exists;
+	// that job is returned instead.
+	Create(ctx context.Context, j Job) (Job, error)
+	Get(ctx context.Context, id string) (Job, error)
+	// Update applies fn to the stored job and saves it atomically. The job
+	// is left unchanged when fn returns an error.
+	Update(ctx context.Context, id string, fn func(*Job) error) (Job, error)
+	// Claim marks the oldest queued job running and returns it, or
+	// ErrNotFound when nothing is queued.
+	Claim(ctx context.Context, now time.Time) (Job, error)
+	// List returns jobs newest first, starting after the job with ID after.
+	// An empty state matches every state.
+	List(ctx context.Context, req ListRequest) ([]Job, error)
+	// Requeue puts jobs left running by a previous process back in the
+	// queue and returns how many there were.
+	Requeue(ctx context.Context) (int, error)
+	// DeleteExpired removes finished jobs that expired before now, except
+	// those whose callback is still pending.
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+	// DueCallbacks returns up to limit jobs whose pending callback is due
+	// at now, the longest waiting first.
+	DueCallbacks(ctx context.Context, now time.Time, limit int) ([]Job, error)
+}
+
+type ListRequest struct {
+	State State
+	Limit int
+	After string
+}
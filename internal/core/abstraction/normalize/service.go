@@ -0,0 +1,30 @@
+package normalize
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrConverterMissing is returned when the external program a format needs
+// is configured but not installed.
+var ErrConverterMissing = errors.New("image converter is not installed")
+
+// Normalizer re-encodes an image the recognizers do not accept into one
+// they do, one image per page for multi-page formats.
+type Normalizer interface {
+	Normalize(ctx context.Context, req Request) (Response, error)
+}
+
+type Request struct {
+	Content  []byte
+	MimeType string
+}
+
+type Response struct {
+	Images []Image
+}
+
+type Image struct {
+	Content  []byte
+	MimeType string
+}
@@ -0,0 +1,40 @@
+package queue
+
+import (
+	"context"
+	"time"
+)
+
+type Message struct {
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// Delivery is a received message. Ack marks it handled; a message that is
+// never acked is delivered again, so consumers must tolerate duplicates.
+type Delivery struct {
+	Message
+	// Attempt counts deliveries of the message from 1 when the broker
+	// tracks them, and is 0 otherwise.
+	Attempt int
+	Ack     func(ctx context.Context) error
+	// Nak hands the message back for redelivery after delay. It is nil
+	// when the broker redelivers only after a restart.
+	Nak func(ctx context.Context, delay time.Duration) error
+	// Respond answers a request/reply message. It is nil when nobody is
+	// waiting for an answer.
+	Respond func(ctx context.Context, m Message) error
+}
+
+// Subscription yields messages of one topic or subject, one at a time.
+type Subscription interface {
+	Receive(ctx context.Context) (Delivery, error)
+	Close() error
+}
+
+type Publisher interface {
+	// Publish returns once the broker has durably accepted the message.
+	Publish(ctx context.Context, topic string, m Message) error
+	Close() error
+}
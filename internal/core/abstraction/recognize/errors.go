@@ -0,0 +1,14 @@
+package recognize
+
+import "errors"
+
+// Error classes recognizers wrap their failures with so callers can decide
+// whether another backend is worth trying.
+var (
+	ErrQuotaExceeded   = errors.New("recognizer quota exceeded")
+	ErrUnavailable     = errors.New("recognizer unavailable")
+	ErrUnsupportedType = errors.New("recognizer does not support this content type")
+	ErrTooLarge        = errors.New("content exceeds recognizer limits")
+	// ErrUnknownBackend rejects a backend hint naming no configured backend.
+	ErrUnknownBackend = errors.New("unknown recognizer backend")
+)
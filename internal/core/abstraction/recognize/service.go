@@ -9,8 +9,75 @@ type Recognizer interface {
 type Request struct {
 	ContentBase64 string
 	MimeType      string
+	// MinConfidence overrides the recognizer's configured threshold when set.
+	MinConfidence *float64
+	// Backend asks a routing recognizer to try this backend first.
+	Backend string
 }
 
 type Response struct {
 	ExtractedText string
+	Pages         []Page
+	FilteredWords int
+	FilteredLines int
+	// Backend names the recognizer that produced the result.
+	Backend string
+	// Cached is set when the result was reused rather than recognized.
+	Cached bool
+}
+
+// Page sources.
+const (
+	SourceOCR       = "ocr"
+	SourceTextLayer = "text_layer"
+	SourceDocument  = "document"
+)
+
+type Page struct {
+	Width  int64
+	Height int64
+	Blocks []Block
+	// Source tells how the page text was obtained.
+	Source string
+	// Label names the page in its document, e.g. a sheet name or "slide 3".
+	Label string
+	// Rotation is how many degrees clockwise the image was turned before
+	// recognition (EXIF orientation plus auto-rotation); Skew is the
+	// deskew correction in degrees. Coordinates refer to the turned image.
+	Rotation int
+	Skew     float64
+}
+
+type Block struct {
+	BoundingBox Polygon
+	Lines       []Line
+	Languages   []Language
+}
+
+type Line struct {
+	Text        string
+	BoundingBox Polygon
+	Confidence  float64
+	Words       []Word
+}
+
+type Word struct {
+	Text        string
+	BoundingBox Polygon
+	Confidence  float64
+	Languages   []Language
+}
+
+type Language struct {
+	Code       string
+	Confidence float64
+}
+
+type Polygon struct {
+	Vertices []Point
+}
+
+type Point struct {
+	X int64
+	Y int64
 }
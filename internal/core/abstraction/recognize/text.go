@@ -0,0 +1,78 @@
+package recognize
+
+import "strings"
+
+// JoinText renders the layout tree as plain text: one line per row, pages
+// separated by an empty line.
+func JoinText(pages []Page) string {
+	var b strings.Builder
+	for i, p := range pages {
+		if i > 0 && b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		for _, blk := range p.Blocks {
+			for _, ln := range blk.Lines {
+				if ln.Text != "" {
+					b.WriteString(ln.Text)
+					b.WriteByte('\n')
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// FilterByConfidence drops words and lines whose confidence is below min and
+// rebuilds line text from the words that survive. Lines left without words
+// are dropped as well. It returns the filtered pages and how many words and
+// lines were removed.
+func FilterByConfidence(pages []Page, min float64) ([]Page, int, int) {
+	if min <= 0 {
+		return pages, 0, 0
+	}
+	var droppedWords, droppedLines int
+	out := make([]Page, 0, len(pages))
+	for _, p := range pages {
+		page := p
+		page.Blocks = make([]Block, 0, len(p.Blocks))
+		for _, b := range p.Blocks {
+			block := b
+			block.Lines = make([]Line, 0, len(b.Lines))
+			for _, ln := range b.Lines {
+				if ln.Confidence < min {
+					droppedWords += len(ln.Words)
+					droppedLines++
+					continue
+				}
+				if len(ln.Words) == 0 {
+					block.Lines = append(block.Lines, ln)
+					continue
+				}
+				line := ln
+				line.Words = make([]Word, 0, len(ln.Words))
+				texts := make([]string, 0, len(ln.Words))
+				for _, w := range ln.Words {
+					if w.Confidence < min {
+						droppedWords++
+						continue
+					}
+					line.Words = append(line.Words, w)
+					if w.Text != "" {
+						texts = append(texts, w.Text)
+					}
+				}
+				if len(line.Words) == 0 {
+					droppedLines++
+					continue
+				}
+				line.Text = strings.Join(texts, " ")
+				block.Lines = append(block.Lines, line)
+			}
+			if len(block.Lines) > 0 {
+				page.Blocks = append(page.Blocks, block)
+			}
+		}
+		out = append(out, page)
+	}
+	return out, droppedWords, droppedLines
+}
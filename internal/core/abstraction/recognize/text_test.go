@@ -0,0 +1,134 @@
+package recognize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func words(spec ...any) []Word {
+	var out []Word
+	for i := 0; i < len(spec); i += 2 {
+		out = append(out, Word{Text: spec[i].(string), Confidence: spec[i+1].(float64)})
+	}
+	return out
+}
+
+func line(conf float64, ws []Word) Line {
+	texts := make([]string, 0, len(ws))
+	for _, w := range ws {
+		texts = append(texts, w.Text)
+	}
+	return Line{Text: strings.Join(texts, " "), Confidence: conf, Words: ws}
+}
+
+func pageTexts(pages []Page) [][]string {
+	out := make([][]string, len(pages))
+	for i, p := range pages {
+		out[i] = []string{}
+		for _, b := range p.Blocks {
+			for _, l := range b.Lines {
+				out[i] = append(out[i], l.Text)
+			}
+		}
+	}
+	return out
+}
+
+func TestFilterByConfidence(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		pages  []Page
+		min    float64
+		want   [][]string
+		blocks []int
+		words  int
+		lines  int
+	}{
+		{
+			name:  "threshold off",
+			pages: []Page{{Blocks: []Block{{Lines: []Line{line(0.1, words("a", 0.1))}}}}},
+			min:   0,
+			want:  [][]string{{"a"}},
+		},
+		{
+			name:   "words under the threshold",
+			pages:  []Page{{Blocks: []Block{{Lines: []Line{line(0.8, words("keep", 0.9, "drop", 0.4, "at", 0.5))}}}}},
+			min:    0.5,
+			want:   [][]string{{"keep at"}},
+			blocks: []int{1},
+			words:  1,
+		},
+		{
+			name:   "line under the threshold",
+			pages:  []Page{{Blocks: []Block{{Lines: []Line{line(0.3, words("x", 0.9, "y", 0.9)), line(0.9, words("z", 0.9))}}}}},
+			min:    0.5,
+			want:   [][]string{{"z"}},
+			blocks: []int{1},
+			words:  2,
+			lines:  1,
+		},
+		{
+			name:   "line emptied by its words",
+			pages:  []Page{{Blocks: []Block{{Lines: []Line{line(0.6, words("x", 0.2, "y", 0.1)), line(0.9, words("z", 0.9))}}}}},
+			min:    0.5,
+			want:   [][]string{{"z"}},
+			blocks: []int{1},
+			words:  2,
+			lines:  1,
+		},
+		{
+			name:   "line without words",
+			pages:  []Page{{Blocks: []Block{{Lines: []Line{{Text: "whole line", Confidence: 0.7}}}}}},
+			min:    0.5,
+			want:   [][]string{{"whole line"}},
+			blocks: []int{1},
+		},
+		{
+			name: "emptied blocks go, pages stay",
+			pages: []Page{
+				{Blocks: []Block{{Lines: []Line{line(0.1, words("a", 0.1))}}, {Lines: []Line{line(0.9, words("b", 0.9))}}}},
+				{Blocks: []Block{{Lines: []Line{line(0.9, words("c", 0.2))}}}},
+			},
+			min:    0.5,
+			want:   [][]string{{"b"}, {}},
+			blocks: []int{1, 0},
+			words:  2,
+			lines:  2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			before := pageTexts(tc.pages)
+			got, droppedWords, droppedLines := FilterByConfidence(tc.pages, tc.min)
+			if texts := pageTexts(got); !reflect.DeepEqual(texts, tc.want) {
+				t.Errorf("lines = %q, want %q", texts, tc.want)
+			}
+			if droppedWords != tc.words || droppedLines != tc.lines {
+				t.Errorf("dropped %d words, %d lines; want %d, %d", droppedWords, droppedLines, tc.words, tc.lines)
+			}
+			if tc.blocks != nil {
+				for i, p := range got {
+					if len(p.Blocks) != tc.blocks[i] {
+						t.Errorf("page %d has %d blocks, want %d", i+1, len(p.Blocks), tc.blocks[i])
+					}
+				}
+			}
+			if after := pageTexts(tc.pages); !reflect.DeepEqual(after, before) {
+				t.Errorf("input changed to %q", after)
+			}
+		})
+	}
+}
+
+func TestJoinText(t *testing.T) {
+	pages := []Page{
+		{Blocks: []Block{{Lines: []Line{{Text: "one"}, {Text: ""}, {Text: "two"}}}}},
+		{Blocks: []Block{{Lines: []Line{{Text: "three"}}}}},
+	}
+	if got, want := JoinText(pages), "one\ntwo\n\nthree\n"; got != want {
+		t.Fatalf("JoinText() = %q, want %q", got, want)
+	}
+	if got := JoinText(nil); got != "" {
+		t.Fatalf("JoinText(nil) = %q", got)
+	}
+}
@@ -0,0 +1,29 @@
+package split
+
+import "context"
+
+// Splitter cuts pages out of a multi-page document, one document per page,
+// so that they can be recognized on their own.
+type Splitter interface {
+	Split(ctx context.Context, req Request) (Response, error)
+}
+
+type Request struct {
+	Content  []byte
+	MimeType string
+	// Pages are zero-based page indexes; empty means every page.
+	Pages []int
+}
+
+type Response struct {
+	// Pages come in the order requested.
+	Pages []Page
+	// Total is the page count of the whole document.
+	Total int
+}
+
+type Page struct {
+	Index    int
+	Content  []byte
+	MimeType string
+}
@@ -0,0 +1,37 @@
+package storage
+
+import "context"
+
+type Storage interface {
+	GetInfo(ctx context.Context, req GetInfoRequest) (GetInfoResponse, error)
+	GetFile(ctx context.Context, req GetFileRequest) (GetFileResponse, error)
+	// PutFile creates or replaces the object.
+	PutFile(ctx context.Context, req PutFileRequest) error
+}
+
+type GetInfoRequest struct {
+	ObjectKey string
+}
+
+type GetInfoResponse struct {
+	MimeType string
+	ETag     string
+}
+
+type GetFileRequest struct {
+	ObjectKey string
+}
+
+type GetFileResponse struct {
+	Content []byte
+}
+
+type PutFileRequest struct {
+	// Bucket is the configured bucket when empty.
+	Bucket    string
+	ObjectKey string
+	Content   []byte
+	MimeType  string
+	// Metadata is stored with the object as user metadata.
+	Metadata map[string]string
+}
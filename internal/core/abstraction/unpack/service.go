@@ -0,0 +1,34 @@
+package unpack
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrLimitExceeded is returned when an archive has too many entries, too
+// much uncompressed data or too deep a nesting to be expanded safely.
+var ErrLimitExceeded = errors.New("archive limits exceeded")
+
+// Unpacker expands an archive, nested archives included, into its files.
+type Unpacker interface {
+	Unpack(ctx context.Context, req Request) (Response, error)
+}
+
+type Request struct {
+	Content  []byte
+	MimeType string
+}
+
+type Response struct {
+	Entries []Entry
+}
+
+type Entry struct {
+	// Path inside the archive; entries of nested archives are prefixed with
+	// the nested archive's path ("scans.zip/page1.png").
+	Path     string
+	MimeType string
+	Content  []byte
+	// Err is set for entries that could not be read, e.g. encrypted ones.
+	Err error
+}
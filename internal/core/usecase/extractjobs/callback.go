@@ -0,0 +1,102 @@
+package extractjobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"doc2text/internal/core/abstraction/callback"
+	"doc2text/internal/core/abstraction/job"
+)
+
+var ErrCallbacksDisabled = errors.New("extractjobs: callbacks are not configured")
+
+type CallbackOptions struct {
+	// Sender delivers callbacks; nil disables them.
+	Sender callback.Sender
+	// Encode renders the finished job, result included, as the body.
+	Encode func(Job) ([]byte, error)
+	// MaxAttempts bounds deliveries of one callback. Backoff is the wait
+	// after the first failure; it doubles after each one up to MaxBackoff.
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// deliver sends due callbacks one at a time. The schedule lives in the
+// store, so retries continue after a restart.
+func (s *Service) deliver() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(min(s.opts.Callback.Backoff, s.opts.PollInterval))
+	defer ticker.Stop()
+	for s.ctx.Err() == nil {
+		due, err := s.store.DueCallbacks(s.ctx, time.Now().UTC(), 16)
+		if err != nil {
+			s.log.Error("extractjobs: due callbacks: %v", err)
+		}
+		for _, j := range due {
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.notify(j)
+		}
+		if len(due) == 16 {
+			continue
+		}
+		select {
+		case <-s.ctx.Done():
+		case <-s.callbackWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) notify(j job.Job) {
+	o := s.opts.Callback
+	attempt := len(j.Deliveries) + 1
+	d := job.Delivery{At: time.Now().UTC()}
+
+	v, err := view(j, true)
+	var body []byte
+	if err == nil {
+		body, err = o.Encode(v)
+	}
+	if err == nil {
+		var res callback.Response
+		res, err = o.Sender.Send(s.ctx, callback.Request{URL: j.CallbackURL, Body: body, ID: j.ID, Attempt: attempt})
+		d.Status = res.Status
+		if err != nil && s.ctx.Err() != nil {
+			// Interrupted by shutdown; not an attempt.
+			return
+		}
+	}
+	d.Duration = time.Since(d.At)
+	if err != nil {
+		d.Error = err.Error()
+		s.log.Error("extractjobs: callback for job %s, attempt %d: %v", j.ID, attempt, err)
+	}
+
+	_, err = s.store.Update(context.Background(), j.ID, func(cur *job.Job) error {
+		cur.Deliveries = append(cur.Deliveries, d)
+		switch {
+		case d.Error == "":
+			cur.Callback = job.CallbackDelivered
+		case attempt >= o.MaxAttempts:
+			cur.Callback = job.CallbackFailed
+		default:
+			cur.NextCallbackAt = d.At.Add(backoff(o, attempt))
+		}
+		return nil
+	})
+	if err != nil {
+		s.log.Error("extractjobs: save callback of job %s: %v", j.ID, err)
+	}
+}
+
+func backoff(o CallbackOptions, attempt int) time.Duration {
+	delay := o.Backoff
+	for i := 1; i < attempt && delay < o.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, o.MaxBackoff)
+}
@@ -0,0 +1,186 @@
+package extractjobs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"doc2text/internal/core/abstraction/job"
+	"doc2text/internal/core/usecase/extracttext"
+	"doc2text/internal/infrastructure/boltjobs"
+	"doc2text/internal/infrastructure/webhook"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+type handlerFunc func(context.Context, extracttext.Query) (extracttext.Result, error)
+
+func (f handlerFunc) Handle(ctx context.Context, q extracttext.Query) (extracttext.Result, error) {
+	return f(ctx, q)
+}
+
+func textHandler(text string) extracttext.Handler {
+	return handlerFunc(func(context.Context, extracttext.Query) (extracttext.Result, error) {
+		return extracttext.Result{Text: text}, nil
+	})
+}
+
+func openStore(t *testing.T, dir string) *boltjobs.Store {
+	t.Helper()
+	st, err := boltjobs.Open(filepath.Join(dir, "jobs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func startService(t *testing.T, st job.Store, h extracttext.Handler, o Options) *Service {
+	t.Helper()
+	s := New(st, h, nopLogger{}, o)
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Stop)
+	return s
+}
+
+// waitJob polls the job until done reports true or the test times out.
+func waitJob(t *testing.T, s *Service, id string, done func(Job) bool) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		j, err := s.Get(context.Background(), GetJob{ID: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done(j) {
+			return j
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job never reached the expected state: %+v", j)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func encodeJSON(j Job) ([]byte, error) { return json.Marshal(j) }
+
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int // answered in turn, the last one repeated
+	attempts []string
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body json.RawMessage
+	_ = json.NewDecoder(req.Body).Decode(&body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, req.Header.Get(webhook.HeaderAttempt))
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(r.statuses[min(len(r.attempts), len(r.statuses))-1])
+}
+
+func callbackOptions(backoff, maxBackoff time.Duration, attempts int) CallbackOptions {
+	return CallbackOptions{
+		Sender:      webhook.New(webhook.Options{Secret: "secret", Timeout: time.Second}),
+		Encode:      encodeJSON,
+		MaxAttempts: attempts,
+		Backoff:     backoff,
+		MaxBackoff:  maxBackoff,
+	}
+}
+
+func TestCallbackRetriesWithBackoff(t *testing.T) {
+	rcv := &receiver{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusNoContent}}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	const backoff, maxBackoff = 40 * time.Millisecond, 60 * time.Millisecond
+	s := startService(t, openStore(t, t.TempDir()), textHandler("hello"), Options{
+		PollInterval: time.Hour,
+		Callback:     callbackOptions(backoff, maxBackoff, 5),
+	})
+	j, err := s.Submit(context.Background(), SubmitJob{ObjectKey: "a.pdf", CallbackURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j = waitJob(t, s, j.ID, func(j Job) bool { return j.Callback == job.CallbackDelivered })
+
+	if len(j.Deliveries) != 3 {
+		t.Fatalf("deliveries = %+v, want 3", j.Deliveries)
+	}
+	for i, want := range []int{503, 502, 204} {
+		d := j.Deliveries[i]
+		if d.Status != want || (want == 204) != (d.Error == "") {
+			t.Errorf("delivery %d = %+v, want status %d", i+1, d, want)
+		}
+	}
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	if got := rcv.attempts; len(got) != 3 || got[0] != "1" || got[1] != "2" || got[2] != "3" {
+		t.Fatalf("attempt headers = %q", got)
+	}
+	// The wait doubles after each failure but stops at MaxBackoff.
+	for i, want := range []time.Duration{backoff, maxBackoff} {
+		if gap := j.Deliveries[i+1].At.Sub(j.Deliveries[i].At); gap < want {
+			t.Errorf("retry %d after %v, want at least %v", i+1, gap, want)
+		}
+	}
+	var body Job
+	if err := json.Unmarshal(rcv.bodies[2], &body); err != nil || body.ID != j.ID || body.Result == nil || body.Result.Text != "hello" {
+		t.Fatalf("callback body = %s (%v)", rcv.bodies[2], err)
+	}
+}
+
+func TestCallbackGivesUp(t *testing.T) {
+	rcv := &receiver{statuses: []int{http.StatusInternalServerError}}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	s := startService(t, openStore(t, t.TempDir()), textHandler("hello"), Options{
+		PollInterval: time.Hour,
+		Callback:     callbackOptions(10*time.Millisecond, 10*time.Millisecond, 2),
+	})
+	j, err := s.Submit(context.Background(), SubmitJob{ObjectKey: "a.pdf", CallbackURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j = waitJob(t, s, j.ID, func(j Job) bool { return j.Callback == job.CallbackFailed })
+	if len(j.Deliveries) != 2 || j.Deliveries[1].Status != http.StatusInternalServerError || j.Deliveries[1].Error == "" {
+		t.Fatalf("deliveries = %+v", j.Deliveries)
+	}
+	time.Sleep(50 * time.Millisecond)
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	if len(rcv.attempts) != 2 {
+		t.Fatalf("receiver got %d attempts after giving up, want 2", len(rcv.attempts))
+	}
+}
+
+func TestCallbackNeedsSender(t *testing.T) {
+	s := New(openStore(t, t.TempDir()), textHandler(""), nopLogger{}, Options{})
+	if _, err := s.Submit(context.Background(), SubmitJob{ObjectKey: "a.pdf", CallbackURL: "http://example.com"}); err != ErrCallbacksDisabled {
+		t.Fatalf("Submit() error = %v, want ErrCallbacksDisabled", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	o := CallbackOptions{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := backoff(o, attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
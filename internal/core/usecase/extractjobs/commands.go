@@ -0,0 +1,119 @@
+package extractjobs
+
+import (
+	"context"
+	"time"
+
+	"doc2text/internal/core/abstraction/job"
+	"doc2text/internal/core/usecase/extracttext"
+)
+
+type SubmitJob struct {
+	ObjectKey     string
+	MinConfidence *float64
+	Backend       string
+	// CallbackURL, if set, receives the finished job.
+	CallbackURL string
+	// IdempotencyKey makes a repeated submit return the job created first,
+	// for as long as that job is kept.
+	IdempotencyKey string
+	// OutputKey, if set, is the object the recognized text is written to.
+	OutputKey string
+}
+
+type CancelJob struct {
+	ID string
+}
+
+type GetJob struct {
+	ID string
+}
+
+// ListJobs pages through jobs newest first; After is the Next of the
+// previous page.
+type ListJobs struct {
+	State job.State
+	Limit int
+	After string
+}
+
+type Job struct {
+	ID        string
+	State     job.State
+	ObjectKey string
+	// Result is set for succeeded jobs returned by GetJob and CancelJob.
+	Result     *extracttext.Result
+	Error      string
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	ExpiresAt  time.Time
+	OutputKey  string
+
+	CallbackURL string
+	Callback    string
+	Deliveries  []job.Delivery
+}
+
+type JobList struct {
+	Jobs []Job
+	// Next is empty on the last page.
+	Next string
+}
+
+func (SubmitJob) IsCommand() {}
+func (CancelJob) IsCommand() {}
+func (GetJob) IsQuery()      {}
+func (ListJobs) IsQuery()    {}
+
+// The handlers expose Service on the cqrs bus, one per message type.
+
+type SubmitHandler struct{ *Service }
+
+func (h SubmitHandler) Handle(ctx context.Context, c SubmitJob) (Job, error) {
+	return h.Submit(ctx, c)
+}
+
+type CancelHandler struct{ *Service }
+
+func (h CancelHandler) Handle(ctx context.Context, c CancelJob) (Job, error) {
+	return h.Cancel(ctx, c)
+}
+
+type GetHandler struct{ *Service }
+
+func (h GetHandler) Handle(ctx context.Context, q GetJob) (Job, error) {
+	return h.Get(ctx, q)
+}
+
+type ListHandler struct{ *Service }
+
+func (h ListHandler) Handle(ctx context.Context, q ListJobs) (JobList, error) {
+	return h.List(ctx, q)
+}
+
+func view(j job.Job, withResult bool) (Job, error) {
+	v := Job{
+		ID:         j.ID,
+		State:      j.State,
+		ObjectKey:  j.ObjectKey,
+		Error:      j.Error,
+		CreatedAt:  j.CreatedAt,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+		ExpiresAt:  j.ExpiresAt,
+		OutputKey:  j.OutputKey,
+
+		CallbackURL: j.CallbackURL,
+		Callback:    j.Callback,
+		Deliveries:  j.Deliveries,
+	}
+	if withResult && len(j.Result) > 0 {
+		res, err := decodeResult(j.Result)
+		if err != nil {
+			return Job{}, err
+		}
+		v.Result = &res
+	}
+	return v, nil
+}
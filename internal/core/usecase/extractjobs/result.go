@@ -0,0 +1,75 @@
+package extractjobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"doc2text/internal/core/abstraction/recognize"
+	"doc2text/internal/core/usecase/extracttext"
+)
+
+// storedResult is extracttext.Result with entry errors kept as text.
+type storedResult struct {
+	Text          string
+	Pages         []recognize.Page
+	FilteredWords int
+	FilteredLines int
+	Backend       string
+	Entries       []storedEntry `json:",omitempty"`
+}
+
+type storedEntry struct {
+	Path     string
+	MimeType string
+	Result   storedResult
+	Error    string `json:",omitempty"`
+}
+
+func encodeResult(res extracttext.Result) ([]byte, error) {
+	return json.Marshal(toStored(res))
+}
+
+func decodeResult(b []byte) (extracttext.Result, error) {
+	var s storedResult
+	if err := json.Unmarshal(b, &s); err != nil {
+		return extracttext.Result{}, fmt.Errorf("extractjobs: decode result: %w", err)
+	}
+	return fromStored(s), nil
+}
+
+func toStored(res extracttext.Result) storedResult {
+	s := storedResult{
+		Text:          res.Text,
+		Pages:         res.Pages,
+		FilteredWords: res.FilteredWords,
+		FilteredLines: res.FilteredLines,
+		Backend:       res.Backend,
+	}
+	for _, e := range res.Entries {
+		se := storedEntry{Path: e.Path, MimeType: e.MimeType, Result: toStored(e.Result)}
+		if e.Err != nil {
+			se.Error = e.Err.Error()
+		}
+		s.Entries = append(s.Entries, se)
+	}
+	return s
+}
+
+func fromStored(s storedResult) extracttext.Result {
+	res := extracttext.Result{
+		Text:          s.Text,
+		Pages:         s.Pages,
+		FilteredWords: s.FilteredWords,
+		FilteredLines: s.FilteredLines,
+		Backend:       s.Backend,
+	}
+	for _, se := range s.Entries {
+		e := extracttext.Entry{Path: se.Path, MimeType: se.MimeType, Result: fromStored(se.Result)}
+		if se.Error != "" {
+			e.Err = errors.New(se.Error)
+		}
+		res.Entries = append(res.Entries, e)
+	}
+	return res
+}
@@ -0,0 +1,333 @@
+package extractjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"doc2text/internal/core/abstraction/job"
+	"doc2text/internal/core/abstraction/logger"
+	"doc2text/internal/core/abstraction/storage"
+	"doc2text/internal/core/usecase/extracttext"
+
+	"github.com/rs/xid"
+)
+
+type Options struct {
+	// Workers is how many jobs run at once.
+	Workers int
+	// Timeout bounds a single job run.
+	Timeout time.Duration
+	// ResultTTL is how long finished jobs are kept.
+	ResultTTL time.Duration
+	// PollInterval is how often idle workers look for jobs queued by
+	// something other than Submit, and how often expired jobs are removed.
+	PollInterval time.Duration
+	Callback     CallbackOptions
+	// Output writes the text of jobs submitted with an OutputKey; without
+	// it such jobs are refused.
+	Output storage.Storage
+}
+
+var ErrOutputDisabled = errors.New("extractjobs: writing results to storage is not configured")
+
+// Service runs extraction jobs in the background with extracttext as the
+// executor. Jobs are persisted, so queued ones survive a restart and ones
+// interrupted by shutdown run again.
+type Service struct {
+	store   job.Store
+	handler extracttext.Handler
+	log     logger.Logger
+	opts    Options
+
+	wake         chan struct{}
+	callbackWake chan struct{}
+	mu           sync.Mutex
+	running      map[string]context.CancelFunc
+	ctx          context.Context
+	stop         context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+func New(store job.Store, h extracttext.Handler, l logger.Logger, o Options) *Service {
+	if o.Workers <= 0 {
+		o.Workers = 1
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Minute
+	}
+	if o.Callback.MaxAttempts <= 0 {
+		o.Callback.MaxAttempts = 1
+	}
+	if o.Callback.Backoff <= 0 {
+		o.Callback.Backoff = time.Second
+	}
+	o.Callback.MaxBackoff = max(o.Callback.MaxBackoff, o.Callback.Backoff)
+	return &Service{
+		store:        store,
+		handler:      h,
+		log:          l,
+		opts:         o,
+		wake:         make(chan struct{}, o.Workers),
+		callbackWake: make(chan struct{}, 1),
+		running:      map[string]context.CancelFunc{},
+	}
+}
+
+// Start requeues jobs a previous process left running and starts the
+// workers. Stop waits for them to return.
+func (s *Service) Start(ctx context.Context) error {
+	n, err := s.store.Requeue(ctx)
+	if err != nil {
+		return fmt.Errorf("extractjobs: requeue: %w", err)
+	}
+	if n > 0 {
+		s.log.Info("extractjobs: requeued %d interrupted jobs", n)
+	}
+
+	s.ctx, s.stop = context.WithCancel(context.Background())
+	for range s.opts.Workers {
+		s.wg.Add(1)
+		go s.work()
+	}
+	s.wg.Add(1)
+	go s.cleanup()
+	if s.opts.Callback.Sender != nil {
+		s.wg.Add(1)
+		go s.deliver()
+	}
+	return nil
+}
+
+// Stop interrupts running jobs, which go back to the queue, and waits for
+// the workers to exit.
+func (s *Service) Stop() {
+	if s.stop == nil {
+		return
+	}
+	s.stop()
+	s.wg.Wait()
+}
+
+func (s *Service) Submit(ctx context.Context, c SubmitJob) (Job, error) {
+	if c.CallbackURL != "" && s.opts.Callback.Sender == nil {
+		return Job{}, ErrCallbacksDisabled
+	}
+	if c.OutputKey != "" && s.opts.Output == nil {
+		return Job{}, ErrOutputDisabled
+	}
+	j, err := s.store.Create(ctx, job.Job{
+		ID:             xid.New().String(),
+		State:          job.StateQueued,
+		ObjectKey:      c.ObjectKey,
+		MinConfidence:  c.MinConfidence,
+		Backend:        c.Backend,
+		CallbackURL:    c.CallbackURL,
+		IdempotencyKey: c.IdempotencyKey,
+		OutputKey:      c.OutputKey,
+		CreatedAt:      time.Now().UTC(),
+	})
+	if err != nil {
+		return Job{}, fmt.Errorf("extractjobs: create: %w", err)
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return view(j, false)
+}
+
+func (s *Service) Get(ctx context.Context, q GetJob) (Job, error) {
+	j, err := s.store.Get(ctx, q.ID)
+	if err != nil {
+		return Job{}, err
+	}
+	return view(j, true)
+}
+
+// Cancel drops a queued job and asks a running one to stop. Finished jobs
+// are returned as they are.
+func (s *Service) Cancel(ctx context.Context, c CancelJob) (Job, error) {
+	j, err := s.store.Update(ctx, c.ID, func(j *job.Job) error {
+		switch j.State {
+		case job.StateQueued:
+			s.finish(j, job.StateCancelled, time.Now().UTC())
+		case job.StateRunning:
+			j.CancelRequested = true
+		}
+		return nil
+	})
+	if err != nil {
+		return Job{}, err
+	}
+	if j.State == job.StateRunning {
+		s.mu.Lock()
+		if cancel, ok := s.running[j.ID]; ok {
+			cancel()
+		}
+		s.mu.Unlock()
+	}
+	return view(j, true)
+}
+
+func (s *Service) List(ctx context.Context, q ListJobs) (JobList, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	jobs, err := s.store.List(ctx, job.ListRequest{State: q.State, Limit: limit + 1, After: q.After})
+	if err != nil {
+		return JobList{}, fmt.Errorf("extractjobs: list: %w", err)
+	}
+	var out JobList
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+		out.Next = jobs[limit-1].ID
+	}
+	for _, j := range jobs {
+		v, err := view(j, false)
+		if err != nil {
+			return JobList{}, err
+		}
+		out.Jobs = append(out.Jobs, v)
+	}
+	return out, nil
+}
+
+const defaultListLimit = 50
+
+func (s *Service) work() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+	// Checked before claiming: a job interrupted by shutdown is queued again
+	// and must not be picked up by the same process.
+	for s.ctx.Err() == nil {
+		j, err := s.store.Claim(s.ctx, time.Now().UTC())
+		if err == nil {
+			s.run(j)
+			continue
+		}
+		if !errors.Is(err, job.ErrNotFound) {
+			s.log.Error("extractjobs: claim: %v", err)
+		}
+		select {
+		case <-s.ctx.Done():
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) run(j job.Job) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if s.opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(s.ctx, s.opts.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(s.ctx)
+	}
+	defer cancel()
+	s.mu.Lock()
+	s.running[j.ID] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, j.ID)
+		s.mu.Unlock()
+	}()
+	// Cancel may have landed between Claim and the registration above.
+	if cur, err := s.store.Get(ctx, j.ID); err == nil && cur.CancelRequested {
+		cancel()
+	}
+
+	res, err := s.handler.Handle(ctx, extracttext.Query{
+		ObjectKey:     j.ObjectKey,
+		MinConfidence: j.MinConfidence,
+		Backend:       j.Backend,
+	})
+	var encoded []byte
+	if err == nil {
+		if encoded, err = encodeResult(res); err != nil {
+			err = fmt.Errorf("extractjobs: encode result: %w", err)
+		}
+	}
+	if err == nil && j.OutputKey != "" {
+		err = s.opts.Output.PutFile(ctx, storage.PutFileRequest{
+			ObjectKey: j.OutputKey,
+			Content:   []byte(res.Text),
+			MimeType:  "text/plain; charset=utf-8",
+		})
+		if err != nil {
+			err = fmt.Errorf("extractjobs: write %s: %w", j.OutputKey, err)
+		}
+	}
+
+	// The service context may be gone already; the outcome is still saved.
+	_, uerr := s.store.Update(context.Background(), j.ID, func(cur *job.Job) error {
+		now := time.Now().UTC()
+		switch {
+		case err == nil:
+			cur.Result = encoded
+			s.finish(cur, job.StateSucceeded, now)
+		case cur.CancelRequested:
+			s.finish(cur, job.StateCancelled, now)
+		case s.ctx.Err() != nil:
+			// Shutdown: run it again after restart.
+			cur.State = job.StateQueued
+			cur.StartedAt = time.Time{}
+		default:
+			cur.Error = err.Error()
+			s.finish(cur, job.StateFailed, now)
+		}
+		return nil
+	})
+	if uerr != nil {
+		s.log.Error("extractjobs: save job %s: %v", j.ID, uerr)
+		return
+	}
+	if j.CallbackURL != "" {
+		select {
+		case s.callbackWake <- struct{}{}:
+		default:
+		}
+	}
+	if err != nil && s.ctx.Err() == nil {
+		s.log.Error("extractjobs: job %s (%s): %v", j.ID, j.ObjectKey, err)
+	}
+}
+
+func (s *Service) finish(j *job.Job, state job.State, now time.Time) {
+	j.State = state
+	j.FinishedAt = now
+	if s.opts.ResultTTL > 0 {
+		j.ExpiresAt = now.Add(s.opts.ResultTTL)
+	}
+	if j.CallbackURL != "" {
+		j.Callback = job.CallbackPending
+		j.NextCallbackAt = now
+	}
+}
+
+func (s *Service) cleanup() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		n, err := s.store.DeleteExpired(s.ctx, time.Now().UTC())
+		if err != nil {
+			s.log.Error("extractjobs: delete expired: %v", err)
+		} else if n > 0 {
+			s.log.Info("extractjobs: deleted %d expired jobs", n)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package extractjobs
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"doc2text/internal/core/abstraction/job"
+	"doc2text/internal/core/usecase/extracttext"
+)
+
+// countingHandler returns the object key as text and counts its runs.
+type countingHandler struct{ calls atomic.Int32 }
+
+func (h *countingHandler) Handle(_ context.Context, q extracttext.Query) (extracttext.Result, error) {
+	h.calls.Add(1)
+	return extracttext.Result{Text: "text of " + q.ObjectKey}, nil
+}
+
+// blockingHandler runs until its query is cancelled and reports each start.
+type blockingHandler chan string
+
+func (h blockingHandler) Handle(ctx context.Context, q extracttext.Query) (extracttext.Result, error) {
+	h <- q.ObjectKey
+	<-ctx.Done()
+	return extracttext.Result{}, ctx.Err()
+}
+
+func waitStarted(t *testing.T, h blockingHandler) string {
+	t.Helper()
+	select {
+	case key := <-h:
+		return key
+	case <-time.After(5 * time.Second):
+		t.Fatal("job never started")
+		return ""
+	}
+}
+
+func TestSubmitIdempotent(t *testing.T) {
+	ctx := context.Background()
+	h := &countingHandler{}
+	s := startService(t, openStore(t, t.TempDir()), h, Options{PollInterval: time.Hour})
+
+	first, err := s.Submit(ctx, SubmitJob{ObjectKey: "a.pdf", IdempotencyKey: "s3:b/a.pdf@e1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitJob(t, s, first.ID, func(j Job) bool { return j.State == job.StateSucceeded })
+	replay, err := s.Submit(ctx, SubmitJob{ObjectKey: "a.pdf", IdempotencyKey: "s3:b/a.pdf@e1"})
+	if err != nil || replay.ID != first.ID || replay.State != job.StateSucceeded {
+		t.Fatalf("replayed Submit() = %+v, %v, want job %s", replay, err, first.ID)
+	}
+	other, _ := s.Submit(ctx, SubmitJob{ObjectKey: "a.pdf", IdempotencyKey: "s3:b/a.pdf@e2"})
+	waitJob(t, s, other.ID, func(j Job) bool { return j.State == job.StateSucceeded })
+	if other.ID == first.ID || h.calls.Load() != 2 {
+		t.Fatalf("new version got job %s after %d runs", other.ID, h.calls.Load())
+	}
+	if j, _ := s.Get(ctx, GetJob{ID: first.ID}); j.Result == nil || j.Result.Text != "text of a.pdf" {
+		t.Fatalf("Get() = %+v", j)
+	}
+}
+
+func TestListPages(t *testing.T) {
+	ctx := context.Background()
+	// Not started: the jobs stay queued.
+	s := New(openStore(t, t.TempDir()), &countingHandler{}, nopLogger{}, Options{})
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		if _, err := s.Submit(ctx, SubmitJob{ObjectKey: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	pages := 0
+	for q := (ListJobs{Limit: 2}); ; {
+		list, err := s.List(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		for _, j := range list.Jobs {
+			if j.Result != nil {
+				t.Fatalf("listed job %s carries a result", j.ID)
+			}
+			got = append(got, j.ObjectKey)
+		}
+		if list.Next == "" {
+			break
+		}
+		q.After = list.Next
+	}
+	if fmt.Sprint(got) != "[e d c b a]" || pages != 3 {
+		t.Fatalf("listed %v over %d pages, want newest first over 3", got, pages)
+	}
+	if list, _ := s.List(ctx, ListJobs{Limit: 5}); len(list.Jobs) != 5 || list.Next != "" {
+		t.Fatalf("an exact page has Next %q", list.Next)
+	}
+}
+
+func TestRequeueAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	blocked := make(blockingHandler, 1)
+	st := openStore(t, dir)
+	s := startService(t, st, blocked, Options{PollInterval: time.Hour})
+	j, err := s.Submit(ctx, SubmitJob{ObjectKey: "a.pdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitStarted(t, blocked)
+	s.Stop()
+	if stored, _ := st.Get(ctx, j.ID); stored.State != job.StateQueued || !stored.StartedAt.IsZero() {
+		t.Fatalf("job interrupted by shutdown is %s", stored.State)
+	}
+
+	// A process that died leaves the job running; the next one runs it again.
+	if _, err := st.Update(ctx, j.ID, func(cur *job.Job) error {
+		cur.State = job.StateRunning
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+	h := &countingHandler{}
+	s = startService(t, openStore(t, dir), h, Options{PollInterval: time.Hour})
+	done := waitJob(t, s, j.ID, func(j Job) bool { return j.State.Final() })
+	if done.State != job.StateSucceeded || h.calls.Load() != 1 {
+		t.Fatalf("after restart the job is %s after %d runs", done.State, h.calls.Load())
+	}
+}
+
+func TestCancelQueued(t *testing.T) {
+	ctx := context.Background()
+	h := &countingHandler{}
+	st := openStore(t, t.TempDir())
+	s := New(st, h, nopLogger{}, Options{PollInterval: time.Hour, ResultTTL: time.Hour})
+	j, err := s.Submit(ctx, SubmitJob{ObjectKey: "a.pdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.Cancel(ctx, CancelJob{ID: j.ID})
+	if err != nil || c.State != job.StateCancelled || c.FinishedAt.IsZero() || c.ExpiresAt.IsZero() {
+		t.Fatalf("Cancel() = %+v, %v", c, err)
+	}
+
+	// A cancelled job is never claimed.
+	if err := s.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Stop)
+	time.Sleep(20 * time.Millisecond)
+	if h.calls.Load() != 0 {
+		t.Fatal("cancelled job ran")
+	}
+	if again, _ := s.Cancel(ctx, CancelJob{ID: j.ID}); again.State != job.StateCancelled || !again.FinishedAt.Equal(c.FinishedAt) {
+		t.Fatalf("second Cancel() = %+v", again)
+	}
+}
+
+func TestCancelRunning(t *testing.T) {
+	ctx := context.Background()
+	blocked := make(blockingHandler, 1)
+	s := startService(t, openStore(t, t.TempDir()), blocked, Options{PollInterval: time.Hour})
+	j, err := s.Submit(ctx, SubmitJob{ObjectKey: "a.pdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitStarted(t, blocked)
+
+	c, err := s.Cancel(ctx, CancelJob{ID: j.ID})
+	if err != nil || c.State != job.StateRunning {
+		t.Fatalf("Cancel() = %+v, %v, want it still running", c, err)
+	}
+	done := waitJob(t, s, j.ID, func(j Job) bool { return j.State.Final() })
+	if done.State != job.StateCancelled || done.Error != "" {
+		t.Fatalf("cancelled job = %+v", done)
+	}
+}
@@ -0,0 +1,58 @@
+package extracttext
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchQuery runs several queries in one call.
+type BatchQuery struct {
+	Items []Query
+}
+
+// BatchResult holds one item per query, in query order.
+type BatchResult struct {
+	Items []BatchItem
+}
+
+type BatchItem struct {
+	Result Result
+	Err    error
+}
+
+func (BatchQuery) IsQuery() {}
+
+type BatchHandler struct {
+	handler     Handler
+	concurrency int
+}
+
+// NewBatchHandler runs the queries of a batch through h, at most
+// concurrency at a time. A failing item does not stop the others.
+func NewBatchHandler(h Handler, concurrency int) *BatchHandler {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &BatchHandler{handler: h, concurrency: concurrency}
+}
+
+func (b *BatchHandler) Handle(ctx context.Context, q BatchQuery) (BatchResult, error) {
+	items := make([]BatchItem, len(q.Items))
+	sem := make(chan struct{}, b.concurrency)
+	var wg sync.WaitGroup
+	for i, item := range q.Items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			items[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			items[i].Result, items[i].Err = b.handler.Handle(ctx, item)
+		}()
+	}
+	wg.Wait()
+	return BatchResult{Items: items}, ctx.Err()
+}
@@ -0,0 +1,102 @@
+package extracttext
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type CoalescingHandler struct {
+	handler Handler
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call is one run of the wrapped handler shared by every query waiting on
+// it. It is cancelled once the last of them gives up.
+type call struct {
+	done    chan struct{}
+	res     Result
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewCoalescingHandler runs identical queries that overlap in time through
+// h once: the same object, or uploads with the same content, with the same
+// options. Streaming queries are passed through, since each needs its own
+// pages. Waiters share the Result and must not modify it.
+func NewCoalescingHandler(h Handler) *CoalescingHandler {
+	return &CoalescingHandler{handler: h, calls: map[string]*call{}}
+}
+
+// Handle runs the shared call detached from the context of the query that
+// started it, so a caller that leaves early does not fail the others; the
+// call keeps that query's values and is cancelled only when every waiter is
+// gone.
+func (c *CoalescingHandler) Handle(ctx context.Context, q Query) (Result, error) {
+	if q.OnPage != nil {
+		return c.handler.Handle(ctx, q)
+	}
+	key := coalesceKey(q)
+
+	c.mu.Lock()
+	cl, ok := c.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		cl = &call{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = cl
+		go c.run(callCtx, key, cl, q)
+	}
+	cl.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.res, cl.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		if cl.waiters--; cl.waiters == 0 {
+			cl.cancel()
+			c.forget(key, cl)
+		}
+		c.mu.Unlock()
+		return Result{}, ctx.Err()
+	}
+}
+
+func (c *CoalescingHandler) run(ctx context.Context, key string, cl *call, q Query) {
+	defer cl.cancel()
+	cl.res, cl.err = c.handler.Handle(ctx, q)
+	c.mu.Lock()
+	c.forget(key, cl)
+	c.mu.Unlock()
+	close(cl.done)
+}
+
+// forget lets later queries start a new call; cl may already be replaced
+// when it was abandoned.
+func (c *CoalescingHandler) forget(key string, cl *call) {
+	if c.calls[key] == cl {
+		delete(c.calls, key)
+	}
+}
+
+func coalesceKey(q Query) string {
+	var b strings.Builder
+	if q.Upload != nil {
+		sum := sha256.Sum256(q.Upload.Content)
+		b.WriteString("upload:" + hex.EncodeToString(sum[:]) + ":" + q.Upload.MimeType)
+	} else {
+		b.WriteString("object:" + q.ObjectKey)
+	}
+	b.WriteString("\x00" + q.Backend + "\x00")
+	if q.MinConfidence != nil {
+		b.WriteString(strconv.FormatFloat(*q.MinConfidence, 'g', -1, 64))
+	}
+	return b.String()
+}
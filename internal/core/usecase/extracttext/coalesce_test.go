@@ -0,0 +1,232 @@
+package extracttext
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingHandler holds every call until release is closed, or until its
+// context is cancelled, which it records.
+type blockingHandler struct {
+	release chan struct{}
+	started chan context.Context
+	res     Result
+	err     error
+
+	mu        sync.Mutex
+	calls     int
+	cancelled int
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{release: make(chan struct{}), started: make(chan context.Context, 8), res: Result{Text: "shared"}}
+}
+
+func (h *blockingHandler) Handle(ctx context.Context, _ Query) (Result, error) {
+	h.mu.Lock()
+	h.calls++
+	h.mu.Unlock()
+	h.started <- ctx
+	select {
+	case <-h.release:
+		return h.res, h.err
+	case <-ctx.Done():
+		h.mu.Lock()
+		h.cancelled++
+		h.mu.Unlock()
+		return Result{}, ctx.Err()
+	}
+}
+
+func (h *blockingHandler) counts() (calls, cancelled int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls, h.cancelled
+}
+
+type outcome struct {
+	res Result
+	err error
+}
+
+func goHandle(ctx context.Context, c *CoalescingHandler, q Query) chan outcome {
+	out := make(chan outcome, 1)
+	go func() {
+		res, err := c.Handle(ctx, q)
+		out <- outcome{res, err}
+	}()
+	return out
+}
+
+// waitWaiters blocks until n queries wait on the call for q.
+func waitWaiters(t *testing.T, c *CoalescingHandler, q Query, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.mu.Lock()
+		cl := c.calls[coalesceKey(q)]
+		got := 0
+		if cl != nil {
+			got = cl.waiters
+		}
+		c.mu.Unlock()
+		if got == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d waiters, want %d", got, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func receive(t *testing.T, out chan outcome) outcome {
+	t.Helper()
+	select {
+	case o := <-out:
+		return o
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handle did not return")
+		return outcome{}
+	}
+}
+
+func TestCoalesceLeaderLeaves(t *testing.T) {
+	h := newBlockingHandler()
+	c := NewCoalescingHandler(h)
+	q := Query{ObjectKey: "a.pdf"}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leader := goHandle(leaderCtx, c, q)
+	callCtx := <-h.started
+	follower := goHandle(context.Background(), c, q)
+	waitWaiters(t, c, q, 2)
+
+	cancelLeader()
+	if o := receive(t, leader); !errors.Is(o.err, context.Canceled) {
+		t.Fatalf("leader got %+v, want context.Canceled", o)
+	}
+	if callCtx.Err() != nil {
+		t.Fatal("the call was cancelled while the follower still waits")
+	}
+	close(h.release)
+	if o := receive(t, follower); o.err != nil || o.res.Text != "shared" {
+		t.Fatalf("follower got %+v", o)
+	}
+	if calls, cancelled := h.counts(); calls != 1 || cancelled != 0 {
+		t.Fatalf("handler ran %d times, %d cancelled; want 1, 0", calls, cancelled)
+	}
+}
+
+func TestCoalesceAllWaitersLeave(t *testing.T) {
+	h := newBlockingHandler()
+	c := NewCoalescingHandler(h)
+	q := Query{ObjectKey: "a.pdf"}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	out1 := goHandle(ctx1, c, q)
+	callCtx := <-h.started
+	out2 := goHandle(ctx2, c, q)
+	waitWaiters(t, c, q, 2)
+
+	cancel1()
+	receive(t, out1)
+	cancel2()
+	receive(t, out2)
+	select {
+	case <-callCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the call was not cancelled after every waiter left")
+	}
+
+	// The abandoned call is forgotten at once, so a new query starts over
+	// rather than joining it.
+	out3 := goHandle(context.Background(), c, q)
+	<-h.started
+	close(h.release)
+	if o := receive(t, out3); o.err != nil || o.res.Text != "shared" {
+		t.Fatalf("new query got %+v", o)
+	}
+	if calls, _ := h.counts(); calls != 2 {
+		t.Fatalf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestCoalesceSharesError(t *testing.T) {
+	h := newBlockingHandler()
+	h.err = errors.New("boom")
+	c := NewCoalescingHandler(h)
+	q := Query{ObjectKey: "a.pdf"}
+
+	outs := []chan outcome{goHandle(context.Background(), c, q)}
+	<-h.started
+	for range 2 {
+		outs = append(outs, goHandle(context.Background(), c, q))
+	}
+	waitWaiters(t, c, q, 3)
+	close(h.release)
+	for i, out := range outs {
+		if o := receive(t, out); o.err != h.err {
+			t.Errorf("waiter %d got %v, want the shared error", i, o.err)
+		}
+	}
+	if calls, _ := h.counts(); calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestCoalesceRunsAgainAfterDone(t *testing.T) {
+	h := newBlockingHandler()
+	close(h.release)
+	c := NewCoalescingHandler(h)
+	q := Query{ObjectKey: "a.pdf"}
+	for range 2 {
+		if _, err := c.Handle(context.Background(), q); err != nil {
+			t.Fatal(err)
+		}
+		<-h.started
+	}
+	if calls, _ := h.counts(); calls != 2 {
+		t.Fatalf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestCoalesceKey(t *testing.T) {
+	low, high := 0.5, 0.7
+	base := Query{ObjectKey: "a.pdf"}
+	for name, q := range map[string]Query{
+		"object":     {ObjectKey: "b.pdf"},
+		"backend":    {ObjectKey: "a.pdf", Backend: "tesseract"},
+		"confidence": {ObjectKey: "a.pdf", MinConfidence: &low},
+		"upload":     {ObjectKey: "a.pdf", Upload: &Upload{Content: []byte("x"), MimeType: "image/png"}},
+	} {
+		if coalesceKey(q) == coalesceKey(base) {
+			t.Errorf("%s: key does not change", name)
+		}
+	}
+	if coalesceKey(Query{MinConfidence: &low}) == coalesceKey(Query{MinConfidence: &high}) {
+		t.Error("different confidences share a key")
+	}
+	// The upload name is not part of the content.
+	a := Query{Upload: &Upload{Name: "a.png", Content: []byte("x"), MimeType: "image/png"}}
+	b := Query{Upload: &Upload{Name: "b.png", Content: []byte("x"), MimeType: "image/png"}}
+	if coalesceKey(a) != coalesceKey(b) {
+		t.Error("identical uploads get different keys")
+	}
+}
+
+func TestCoalesceStreamingPassesThrough(t *testing.T) {
+	h := newBlockingHandler()
+	close(h.release)
+	c := NewCoalescingHandler(h)
+	if _, err := c.Handle(context.Background(), Query{ObjectKey: "a.pdf", OnPage: func(PageEvent) {}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.calls) != 0 {
+		t.Fatal("streaming query was coalesced")
+	}
+}
@@ -3,43 +3,336 @@ package extracttext
 import (
 	"context"
 	"doc2text/internal/core/abstraction/convert"
-	"doc2text/internal/core/abstraction/download"
+	"doc2text/internal/core/abstraction/extract"
 	"doc2text/internal/core/abstraction/logger"
+	"doc2text/internal/core/abstraction/normalize"
 	"doc2text/internal/core/abstraction/recognize"
+	"doc2text/internal/core/abstraction/split"
+	"doc2text/internal/core/abstraction/storage"
+	"doc2text/internal/core/abstraction/unpack"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 )
 
 type QueryHandler struct {
 	fileConverter convert.FileConverter
-	downloader    download.Downloader
+	storage       storage.Storage
 	recognizer    recognize.Recognizer
+	extractors    map[string]extract.Extractor
+	unpackers     map[string]unpack.Unpacker
+	normalizers   map[string]normalize.Normalizer
+	splitters     map[string]split.Splitter
+	// pageConcurrency bounds how many pages of a document are recognized
+	// at once.
+	pageConcurrency int
+	log             logger.Logger
 }
 
+// NewHandler wires the use case. Extractors are keyed by MIME type and are
+// tried before OCR; pages they cannot read are sent to the recognizer.
+// Unpackers, also keyed by MIME type, expand archives whose files then go
+// through the same pipeline one by one. Normalizers re-encode image formats
+// the recognizers do not take before OCR. Splitters cut documents into
+// single pages: only the pages without text are recognized, and scanned
+// documents are recognized page by page, pageConcurrency at a time.
 func NewHandler(
 	fc convert.FileConverter,
-	d download.Downloader,
+	s storage.Storage,
 	l logger.Logger,
-	r recognize.Recognizer) *QueryHandler {
+	r recognize.Recognizer,
+	extractors map[string]extract.Extractor,
+	unpackers map[string]unpack.Unpacker,
+	normalizers map[string]normalize.Normalizer,
+	splitters map[string]split.Splitter,
+	pageConcurrency int) *QueryHandler {
 	return &QueryHandler{
-		fileConverter: fc,
-		downloader:    d,
-		recognizer:    r,
+		fileConverter:   fc,
+		storage:         s,
+		recognizer:      r,
+		extractors:      extractors,
+		unpackers:       unpackers,
+		normalizers:     normalizers,
+		splitters:       splitters,
+		pageConcurrency: pageConcurrency,
+		log:             l,
 	}
 }
 
 func (h *QueryHandler) Handle(ctx context.Context, q Query) (Result, error) {
-	fi, err := h.downloader.GetInfo(ctx, download.GetInfoRequest{ObjectKey: q.ObjectKey})
-	f, err := h.downloader.GetFile(ctx, download.GetFileRequest{ObjectKey: q.ObjectKey})
+	name, content, mimeType, err := h.fetch(ctx, q)
 	if err != nil {
-		return Result{}, fmt.Errorf("extracttext: download by URL %q: %w", q.ObjectKey, err)
+		return Result{}, err
 	}
-	b64, err := h.fileConverter.ToBase64(ctx, convert.ToBase64Request{Data: f.Content})
+
+	if u, ok := h.unpackers[baseMimeType(mimeType)]; ok {
+		return h.unpack(ctx, q, name, u, content, mimeType)
+	}
+	return h.process(ctx, q, name, content, mimeType)
+}
+
+// fetch returns the uploaded file, or downloads the object from storage.
+func (h *QueryHandler) fetch(ctx context.Context, q Query) (string, []byte, string, error) {
+	if q.Upload != nil {
+		name := q.Upload.Name
+		if name == "" {
+			name = "upload"
+		}
+		return name, q.Upload.Content, q.Upload.MimeType, nil
+	}
+
+	fi, err := h.storage.GetInfo(ctx, storage.GetInfoRequest{ObjectKey: q.ObjectKey})
+	if err != nil {
+		return "", nil, "", fmt.Errorf("extracttext: stat %q: %w", q.ObjectKey, err)
+	}
+	f, err := h.storage.GetFile(ctx, storage.GetFileRequest{ObjectKey: q.ObjectKey})
+	if err != nil {
+		return "", nil, "", fmt.Errorf("extracttext: download by URL %q: %w", q.ObjectKey, err)
+	}
+	return q.ObjectKey, f.Content, fi.MimeType, nil
+}
+
+func (h *QueryHandler) process(ctx context.Context, q Query, name string, content []byte, mimeType string) (Result, error) {
+	if ex, ok := h.extractors[baseMimeType(mimeType)]; ok {
+		ext, err := ex.Extract(ctx, extract.Request{Content: content, MimeType: mimeType})
+		if err == nil {
+			return h.extract(ctx, q, name, ext, content, mimeType)
+		}
+		if ctx.Err() != nil {
+			return Result{}, err
+		}
+		h.log.Error("extracttext: extract %q (mime=%s): %v; falling back to OCR", name, mimeType, err)
+	}
+
+	return h.recognize(ctx, q, name, content, mimeType)
+}
+
+// unpack processes every file of an archive. A file that fails is reported
+// in its entry and does not fail the others.
+func (h *QueryHandler) unpack(ctx context.Context, q Query, archive string, u unpack.Unpacker, content []byte, mimeType string) (Result, error) {
+	arc, err := u.Unpack(ctx, unpack.Request{Content: content, MimeType: mimeType})
+	if err != nil {
+		return Result{}, fmt.Errorf("extracttext: unpack %q: %w", archive, err)
+	}
+
+	var (
+		res   Result
+		texts []string
+		// Entries read without OCR have no backend and do not count.
+		ocred, hits int
+	)
+	for _, e := range arc.Entries {
+		entry := Entry{Path: e.Path, MimeType: e.MimeType, Err: e.Err}
+		if entry.Err == nil {
+			name := archive + "/" + e.Path
+			if !h.processable(e.MimeType) {
+				entry.Err = fmt.Errorf("extracttext: %q: %w", name, recognize.ErrUnsupportedType)
+			} else {
+				entry.Result, entry.Err = h.process(ctx, inEntry(q, e.Path), name, e.Content, e.MimeType)
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+		if fatal(ctx, entry.Err) {
+			return Result{}, entry.Err
+		}
+		if entry.Err != nil {
+			h.log.Error("extracttext: %s/%s: %v", archive, e.Path, entry.Err)
+			if q.OnPage != nil {
+				q.OnPage(PageEvent{Entry: e.Path, Err: entry.Err})
+			}
+		} else if entry.Result.Text != "" {
+			texts = append(texts, entry.Result.Text)
+		}
+		if entry.Err == nil && entry.Result.Backend != "" {
+			ocred++
+			if entry.Result.Cached {
+				hits++
+			}
+		}
+		res.FilteredWords += entry.Result.FilteredWords
+		res.FilteredLines += entry.Result.FilteredLines
+		res.Entries = append(res.Entries, entry)
+	}
+	res.Text = strings.Join(texts, "\n\n")
+	res.Cached = ocred > 0 && hits == ocred
+	return res, nil
+}
+
+// inEntry makes the pages reported for an archive member carry its path.
+func inEntry(q Query, path string) Query {
+	if onPage := q.OnPage; onPage != nil {
+		q.OnPage = func(ev PageEvent) {
+			ev.Entry = path
+			onPage(ev)
+		}
+	}
+	return q
+}
+
+// processable keeps archive members that neither an extractor nor OCR can
+// read (executables, fonts, ...) away from the recognizer.
+func (h *QueryHandler) processable(mimeType string) bool {
+	base := baseMimeType(mimeType)
+	if _, ok := h.extractors[base]; ok {
+		return true
+	}
+	return strings.HasPrefix(base, "image/") || base == "application/pdf"
+}
+
+// extract completes a natively read document, OCRing only the pages that
+// have no usable text.
+func (h *QueryHandler) extract(ctx context.Context, q Query, name string, ext extract.Response, content []byte, mimeType string) (Result, error) {
+	pages := ext.Pages
+	if len(ext.NeedsOCR) == 0 {
+		emitPages(q, pages, 0, len(pages), "")
+		return Result{Text: recognize.JoinText(pages), Pages: pages}, nil
+	}
+
+	// Pages with a text layer are ready before OCR starts.
+	if q.OnPage != nil {
+		for i, p := range pages {
+			if !slices.Contains(ext.NeedsOCR, i) {
+				emitPages(q, []recognize.Page{p}, i, len(pages), "")
+			}
+		}
+	}
+
+	ocr, err := h.recognizePages(ctx, q, name, content, mimeType, ext.NeedsOCR, len(pages))
 	if err != nil {
-		return Result{}, fmt.Errorf("extracttext: encode base64 for URL %q: %w", q.ObjectKey, err)
+		return Result{}, err
+	}
+	for i, p := range ocr.pages {
+		pages[i] = p
 	}
-	rcn, err := h.recognizer.Recognize(ctx, recognize.Request{ContentBase64: b64.Base64, MimeType: fi.MimeType})
+	return Result{
+		Text:          recognize.JoinText(pages),
+		Pages:         pages,
+		FilteredWords: ocr.filteredWords,
+		FilteredLines: ocr.filteredLines,
+		Backend:       ocr.backend,
+		Cached:        ocr.cached,
+	}, nil
+}
+
+// recognize OCRs the content, first converting it to a format the
+// recognizers take when a normalizer is registered for its type. Pages of a
+// multi-page image, or of a document a splitter can cut, are recognized one
+// by one and concatenated.
+func (h *QueryHandler) recognize(ctx context.Context, q Query, name string, content []byte, mimeType string) (Result, error) {
+	n, ok := h.normalizers[baseMimeType(mimeType)]
+	if !ok {
+		if res, ok, err := h.recognizeSplit(ctx, q, name, content, mimeType); ok || err != nil {
+			return res, err
+		}
+		return h.recognizeWhole(ctx, q, name, content, mimeType)
+	}
+	norm, err := n.Normalize(ctx, normalize.Request{Content: content, MimeType: mimeType})
 	if err != nil {
-		return Result{}, fmt.Errorf("extracttext: recognize text (url=%q, mime=%s): %w", q.ObjectKey, fi.MimeType, err)
+		return Result{}, fmt.Errorf("extracttext: normalize %q (mime=%s): %w", name, mimeType, err)
+	}
+	if len(norm.Images) == 1 {
+		return h.recognizeWhole(ctx, q, name, norm.Images[0].Content, norm.Images[0].MimeType)
 	}
-	return Result{Text: rcn.ExtractedText}, nil
+
+	var (
+		res      Result
+		texts    []string
+		backends []string
+		hits     int
+	)
+	for i, img := range norm.Images {
+		r, err := h.recognizeImage(ctx, q, fmt.Sprintf("%s#%d", name, i+1), img.Content, img.MimeType)
+		if err != nil {
+			if q.OnPage == nil || fatal(ctx, err) {
+				return Result{}, err
+			}
+			h.log.Error("%v", err)
+			q.OnPage(PageEvent{Index: i, Total: len(norm.Images), Err: err})
+			continue
+		}
+		emitPages(q, r.Pages, i, len(norm.Images), r.Backend)
+		res.Pages = append(res.Pages, r.Pages...)
+		res.FilteredWords += r.FilteredWords
+		res.FilteredLines += r.FilteredLines
+		texts = append(texts, r.Text)
+		if r.Cached {
+			hits++
+		}
+		if r.Backend != "" && !slices.Contains(backends, r.Backend) {
+			backends = append(backends, r.Backend)
+		}
+	}
+	res.Text = strings.Join(texts, "\n\n")
+	res.Backend = strings.Join(backends, ",")
+	res.Cached = hits == len(norm.Images)
+	return res, nil
+}
+
+// recognizeWhole OCRs a document in one call and reports its pages once
+// they are all back.
+func (h *QueryHandler) recognizeWhole(ctx context.Context, q Query, name string, content []byte, mimeType string) (Result, error) {
+	res, err := h.recognizeImage(ctx, q, name, content, mimeType)
+	if err != nil {
+		return Result{}, err
+	}
+	emitPages(q, res.Pages, 0, len(res.Pages), res.Backend)
+	return res, nil
+}
+
+func (h *QueryHandler) recognizeImage(ctx context.Context, q Query, name string, content []byte, mimeType string) (Result, error) {
+	b64, err := h.fileConverter.ToBase64(ctx, convert.ToBase64Request{Data: content})
+	if err != nil {
+		return Result{}, fmt.Errorf("extracttext: encode base64 for URL %q: %w", name, err)
+	}
+	rcn, err := h.recognizer.Recognize(ctx, recognize.Request{
+		ContentBase64: b64.Base64,
+		MimeType:      mimeType,
+		MinConfidence: q.MinConfidence,
+		Backend:       q.Backend,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("extracttext: recognize text (url=%q, mime=%s): %w", name, mimeType, err)
+	}
+	for i := range rcn.Pages {
+		if rcn.Pages[i].Source == "" {
+			rcn.Pages[i].Source = recognize.SourceOCR
+		}
+	}
+	return Result{
+		Text:          rcn.ExtractedText,
+		Pages:         rcn.Pages,
+		FilteredWords: rcn.FilteredWords,
+		FilteredLines: rcn.FilteredLines,
+		Backend:       rcn.Backend,
+		Cached:        rcn.Cached,
+	}, nil
+}
+
+// emitPages reports pages starting at index first of a file of total pages.
+func emitPages(q Query, pages []recognize.Page, first, total int, backend string) {
+	if q.OnPage == nil {
+		return
+	}
+	for i, p := range pages {
+		q.OnPage(PageEvent{
+			Index:   first + i,
+			Total:   max(total, first+i+1),
+			Page:    p,
+			Text:    recognize.JoinText([]recognize.Page{p}),
+			Backend: backend,
+		})
+	}
+}
+
+// fatal tells errors that would fail every other page or file the same way,
+// so there is no point in reporting them one by one.
+func fatal(ctx context.Context, err error) bool {
+	return ctx.Err() != nil || errors.Is(err, recognize.ErrUnknownBackend)
+}
+
+func baseMimeType(mimeType string) string {
+	return strings.ToLower(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0]))
 }
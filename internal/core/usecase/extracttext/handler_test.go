@@ -0,0 +1,252 @@
+package extracttext
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"doc2text/internal/core/abstraction/convert"
+	"doc2text/internal/core/abstraction/extract"
+	"doc2text/internal/core/abstraction/recognize"
+	"doc2text/internal/core/abstraction/split"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+type base64Converter struct{}
+
+func (base64Converter) ToBase64(_ context.Context, req convert.ToBase64Request) (convert.ToBase64Response, error) {
+	return convert.ToBase64Response{Base64: base64.StdEncoding.EncodeToString(req.Data)}, nil
+}
+
+// textExtractor reads the pages it is given; empty ones need OCR.
+type textExtractor []string
+
+func (e textExtractor) Extract(context.Context, extract.Request) (extract.Response, error) {
+	var res extract.Response
+	for i, text := range e {
+		res.Pages = append(res.Pages, textPage(text, recognize.SourceTextLayer))
+		if text == "" {
+			res.NeedsOCR = append(res.NeedsOCR, i)
+		}
+	}
+	return res, nil
+}
+
+// pageSplitter cuts a document of that many pages into parts whose content
+// names the page.
+type pageSplitter int
+
+func (s pageSplitter) Split(_ context.Context, req split.Request) (split.Response, error) {
+	res := split.Response{Total: int(s)}
+	pages := req.Pages
+	if len(pages) == 0 {
+		for i := range int(s) {
+			pages = append(pages, i)
+		}
+	}
+	for _, i := range pages {
+		res.Pages = append(res.Pages, split.Page{Index: i, Content: []byte(fmt.Sprintf("page-%d", i)), MimeType: req.MimeType})
+	}
+	return res, nil
+}
+
+// fakeRecognizer returns "ocr <content>" for a part and wholePages pages for
+// anything else, and fails the contents listed in fail.
+type fakeRecognizer struct {
+	wholePages int
+	fail       []string
+
+	mu   sync.Mutex
+	seen []string
+}
+
+func (r *fakeRecognizer) Recognize(_ context.Context, req recognize.Request) (recognize.Response, error) {
+	b, _ := base64.StdEncoding.DecodeString(req.ContentBase64)
+	content := string(b)
+	r.mu.Lock()
+	r.seen = append(r.seen, content)
+	r.mu.Unlock()
+	if slices.Contains(r.fail, content) {
+		return recognize.Response{}, errors.New("recognizer failed")
+	}
+	if !strings.HasPrefix(content, "page-") {
+		var pages []recognize.Page
+		for i := range r.wholePages {
+			pages = append(pages, textPage(fmt.Sprintf("ocr whole %d", i), ""))
+		}
+		return recognize.Response{Pages: pages, Backend: "fake"}, nil
+	}
+	return recognize.Response{Pages: []recognize.Page{textPage("ocr "+content, "")}, Backend: "fake"}, nil
+}
+
+func textPage(text, source string) recognize.Page {
+	p := recognize.Page{Source: source}
+	if text != "" {
+		p.Blocks = []recognize.Block{{Lines: []recognize.Line{{Text: text}}}}
+	}
+	return p
+}
+
+func pageTexts(pages []recognize.Page) []string {
+	texts := make([]string, len(pages))
+	for i, p := range pages {
+		texts[i] = strings.TrimSpace(recognize.JoinText([]recognize.Page{p}))
+	}
+	return texts
+}
+
+func newTestHandler(r recognize.Recognizer, e extract.Extractor, splitters map[string]split.Splitter) *QueryHandler {
+	extractors := map[string]extract.Extractor{}
+	if e != nil {
+		extractors["application/pdf"] = e
+	}
+	return NewHandler(base64Converter{}, nil, nopLogger{}, r, extractors, nil, nil, splitters, 2)
+}
+
+func pdfSplitter(pages int) map[string]split.Splitter {
+	return map[string]split.Splitter{"application/pdf": pageSplitter(pages)}
+}
+
+// collectEvents records the events of a streaming query by page index.
+func collectEvents(q *Query) map[int]PageEvent {
+	var mu sync.Mutex
+	events := map[int]PageEvent{}
+	q.OnPage = func(e PageEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events[e.Index] = e
+	}
+	return events
+}
+
+func pdfQuery() Query {
+	return Query{Upload: &Upload{Name: "doc.pdf", MimeType: "application/pdf", Content: []byte("doc")}}
+}
+
+func TestExtractRecognizesOnlyPagesWithoutText(t *testing.T) {
+	r := &fakeRecognizer{}
+	h := newTestHandler(r, textExtractor{"a", "", "c", ""}, pdfSplitter(4))
+
+	res, err := h.Handle(context.Background(), pdfQuery())
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	slices.Sort(r.seen)
+	if want := []string{"page-1", "page-3"}; !slices.Equal(r.seen, want) {
+		t.Fatalf("recognized %q, want %q", r.seen, want)
+	}
+	if got, want := pageTexts(res.Pages), []string{"a", "ocr page-1", "c", "ocr page-3"}; !slices.Equal(got, want) {
+		t.Fatalf("pages = %q, want %q", got, want)
+	}
+	if res.Pages[1].Source != recognize.SourceOCR || res.Pages[0].Source != recognize.SourceTextLayer {
+		t.Fatalf("sources = %q, %q", res.Pages[0].Source, res.Pages[1].Source)
+	}
+	if res.Backend != "fake" {
+		t.Fatalf("backend = %q", res.Backend)
+	}
+}
+
+func TestExtractWholeDocumentPicksPages(t *testing.T) {
+	r := &fakeRecognizer{wholePages: 3}
+	h := newTestHandler(r, textExtractor{"a", "", "c"}, nil)
+
+	res, err := h.Handle(context.Background(), pdfQuery())
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if got, want := pageTexts(res.Pages), []string{"a", "ocr whole 1", "c"}; !slices.Equal(got, want) {
+		t.Fatalf("pages = %q, want %q", got, want)
+	}
+}
+
+func TestExtractWholeDocumentMissingPage(t *testing.T) {
+	r := &fakeRecognizer{wholePages: 2}
+	h := newTestHandler(r, textExtractor{"a", "", "c", ""}, nil)
+
+	_, err := h.Handle(context.Background(), pdfQuery())
+	if err == nil || !strings.Contains(err.Error(), "no result for page 4") {
+		t.Fatalf("Handle() error = %v, want missing page 4", err)
+	}
+}
+
+func TestExtractStreamsFailedPage(t *testing.T) {
+	r := &fakeRecognizer{fail: []string{"page-1"}}
+	h := newTestHandler(r, textExtractor{"a", "", "c", ""}, pdfSplitter(4))
+
+	q := pdfQuery()
+	events := collectEvents(&q)
+	res, err := h.Handle(context.Background(), q)
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if len(events) != 4 || events[1].Err == nil || events[3].Err != nil || strings.TrimSpace(events[3].Text) != "ocr page-3" {
+		t.Fatalf("events = %+v", events)
+	}
+	if got, want := pageTexts(res.Pages), []string{"a", "", "c", "ocr page-3"}; !slices.Equal(got, want) {
+		t.Fatalf("pages = %q, want %q", got, want)
+	}
+}
+
+func TestExtractFailedPageFailsQuery(t *testing.T) {
+	r := &fakeRecognizer{fail: []string{"page-1"}}
+	h := newTestHandler(r, textExtractor{"a", "", "c", ""}, pdfSplitter(4))
+
+	if _, err := h.Handle(context.Background(), pdfQuery()); err == nil {
+		t.Fatal("Handle() error = nil, want the page's error")
+	}
+}
+
+func TestRecognizeScannedPageByPage(t *testing.T) {
+	r := &fakeRecognizer{}
+	h := newTestHandler(r, nil, pdfSplitter(3))
+
+	res, err := h.Handle(context.Background(), pdfQuery())
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if len(r.seen) != 3 || slices.Contains(r.seen, "doc") {
+		t.Fatalf("recognized %q, want every page on its own", r.seen)
+	}
+	if got, want := pageTexts(res.Pages), []string{"ocr page-0", "ocr page-1", "ocr page-2"}; !slices.Equal(got, want) {
+		t.Fatalf("pages = %q, want %q", got, want)
+	}
+}
+
+func TestRecognizeScannedStreamsFailedPage(t *testing.T) {
+	r := &fakeRecognizer{fail: []string{"page-0"}}
+	h := newTestHandler(r, nil, pdfSplitter(3))
+
+	q := pdfQuery()
+	events := collectEvents(&q)
+	res, err := h.Handle(context.Background(), q)
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if len(events) != 3 || events[0].Err == nil || events[1].Err != nil || events[2].Err != nil || events[2].Total != 3 {
+		t.Fatalf("events = %+v", events)
+	}
+	if got, want := pageTexts(res.Pages), []string{"ocr page-1", "ocr page-2"}; !slices.Equal(got, want) {
+		t.Fatalf("pages = %q, want %q", got, want)
+	}
+}
+
+func TestRecognizeSinglePageWhole(t *testing.T) {
+	r := &fakeRecognizer{wholePages: 1}
+	h := newTestHandler(r, nil, pdfSplitter(1))
+
+	if _, err := h.Handle(context.Background(), pdfQuery()); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if want := []string{"doc"}; !slices.Equal(r.seen, want) {
+		t.Fatalf("recognized %q, want %q", r.seen, want)
+	}
+}
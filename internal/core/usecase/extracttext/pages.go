@@ -0,0 +1,187 @@
+package extracttext
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"doc2text/internal/core/abstraction/recognize"
+	"doc2text/internal/core/abstraction/split"
+)
+
+// pageSet is the OCR of some pages of a document, keyed by page index.
+type pageSet struct {
+	pages         map[int]recognize.Page
+	filteredWords int
+	filteredLines int
+	backend       string
+	cached        bool
+}
+
+// recognizeSplit OCRs every page of a document a splitter can cut, one page
+// per call, so that each page is reported as soon as it is back and a failed
+// page does not take the others with it. ok is false when the document has
+// no splitter, has a single page, or cannot be split.
+func (h *QueryHandler) recognizeSplit(ctx context.Context, q Query, name string, content []byte, mimeType string) (Result, bool, error) {
+	s, ok := h.splitters[baseMimeType(mimeType)]
+	if !ok {
+		return Result{}, false, nil
+	}
+	parts, err := s.Split(ctx, split.Request{Content: content, MimeType: mimeType})
+	if err != nil {
+		if ctx.Err() != nil {
+			return Result{}, false, err
+		}
+		h.log.Error("extracttext: split %q (mime=%s): %v; recognizing it whole", name, mimeType, err)
+		return Result{}, false, nil
+	}
+	if parts.Total <= 1 {
+		return Result{}, false, nil
+	}
+
+	set, err := h.recognizeParts(ctx, q, name, parts.Pages, parts.Total)
+	if err != nil {
+		return Result{}, true, err
+	}
+	res := Result{
+		FilteredWords: set.filteredWords,
+		FilteredLines: set.filteredLines,
+		Backend:       set.backend,
+		Cached:        set.cached,
+	}
+	// Failed pages are left out, as failed frames of an image are.
+	for i := range parts.Total {
+		if p, ok := set.pages[i]; ok {
+			res.Pages = append(res.Pages, p)
+		}
+	}
+	res.Text = recognize.JoinText(res.Pages)
+	return res, true, nil
+}
+
+// recognizePages OCRs the pages idx of a document of total pages and
+// reports each as soon as it is back. Documents with a splitter are sent a
+// page at a time; others are sent whole and the pages picked by index, and
+// a page the recognizer did not return is an error. Without OnPage the first
+// failed page fails the call; streaming callers get an error event for it
+// and the other pages go on.
+func (h *QueryHandler) recognizePages(ctx context.Context, q Query, name string, content []byte, mimeType string, idx []int, total int) (pageSet, error) {
+	if s, ok := h.splitters[baseMimeType(mimeType)]; ok {
+		parts, err := s.Split(ctx, split.Request{Content: content, MimeType: mimeType, Pages: idx})
+		if err == nil {
+			return h.recognizeParts(ctx, q, name, parts.Pages, total)
+		}
+		if ctx.Err() != nil {
+			return pageSet{}, err
+		}
+		h.log.Error("extracttext: split %q (mime=%s): %v; recognizing it whole", name, mimeType, err)
+	}
+
+	set := pageSet{pages: map[int]recognize.Page{}}
+	res, err := h.recognizeImage(ctx, q, name, content, mimeType)
+	if err != nil {
+		for _, i := range idx {
+			if err := h.pageFailed(ctx, q, i, total, err); err != nil {
+				return pageSet{}, err
+			}
+		}
+		return set, nil
+	}
+	for _, i := range idx {
+		if i >= len(res.Pages) {
+			err := fmt.Errorf("extracttext: recognize %q: no result for page %d, got %d pages", name, i+1, len(res.Pages))
+			if err := h.pageFailed(ctx, q, i, total, err); err != nil {
+				return pageSet{}, err
+			}
+			continue
+		}
+		set.pages[i] = res.Pages[i]
+		emitPages(q, res.Pages[i:i+1], i, total, res.Backend)
+	}
+	set.filteredWords, set.filteredLines = res.FilteredWords, res.FilteredLines
+	set.backend, set.cached = res.Backend, res.Cached
+	return set, nil
+}
+
+// recognizeParts OCRs single-page documents cut from a file of total pages,
+// pageConcurrency at a time, and reports each page as it is back.
+func (h *QueryHandler) recognizeParts(ctx context.Context, q Query, name string, parts []split.Page, total int) (pageSet, error) {
+	type outcome struct {
+		part split.Page
+		res  Result
+		err  error
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan split.Page)
+	out := make(chan outcome, len(parts))
+	go func() {
+		defer close(jobs)
+		for _, p := range parts {
+			select {
+			case jobs <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for range min(max(h.pageConcurrency, 1), len(parts)) {
+		go func() {
+			for p := range jobs {
+				pageName := fmt.Sprintf("%s#%d", name, p.Index+1)
+				r, err := h.recognizeImage(ctx, q, pageName, p.Content, p.MimeType)
+				if err == nil && len(r.Pages) != 1 {
+					err = fmt.Errorf("extracttext: recognize %q: got %d pages for one", pageName, len(r.Pages))
+				}
+				out <- outcome{part: p, res: r, err: err}
+			}
+		}()
+	}
+
+	var (
+		set      = pageSet{pages: map[int]recognize.Page{}}
+		backends []string
+		hits     int
+	)
+	for range parts {
+		var o outcome
+		select {
+		case o = <-out:
+		case <-ctx.Done():
+			return pageSet{}, ctx.Err()
+		}
+		if o.err != nil {
+			if err := h.pageFailed(ctx, q, o.part.Index, total, o.err); err != nil {
+				return pageSet{}, err
+			}
+			continue
+		}
+		r := o.res
+		set.pages[o.part.Index] = r.Pages[0]
+		emitPages(q, r.Pages, o.part.Index, total, r.Backend)
+		set.filteredWords += r.FilteredWords
+		set.filteredLines += r.FilteredLines
+		if r.Cached {
+			hits++
+		}
+		if r.Backend != "" && !slices.Contains(backends, r.Backend) {
+			backends = append(backends, r.Backend)
+		}
+	}
+	set.backend = strings.Join(backends, ",")
+	set.cached = len(parts) > 0 && hits == len(parts)
+	return set, nil
+}
+
+// pageFailed reports a page that could not be recognized to a streaming
+// caller and returns nil, or returns err when the whole call should fail.
+func (h *QueryHandler) pageFailed(ctx context.Context, q Query, index, total int, err error) error {
+	if q.OnPage == nil || fatal(ctx, err) {
+		return err
+	}
+	h.log.Error("%v", err)
+	q.OnPage(PageEvent{Index: index, Total: total, Err: err})
+	return nil
+}
@@ -1,13 +1,62 @@
 package extracttext
 
-import "context"
+import (
+	"context"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
 
 type Query struct {
-	ObjectKey string
+	ObjectKey     string
+	MinConfidence *float64
+	Backend       string
+	// Upload carries the file itself; ObjectKey is ignored when it is set.
+	Upload *Upload
+	// OnPage, when set, receives every page as soon as it is ready. A page
+	// that fails is reported here instead of failing the whole document.
+	OnPage func(PageEvent)
+}
+
+// PageEvent is one page of the document, or the failure to get it. Pages
+// come in the order they are ready, which is not the page order.
+type PageEvent struct {
+	// Entry is the archive path of the file the page belongs to.
+	Entry string
+	// Index is the page's position in its file; Total is the file's page
+	// count. Both are zero when a whole archive entry fails.
+	Index   int
+	Total   int
+	Page    recognize.Page
+	Text    string
+	Backend string
+	Err     error
+}
+
+// Upload is a file sent with the request instead of stored in the bucket.
+type Upload struct {
+	Name     string
+	MimeType string
+	Content  []byte
 }
 
 type Result struct {
-	Text string
+	Text          string
+	Pages         []recognize.Page
+	FilteredWords int
+	FilteredLines int
+	Backend       string
+	// Cached is set when all OCR in the result came from the cache.
+	Cached bool
+	// Entries is set instead of Pages when the object is an archive.
+	Entries []Entry
+}
+
+// Entry is the outcome for one file of an archive.
+type Entry struct {
+	Path     string
+	MimeType string
+	Result   Result
+	Err      error
 }
 
 func (Query) IsQuery() {}
@@ -0,0 +1,106 @@
+package extracttext
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"doc2text/internal/core/abstraction/logger"
+	"doc2text/internal/core/abstraction/storage"
+)
+
+// Sidecars are named after the source object plus these suffixes.
+const (
+	SidecarTextSuffix = ".txt"
+	SidecarJSONSuffix = ".ocr.json"
+)
+
+// Metadata set on sidecars. The source key is URL-encoded, since metadata
+// values must be ASCII.
+const (
+	MetaSourceKey  = "Doc2text-Source-Key"
+	MetaSourceETag = "Doc2text-Source-Etag"
+	MetaRecognizer = "Doc2text-Recognizer"
+	MetaVersion    = "Doc2text-Version"
+)
+
+type SidecarOptions struct {
+	// Bucket and Prefix place the sidecars; by default they go to the
+	// source bucket, right next to the object.
+	Bucket string
+	Prefix string
+	// Version identifies the build that produced the text.
+	Version string
+	// Encode renders the .ocr.json sidecar; it is not written when nil.
+	Encode func(Result) ([]byte, error)
+}
+
+type SidecarHandler struct {
+	handler Handler
+	storage storage.Storage
+	log     logger.Logger
+	opts    SidecarOptions
+}
+
+// NewSidecarHandler stores the text and result of every object h reads
+// from storage. Uploaded files are passed through as they are.
+func NewSidecarHandler(h Handler, s storage.Storage, l logger.Logger, o SidecarOptions) *SidecarHandler {
+	return &SidecarHandler{handler: h, storage: s, log: l, opts: o}
+}
+
+// Handle reads the source ETag before h downloads the object, so sidecars
+// of an object replaced meanwhile name the older version and look stale
+// rather than current. Failing to write them does not fail the query.
+func (s *SidecarHandler) Handle(ctx context.Context, q Query) (Result, error) {
+	if q.Upload != nil {
+		return s.handler.Handle(ctx, q)
+	}
+	fi, err := s.storage.GetInfo(ctx, storage.GetInfoRequest{ObjectKey: q.ObjectKey})
+	if err != nil {
+		return Result{}, fmt.Errorf("extracttext: stat %q: %w", q.ObjectKey, err)
+	}
+	res, err := s.handler.Handle(ctx, q)
+	if err != nil {
+		return res, err
+	}
+	if err := s.write(ctx, q.ObjectKey, fi.ETag, res); err != nil {
+		s.log.Error("extracttext: sidecars of %q: %v", q.ObjectKey, err)
+	}
+	return res, nil
+}
+
+func (s *SidecarHandler) write(ctx context.Context, key, etag string, res Result) error {
+	meta := map[string]string{
+		MetaSourceKey:  url.PathEscape(key),
+		MetaSourceETag: etag,
+	}
+	if res.Backend != "" {
+		meta[MetaRecognizer] = res.Backend
+	}
+	if s.opts.Version != "" {
+		meta[MetaVersion] = s.opts.Version
+	}
+
+	base := s.opts.Prefix + key
+	err := s.storage.PutFile(ctx, storage.PutFileRequest{
+		Bucket:    s.opts.Bucket,
+		ObjectKey: base + SidecarTextSuffix,
+		Content:   []byte(res.Text),
+		MimeType:  "text/plain; charset=utf-8",
+		Metadata:  meta,
+	})
+	if err != nil || s.opts.Encode == nil {
+		return err
+	}
+	body, err := s.opts.Encode(res)
+	if err != nil {
+		return fmt.Errorf("encode result: %w", err)
+	}
+	return s.storage.PutFile(ctx, storage.PutFileRequest{
+		Bucket:    s.opts.Bucket,
+		ObjectKey: base + SidecarJSONSuffix,
+		Content:   body,
+		MimeType:  "application/json",
+		Metadata:  meta,
+	})
+}
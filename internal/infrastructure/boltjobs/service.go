@@ -0,0 +1,272 @@
+package boltjobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"doc2text/internal/core/abstraction/job"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketJobs = []byte("jobs")
+	bucketKeys = []byte("keys")
+)
+
+// Store keeps jobs in a single bbolt file, keyed by ID. IDs sort by
+// creation time, so key order is queue order. A second bucket maps
+// idempotency keys to job IDs.
+type Store struct {
+	db *bolt.DB
+}
+
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("boltjobs: open %q: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketJobs, bucketKeys} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("boltjobs: create buckets: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) Create(_ context.Context, j job.Job) (job.Job, error) {
+	out := j
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, keys := tx.Bucket(bucketJobs), tx.Bucket(bucketKeys)
+		if b.Get([]byte(j.ID)) != nil {
+			return fmt.Errorf("boltjobs: job %s already exists", j.ID)
+		}
+		if j.IdempotencyKey != "" {
+			if id := keys.Get([]byte(j.IdempotencyKey)); id != nil {
+				var err error
+				out, err = get(b, string(id))
+				return err
+			}
+			if err := keys.Put([]byte(j.IdempotencyKey), []byte(j.ID)); err != nil {
+				return err
+			}
+		}
+		return put(b, j)
+	})
+	if err != nil {
+		return job.Job{}, err
+	}
+	return out, nil
+}
+
+func (s *Store) Get(_ context.Context, id string) (job.Job, error) {
+	var j job.Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		j, err = get(tx.Bucket(bucketJobs), id)
+		return err
+	})
+	return j, err
+}
+
+func (s *Store) Update(_ context.Context, id string, fn func(*job.Job) error) (job.Job, error) {
+	var j job.Job
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketJobs)
+		var err error
+		if j, err = get(b, id); err != nil {
+			return err
+		}
+		if err := fn(&j); err != nil {
+			return err
+		}
+		return put(b, j)
+	})
+	if err != nil {
+		return job.Job{}, err
+	}
+	return j, nil
+}
+
+func (s *Store) Claim(_ context.Context, now time.Time) (job.Job, error) {
+	var claimed job.Job
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketJobs)
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var j job.Job
+			if err := json.Unmarshal(v, &j); err != nil {
+				return fmt.Errorf("boltjobs: decode %s: %w", k, err)
+			}
+			if j.State != job.StateQueued {
+				continue
+			}
+			j.State = job.StateRunning
+			j.StartedAt = now
+			claimed = j
+			return put(b, j)
+		}
+		return job.ErrNotFound
+	})
+	if err != nil {
+		return job.Job{}, err
+	}
+	return claimed, nil
+}
+
+func (s *Store) List(_ context.Context, req job.ListRequest) ([]job.Job, error) {
+	var out []job.Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketJobs).Cursor()
+		k, v := c.Last()
+		if req.After != "" {
+			// Seek lands on the first key >= After; step back past it.
+			if k, v = c.Seek([]byte(req.After)); k == nil {
+				k, v = c.Last()
+			}
+			for k != nil && bytes.Compare(k, []byte(req.After)) >= 0 {
+				k, v = c.Prev()
+			}
+		}
+		for ; k != nil; k, v = c.Prev() {
+			var j job.Job
+			if err := json.Unmarshal(v, &j); err != nil {
+				return fmt.Errorf("boltjobs: decode %s: %w", k, err)
+			}
+			if req.State != "" && j.State != req.State {
+				continue
+			}
+			out = append(out, j)
+			if req.Limit > 0 && len(out) == req.Limit {
+				return nil
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (s *Store) Requeue(_ context.Context) (int, error) {
+	n := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketJobs)
+		var stale []job.Job
+		err := b.ForEach(func(k, v []byte) error {
+			var j job.Job
+			if err := json.Unmarshal(v, &j); err != nil {
+				return fmt.Errorf("boltjobs: decode %s: %w", k, err)
+			}
+			if j.State == job.StateRunning {
+				stale = append(stale, j)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, j := range stale {
+			j.State = job.StateQueued
+			j.StartedAt = time.Time{}
+			if err := put(b, j); err != nil {
+				return err
+			}
+		}
+		n = len(stale)
+		return nil
+	})
+	return n, err
+}
+
+func (s *Store) DeleteExpired(_ context.Context, now time.Time) (int, error) {
+	n := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, keys := tx.Bucket(bucketJobs), tx.Bucket(bucketKeys)
+		var expired []job.Job
+		err := b.ForEach(func(k, v []byte) error {
+			var j job.Job
+			if err := json.Unmarshal(v, &j); err != nil {
+				return fmt.Errorf("boltjobs: decode %s: %w", k, err)
+			}
+			if j.State.Final() && !j.ExpiresAt.IsZero() && j.ExpiresAt.Before(now) && j.Callback != job.CallbackPending {
+				expired = append(expired, j)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, j := range expired {
+			if err := b.Delete([]byte(j.ID)); err != nil {
+				return err
+			}
+			// Replays of the key after this run as new jobs.
+			if j.IdempotencyKey != "" && string(keys.Get([]byte(j.IdempotencyKey))) == j.ID {
+				if err := keys.Delete([]byte(j.IdempotencyKey)); err != nil {
+					return err
+				}
+			}
+		}
+		n = len(expired)
+		return nil
+	})
+	return n, err
+}
+
+func (s *Store) DueCallbacks(_ context.Context, now time.Time, limit int) ([]job.Job, error) {
+	var due []job.Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketJobs).ForEach(func(k, v []byte) error {
+			var j job.Job
+			if err := json.Unmarshal(v, &j); err != nil {
+				return fmt.Errorf("boltjobs: decode %s: %w", k, err)
+			}
+			if j.Callback == job.CallbackPending && !j.NextCallbackAt.After(now) {
+				due = append(due, j)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(due, func(a, b int) bool { return due[a].NextCallbackAt.Before(due[b].NextCallbackAt) })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func get(b *bolt.Bucket, id string) (job.Job, error) {
+	v := b.Get([]byte(id))
+	if v == nil {
+		return job.Job{}, fmt.Errorf("boltjobs: %w: %s", job.ErrNotFound, id)
+	}
+	var j job.Job
+	if err := json.Unmarshal(v, &j); err != nil {
+		return job.Job{}, fmt.Errorf("boltjobs: decode %s: %w", id, err)
+	}
+	return j, nil
+}
+
+func put(b *bolt.Bucket, j job.Job) error {
+	v, err := json.Marshal(j)
+	if err != nil {
+		return fmt.Errorf("boltjobs: encode %s: %w", j.ID, err)
+	}
+	return b.Put([]byte(j.ID), v)
+}
@@ -0,0 +1,202 @@
+package boltjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"doc2text/internal/core/abstraction/job"
+)
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+	st, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func create(t *testing.T, st *Store, j job.Job) job.Job {
+	t.Helper()
+	if j.State == "" {
+		j.State = job.StateQueued
+	}
+	got, err := st.Create(context.Background(), j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func ids(jobs []job.Job) []string {
+	out := make([]string, len(jobs))
+	for i, j := range jobs {
+		out[i] = j.ID
+	}
+	return out
+}
+
+func TestCreateIdempotent(t *testing.T) {
+	ctx := context.Background()
+	st := openStore(t, filepath.Join(t.TempDir(), "jobs.db"))
+
+	first := create(t, st, job.Job{ID: "a", ObjectKey: "x.pdf", IdempotencyKey: "k"})
+	if got := create(t, st, job.Job{ID: "b", ObjectKey: "y.pdf", IdempotencyKey: "k"}); got.ID != "a" || got.ObjectKey != first.ObjectKey {
+		t.Fatalf("replayed Create = %+v, want job a", got)
+	}
+	if _, err := st.Get(ctx, "b"); !errors.Is(err, job.ErrNotFound) {
+		t.Fatalf("Get(b) error = %v, want ErrNotFound", err)
+	}
+	// Without a key every Create stores a job.
+	create(t, st, job.Job{ID: "c"})
+	create(t, st, job.Job{ID: "d"})
+	if all, _ := st.List(ctx, job.ListRequest{}); len(all) != 3 {
+		t.Fatalf("stored %v, want a, c, d", ids(all))
+	}
+	if _, err := st.Create(ctx, job.Job{ID: "a"}); err == nil {
+		t.Fatal("Create with an existing ID succeeded")
+	}
+}
+
+func TestClaimOrder(t *testing.T) {
+	ctx := context.Background()
+	st := openStore(t, filepath.Join(t.TempDir(), "jobs.db"))
+	create(t, st, job.Job{ID: "j1"})
+	create(t, st, job.Job{ID: "j2", State: job.StateSucceeded})
+	create(t, st, job.Job{ID: "j3"})
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, want := range []string{"j1", "j3"} {
+		j, err := st.Claim(ctx, now)
+		if err != nil || j.ID != want || j.State != job.StateRunning || !j.StartedAt.Equal(now) {
+			t.Fatalf("Claim() = %+v, %v, want %s running", j, err, want)
+		}
+		if stored, _ := st.Get(ctx, want); stored.State != job.StateRunning {
+			t.Fatalf("stored %s is %s", want, stored.State)
+		}
+	}
+	if _, err := st.Claim(ctx, now); !errors.Is(err, job.ErrNotFound) {
+		t.Fatalf("Claim() on an empty queue error = %v", err)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	ctx := context.Background()
+	st := openStore(t, filepath.Join(t.TempDir(), "jobs.db"))
+	for _, id := range []string{"j1", "j2", "j3", "j4", "j5"} {
+		state := job.StateQueued
+		if id == "j2" || id == "j4" {
+			state = job.StateFailed
+		}
+		create(t, st, job.Job{ID: id, State: state})
+	}
+
+	for _, tc := range []struct {
+		req  job.ListRequest
+		want string
+	}{
+		{job.ListRequest{Limit: 2}, "[j5 j4]"},
+		{job.ListRequest{Limit: 2, After: "j4"}, "[j3 j2]"},
+		{job.ListRequest{Limit: 2, After: "j2"}, "[j1]"},
+		{job.ListRequest{After: "j1"}, "[]"},
+		// A removed job still marks the place in the listing.
+		{job.ListRequest{After: "j35"}, "[j3 j2 j1]"},
+		{job.ListRequest{After: "j9"}, "[j5 j4 j3 j2 j1]"},
+		{job.ListRequest{State: job.StateFailed}, "[j4 j2]"},
+		{job.ListRequest{State: job.StateQueued, Limit: 1, After: "j5"}, "[j3]"},
+	} {
+		jobs, err := st.List(ctx, tc.req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(ids(jobs)); got != tc.want {
+			t.Errorf("List(%+v) = %s, want %s", tc.req, got, tc.want)
+		}
+	}
+}
+
+func TestRequeueAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	st, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	create(t, st, job.Job{ID: "j1"})
+	create(t, st, job.Job{ID: "j2"})
+	create(t, st, job.Job{ID: "j3", State: job.StateSucceeded})
+	if _, err := st.Claim(ctx, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	st = openStore(t, path)
+	if n, err := st.Requeue(ctx); err != nil || n != 1 {
+		t.Fatalf("Requeue() = %d, %v, want 1", n, err)
+	}
+	j, _ := st.Get(ctx, "j1")
+	if j.State != job.StateQueued || !j.StartedAt.IsZero() {
+		t.Fatalf("requeued job = %+v", j)
+	}
+	if j, _ := st.Get(ctx, "j3"); j.State != job.StateSucceeded {
+		t.Fatalf("finished job became %s", j.State)
+	}
+	if j, err := st.Claim(ctx, time.Now()); err != nil || j.ID != "j1" {
+		t.Fatalf("Claim() = %s, %v, want the requeued job first", j.ID, err)
+	}
+}
+
+func TestDeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	st := openStore(t, filepath.Join(t.TempDir(), "jobs.db"))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	create(t, st, job.Job{ID: "expired", State: job.StateSucceeded, ExpiresAt: past, IdempotencyKey: "k"})
+	create(t, st, job.Job{ID: "delivered", State: job.StateFailed, ExpiresAt: past, Callback: job.CallbackDelivered})
+	create(t, st, job.Job{ID: "pending", State: job.StateSucceeded, ExpiresAt: past, Callback: job.CallbackPending})
+	create(t, st, job.Job{ID: "fresh", State: job.StateSucceeded, ExpiresAt: future})
+	create(t, st, job.Job{ID: "queued", ExpiresAt: past})
+	create(t, st, job.Job{ID: "kept", State: job.StateCancelled})
+
+	if n, err := st.DeleteExpired(ctx, now); err != nil || n != 2 {
+		t.Fatalf("DeleteExpired() = %d, %v, want 2", n, err)
+	}
+	left, _ := st.List(ctx, job.ListRequest{})
+	if got := fmt.Sprint(ids(left)); got != "[queued pending kept fresh]" {
+		t.Fatalf("left %s", got)
+	}
+	// The key of a deleted job is free again.
+	if j := create(t, st, job.Job{ID: "replay", IdempotencyKey: "k"}); j.ID != "replay" {
+		t.Fatalf("Create after expiry = %s, want a new job", j.ID)
+	}
+}
+
+func TestDueCallbacks(t *testing.T) {
+	ctx := context.Background()
+	st := openStore(t, filepath.Join(t.TempDir(), "jobs.db"))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	create(t, st, job.Job{ID: "j1", Callback: job.CallbackPending, NextCallbackAt: now.Add(-time.Second)})
+	create(t, st, job.Job{ID: "j2", Callback: job.CallbackPending, NextCallbackAt: now.Add(-time.Minute)})
+	create(t, st, job.Job{ID: "j3", Callback: job.CallbackPending, NextCallbackAt: now.Add(time.Second)})
+	create(t, st, job.Job{ID: "j4", Callback: job.CallbackDelivered, NextCallbackAt: now.Add(-time.Hour)})
+	create(t, st, job.Job{ID: "j5", Callback: job.CallbackPending, NextCallbackAt: now})
+
+	due, err := st.DueCallbacks(ctx, now, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(ids(due)); got != "[j2 j1 j5]" {
+		t.Fatalf("DueCallbacks() = %s, want the longest waiting first", got)
+	}
+	if due, _ := st.DueCallbacks(ctx, now, 1); fmt.Sprint(ids(due)) != "[j2]" {
+		t.Fatalf("DueCallbacks(limit 1) = %s", fmt.Sprint(ids(due)))
+	}
+}
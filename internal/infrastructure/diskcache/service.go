@@ -0,0 +1,164 @@
+package diskcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"doc2text/internal/core/abstraction/cache"
+	"doc2text/internal/core/abstraction/logger"
+)
+
+// headerSize is the expiry, in Unix nanoseconds, written before the value;
+// zero never expires.
+const headerSize = 8
+
+type Options struct {
+	Dir string
+	// SweepInterval is how often expired files are deleted.
+	SweepInterval time.Duration
+	Logger        logger.Logger
+}
+
+// Cache keeps one file per key under Dir, fanned out by the first byte of
+// the key's hash. Files are replaced atomically, so several processes may
+// share the directory.
+type Cache struct {
+	dir  string
+	log  logger.Logger
+	stop chan struct{}
+	done chan struct{}
+}
+
+var _ cache.Store = (*Cache)(nil)
+
+func Open(o Options) (*Cache, error) {
+	if err := os.MkdirAll(o.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("diskcache: %w", err)
+	}
+	if o.SweepInterval <= 0 {
+		o.SweepInterval = 10 * time.Minute
+	}
+	c := &Cache{dir: o.Dir, log: o.Logger, stop: make(chan struct{}), done: make(chan struct{})}
+	go c.sweepEvery(o.SweepInterval)
+	return c, nil
+}
+
+// Close stops the sweeper; the files stay.
+func (c *Cache) Close() error {
+	close(c.stop)
+	<-c.done
+	return nil
+}
+
+func (c *Cache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	path := c.path(key)
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("diskcache: %w", err)
+	}
+	if len(b) < headerSize || expired(b, time.Now()) {
+		_ = os.Remove(path)
+		return nil, false, nil
+	}
+	return b[headerSize:], true, nil
+}
+
+func (c *Cache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("diskcache: %w", err)
+	}
+	var header [headerSize]byte
+	if ttl > 0 {
+		binary.BigEndian.PutUint64(header[:], uint64(time.Now().Add(ttl).UnixNano()))
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("diskcache: %w", err)
+	}
+	_, err = f.Write(append(header[:], value...))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("diskcache: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name[:2], name)
+}
+
+func (c *Cache) sweepEvery(interval time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+		n, err := c.sweep(time.Now())
+		if err != nil {
+			c.log.Error("diskcache: sweep: %v", err)
+		} else if n > 0 {
+			c.log.Info("diskcache: deleted %d expired entries", n)
+		}
+	}
+}
+
+// sweep deletes expired entries and temp files left by a crash.
+func (c *Cache) sweep(now time.Time) (int, error) {
+	n := 0
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".tmp-") {
+			if info, err := d.Info(); err == nil && now.Sub(info.ModTime()) > time.Hour {
+				_ = os.Remove(path)
+			}
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		var header [headerSize]byte
+		_, rerr := io.ReadFull(f, header[:])
+		f.Close()
+		if rerr != nil || expired(header[:], now) {
+			if os.Remove(path) == nil {
+				n++
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+func expired(b []byte, now time.Time) bool {
+	exp := int64(binary.BigEndian.Uint64(b[:headerSize]))
+	return exp != 0 && now.UnixNano() > exp
+}
@@ -0,0 +1,84 @@
+package diskcache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+func openCache(t *testing.T) *Cache {
+	t.Helper()
+	c, err := Open(Options{Dir: t.TempDir(), SweepInterval: time.Hour, Logger: nopLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestSetGet(t *testing.T) {
+	ctx := context.Background()
+	c := openCache(t)
+	if _, ok, err := c.Get(ctx, "k"); ok || err != nil {
+		t.Fatalf("Get() on empty cache = %t, %v", ok, err)
+	}
+	if err := c.Set(ctx, "k", []byte("v1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(ctx, "k", []byte("v2"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	b, ok, err := c.Get(ctx, "k")
+	if err != nil || !ok || string(b) != "v2" {
+		t.Fatalf("Get() = %q, %t, %v", b, ok, err)
+	}
+}
+
+func TestExpiredEntryRemoved(t *testing.T) {
+	ctx := context.Background()
+	c := openCache(t)
+	c.Set(ctx, "k", []byte("v"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := c.Get(ctx, "k"); ok {
+		t.Fatal("expired entry served")
+	}
+	if _, err := os.Stat(c.path("k")); !os.IsNotExist(err) {
+		t.Fatalf("expired file kept: %v", err)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	ctx := context.Background()
+	c := openCache(t)
+	c.Set(ctx, "old", []byte("v"), time.Minute)
+	c.Set(ctx, "new", []byte("v"), time.Hour)
+	c.Set(ctx, "forever", []byte("v"), 0)
+	stale := filepath.Join(c.dir, ".tmp-1")
+	fresh := filepath.Join(c.dir, ".tmp-2")
+	os.WriteFile(stale, nil, 0o600)
+	os.WriteFile(fresh, nil, 0o600)
+	os.Chtimes(stale, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour))
+
+	n, err := c.sweep(time.Now().Add(10 * time.Minute))
+	if err != nil || n != 1 {
+		t.Fatalf("sweep() = %d, %v, want 1 entry", n, err)
+	}
+	for key, want := range map[string]bool{"old": false, "new": true, "forever": true} {
+		if _, err := os.Stat(c.path(key)); (err == nil) != want {
+			t.Errorf("%s: stat error %v, want kept=%t", key, err, want)
+		}
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale temp file kept: %v", err)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("fresh temp file removed: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package imagefit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strings"
+
+	"doc2text/internal/core/abstraction/recognize"
+
+	"golang.org/x/image/draw"
+)
+
+// Limits describe what a recognizer accepts; zero means no limit.
+type Limits struct {
+	MaxBytes  int64
+	MaxPixels int64
+	MaxSide   int
+}
+
+// maxAttempts bounds how many times the image is shrunk further when
+// recompression alone does not get it under MaxBytes.
+const maxAttempts = 6
+
+var jpegQualities = []int{90, 80, 70, 60}
+
+type fitRecognizer struct {
+	next   recognize.Recognizer
+	limits Limits
+}
+
+// Wrap returns a recognizer that downsamples and recompresses JPEG and PNG
+// images to fit the limits before calling next, and scales the coordinates
+// of the result back to the original image. Other content passes through.
+func Wrap(next recognize.Recognizer, l Limits) recognize.Recognizer {
+	if l == (Limits{}) {
+		return next
+	}
+	return &fitRecognizer{next: next, limits: l}
+}
+
+func (r *fitRecognizer) Recognize(ctx context.Context, req recognize.Request) (recognize.Response, error) {
+	mimeType := strings.ToLower(strings.TrimSpace(strings.SplitN(req.MimeType, ";", 2)[0]))
+	if mimeType != "image/jpeg" && mimeType != "image/png" {
+		return r.next.Recognize(ctx, req)
+	}
+	content, err := base64.StdEncoding.DecodeString(req.ContentBase64)
+	if err != nil {
+		return recognize.Response{}, fmt.Errorf("imagefit: decode base64: %w", err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		// Let the recognizer report what is wrong with the file.
+		return r.next.Recognize(ctx, req)
+	}
+	if r.fits(int64(len(content)), cfg.Width, cfg.Height) {
+		return r.next.Recognize(ctx, req)
+	}
+
+	fitted, fittedType, w, h, err := r.fit(ctx, content, mimeType)
+	if err != nil {
+		return recognize.Response{}, err
+	}
+	req.ContentBase64 = base64.StdEncoding.EncodeToString(fitted)
+	req.MimeType = fittedType
+	res, err := r.next.Recognize(ctx, req)
+	if err != nil {
+		return recognize.Response{}, err
+	}
+	scalePages(res.Pages, float64(cfg.Width)/float64(w), float64(cfg.Height)/float64(h), cfg.Width, cfg.Height)
+	return res, nil
+}
+
+func (r *fitRecognizer) fits(size int64, w, h int) bool {
+	l := r.limits
+	return (l.MaxBytes == 0 || size <= l.MaxBytes) &&
+		(l.MaxPixels == 0 || int64(w)*int64(h) <= l.MaxPixels) &&
+		(l.MaxSide == 0 || max(w, h) <= l.MaxSide)
+}
+
+// fit returns the re-encoded image with its type and dimensions.
+func (r *fitRecognizer) fit(ctx context.Context, content []byte, mimeType string) ([]byte, string, int, int, error) {
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", 0, 0, fmt.Errorf("imagefit: decode %s: %w", mimeType, err)
+	}
+	b := src.Bounds()
+	scale := r.scale(b.Dx(), b.Dy())
+
+	for range maxAttempts {
+		if err := ctx.Err(); err != nil {
+			return nil, "", 0, 0, err
+		}
+		w := max(1, int(math.Floor(float64(b.Dx())*scale)))
+		h := max(1, int(math.Floor(float64(b.Dy())*scale)))
+		img := src
+		if w != b.Dx() || h != b.Dy() {
+			dst := image.NewRGBA(image.Rect(0, 0, w, h))
+			draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+			img = dst
+		}
+
+		// Scans and screenshots stay lossless while they fit.
+		if mimeType == "image/png" {
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, img); err == nil && r.fitsBytes(buf.Len()) {
+				return buf.Bytes(), "image/png", w, h, nil
+			}
+		}
+		for _, q := range jpegQualities {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: q}); err != nil {
+				return nil, "", 0, 0, fmt.Errorf("imagefit: encode jpeg: %w", err)
+			}
+			if r.fitsBytes(buf.Len()) {
+				return buf.Bytes(), "image/jpeg", w, h, nil
+			}
+		}
+		scale *= 0.75
+	}
+	return nil, "", 0, 0, fmt.Errorf("imagefit: %w: cannot fit %dx%d image into %d bytes", recognize.ErrTooLarge, b.Dx(), b.Dy(), r.limits.MaxBytes)
+}
+
+func (r *fitRecognizer) fitsBytes(n int) bool {
+	return r.limits.MaxBytes == 0 || int64(n) <= r.limits.MaxBytes
+}
+
+// scale is the largest factor, at most 1, that satisfies the pixel limits.
+func (r *fitRecognizer) scale(w, h int) float64 {
+	s := 1.0
+	if l := r.limits.MaxPixels; l > 0 && int64(w)*int64(h) > l {
+		s = min(s, math.Sqrt(float64(l)/(float64(w)*float64(h))))
+	}
+	if l := r.limits.MaxSide; l > 0 && max(w, h) > l {
+		s = min(s, float64(l)/float64(max(w, h)))
+	}
+	return s
+}
+
+func scalePages(pages []recognize.Page, fx, fy float64, width, height int) {
+	scale := func(p *recognize.Polygon) {
+		for i := range p.Vertices {
+			p.Vertices[i].X = int64(math.Round(float64(p.Vertices[i].X) * fx))
+			p.Vertices[i].Y = int64(math.Round(float64(p.Vertices[i].Y) * fy))
+		}
+	}
+	for pi := range pages {
+		p := &pages[pi]
+		p.Width, p.Height = int64(width), int64(height)
+		for bi := range p.Blocks {
+			b := &p.Blocks[bi]
+			scale(&b.BoundingBox)
+			for li := range b.Lines {
+				l := &b.Lines[li]
+				scale(&l.BoundingBox)
+				for wi := range l.Words {
+					scale(&l.Words[wi].BoundingBox)
+				}
+			}
+		}
+	}
+}
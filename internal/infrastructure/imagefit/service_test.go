@@ -0,0 +1,139 @@
+package imagefit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/png"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+// boxRecognizer reports the image it was sent and finds one word on it at
+// fixed coordinates of that image.
+type boxRecognizer struct {
+	calls    int
+	mimeType string
+	content  string
+	size     image.Point
+}
+
+func box(x0, y0, x1, y1 int64) recognize.Polygon {
+	return recognize.Polygon{Vertices: []recognize.Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}}
+}
+
+func (r *boxRecognizer) Recognize(_ context.Context, req recognize.Request) (recognize.Response, error) {
+	r.calls++
+	r.mimeType, r.content = req.MimeType, req.ContentBase64
+	raw, err := base64.StdEncoding.DecodeString(req.ContentBase64)
+	if err != nil {
+		return recognize.Response{}, err
+	}
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(raw)); err == nil {
+		r.size = image.Pt(cfg.Width, cfg.Height)
+	}
+	return recognize.Response{Pages: []recognize.Page{{
+		Width:  int64(r.size.X),
+		Height: int64(r.size.Y),
+		Blocks: []recognize.Block{{
+			BoundingBox: box(5, 10, 60, 40),
+			Lines: []recognize.Line{{
+				BoundingBox: box(10, 20, 50, 30),
+				Words:       []recognize.Word{{Text: "word", BoundingBox: box(10, 20, 25, 30)}},
+			}},
+		}},
+	}}}, nil
+}
+
+func pngRequest(t *testing.T, w, h int, noisy bool) recognize.Request {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	rng := rand.New(rand.NewSource(1))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+		if noisy {
+			img.Pix[i] = uint8(rng.Intn(256))
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return recognize.Request{ContentBase64: base64.StdEncoding.EncodeToString(buf.Bytes()), MimeType: "image/png"}
+}
+
+func TestScalesResultBack(t *testing.T) {
+	next := &boxRecognizer{}
+	req := pngRequest(t, 400, 200, false)
+	res, err := Wrap(next, Limits{MaxSide: 100}).Recognize(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.size != image.Pt(100, 50) || next.mimeType != "image/png" {
+		t.Fatalf("recognizer got a %v %s, want a 100x50 png", next.size, next.mimeType)
+	}
+
+	p := res.Pages[0]
+	if p.Width != 400 || p.Height != 200 {
+		t.Fatalf("page is %dx%d, want the original 400x200", p.Width, p.Height)
+	}
+	for name, tc := range map[string]struct{ got, want recognize.Polygon }{
+		"block": {p.Blocks[0].BoundingBox, box(20, 40, 240, 160)},
+		"line":  {p.Blocks[0].Lines[0].BoundingBox, box(40, 80, 200, 120)},
+		"word":  {p.Blocks[0].Lines[0].Words[0].BoundingBox, box(40, 80, 100, 120)},
+	} {
+		if !reflect.DeepEqual(tc.got, tc.want) {
+			t.Errorf("%s box = %v, want %v", name, tc.got.Vertices, tc.want.Vertices)
+		}
+	}
+}
+
+func TestScalesAxesSeparately(t *testing.T) {
+	next := &boxRecognizer{}
+	// 300x149 shrinks to 100x49: the axes are scaled by 3 and 149/49.
+	res, err := Wrap(next, Limits{MaxSide: 100}).Recognize(context.Background(), pngRequest(t, 300, 149, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.size != image.Pt(100, 49) {
+		t.Fatalf("recognizer got %v", next.size)
+	}
+	want := box(30, 61, 150, 91) // 20*149/49 = 60.8, 30*149/49 = 91.2
+	if got := res.Pages[0].Blocks[0].Lines[0].BoundingBox; !reflect.DeepEqual(got, want) {
+		t.Fatalf("line box = %v, want %v", got.Vertices, want.Vertices)
+	}
+}
+
+func TestPassesFittingImage(t *testing.T) {
+	next := &boxRecognizer{}
+	req := pngRequest(t, 80, 40, false)
+	res, err := Wrap(next, Limits{MaxSide: 100, MaxPixels: 4000}).Recognize(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.content != req.ContentBase64 || res.Pages[0].Blocks[0].BoundingBox.Vertices[0] != (recognize.Point{X: 5, Y: 10}) {
+		t.Fatal("an image within the limits was changed")
+	}
+
+	other := recognize.Request{ContentBase64: base64.StdEncoding.EncodeToString([]byte("%PDF-1.7")), MimeType: "application/pdf"}
+	if _, err := Wrap(next, Limits{MaxBytes: 1}).Recognize(context.Background(), other); err != nil || next.content != other.ContentBase64 {
+		t.Fatalf("a pdf was not passed through: %v", err)
+	}
+}
+
+func TestTooLarge(t *testing.T) {
+	next := &boxRecognizer{}
+	// Noise does not compress: no size or quality gets it under 64 bytes.
+	_, err := Wrap(next, Limits{MaxBytes: 64}).Recognize(context.Background(), pngRequest(t, 200, 200, true))
+	if !errors.Is(err, recognize.ErrTooLarge) {
+		t.Fatalf("Recognize() error = %v, want ErrTooLarge", err)
+	}
+	if next.calls != 0 {
+		t.Fatal("the recognizer was called with an image that does not fit")
+	}
+}
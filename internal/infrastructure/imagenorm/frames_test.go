@@ -0,0 +1,196 @@
+package imagenorm
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"doc2text/internal/core/abstraction/normalize"
+	"doc2text/internal/core/abstraction/recognize"
+
+	"golang.org/x/image/bmp"
+)
+
+var (
+	red   = color.RGBA{255, 0, 0, 255}
+	blue  = color.RGBA{0, 0, 255, 255}
+	green = color.RGBA{0, 255, 0, 255}
+	white = color.RGBA{255, 255, 255, 255}
+)
+
+var palette = color.Palette{color.Transparent, red, blue, green}
+
+func filled(r image.Rectangle, c color.Color) *image.Paletted {
+	img := image.NewPaletted(r, palette)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func normalizeImages(t *testing.T, n normalize.Normalizer, content []byte, mimeType string) []image.Image {
+	t.Helper()
+	res, err := n.Normalize(context.Background(), normalize.Request{Content: content, MimeType: mimeType})
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	imgs := make([]image.Image, len(res.Images))
+	for i, im := range res.Images {
+		if imgs[i], err = png.Decode(bytes.NewReader(im.Content)); err != nil {
+			t.Fatalf("image %d: %v", i, err)
+		}
+	}
+	return imgs
+}
+
+func rgba(c color.Color) color.RGBA {
+	r, g, b, a := c.RGBA()
+	return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+}
+
+func TestGIFFrames(t *testing.T) {
+	screen := image.Rect(0, 0, 4, 4)
+	topLeft := image.Rect(0, 0, 2, 2)
+	g := &gif.GIF{
+		Image: []*image.Paletted{
+			filled(screen, red),
+			filled(screen, red), // repeats the first frame
+			filled(topLeft, blue),
+			filled(image.Rect(2, 2, 4, 4), green),
+			filled(topLeft, green),
+			filled(image.Rect(3, 0, 4, 1), blue),
+		},
+		Delay: make([]int, 6),
+		Disposal: []byte{
+			gif.DisposalNone,
+			gif.DisposalNone,
+			gif.DisposalBackground, // clears the blue square
+			gif.DisposalNone,
+			gif.DisposalPrevious, // undoes the green square
+			gif.DisposalNone,
+		},
+		Config: image.Config{ColorModel: palette, Width: 4, Height: 4},
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+
+	frames := normalizeImages(t, New(Options{MaxPages: 10}), buf.Bytes(), MimeGIF)
+	if len(frames) != 5 {
+		t.Fatalf("got %d frames, want 5 without the repeated one", len(frames))
+	}
+	for _, tc := range []struct {
+		frame int
+		x, y  int
+		want  color.RGBA
+	}{
+		{0, 0, 0, red},
+		{1, 0, 0, blue},
+		{1, 3, 3, red},
+		{2, 0, 0, white}, // cleared to transparent, flattened onto white
+		{2, 3, 3, green},
+		{3, 0, 0, green},
+		{4, 0, 0, white}, // restored to before the green square
+		{4, 3, 0, blue},
+		{4, 3, 3, green},
+	} {
+		if got := rgba(frames[tc.frame].At(tc.x, tc.y)); got != tc.want {
+			t.Errorf("frame %d at (%d,%d) = %v, want %v", tc.frame, tc.x, tc.y, got, tc.want)
+		}
+	}
+
+	if frames := normalizeImages(t, New(Options{MaxPages: 2}), buf.Bytes(), MimeGIF); len(frames) != 2 {
+		t.Fatalf("got %d frames, want MaxPages 2", len(frames))
+	}
+}
+
+// grayTIFF writes an uncompressed little-endian TIFF with one 2x2 page per
+// gray level.
+func grayTIFF(levels ...byte) []byte {
+	const w, h = 2, 2
+	le := binary.LittleEndian
+	buf := []byte("II*\x00\x00\x00\x00\x00")
+	next := 4 // where the offset of the next IFD goes
+	for _, level := range levels {
+		data := len(buf)
+		buf = append(buf, bytes.Repeat([]byte{level}, w*h)...)
+		ifd := len(buf)
+		le.PutUint32(buf[next:], uint32(ifd))
+		entries := [][2]uint32{
+			{256, w}, {257, h}, {258, 8}, {259, 1}, {262, 1},
+			{273, uint32(data)}, {277, 1}, {278, h}, {279, w * h},
+		}
+		buf = le.AppendUint16(buf, uint16(len(entries)))
+		for _, e := range entries {
+			typ := uint16(3) // SHORT
+			if e[0] == 273 || e[0] == 279 {
+				typ = 4 // LONG
+			}
+			buf = le.AppendUint16(buf, uint16(e[0]))
+			buf = le.AppendUint16(buf, typ)
+			buf = le.AppendUint32(buf, 1)
+			buf = le.AppendUint32(buf, e[1])
+		}
+		next = len(buf)
+		buf = le.AppendUint32(buf, 0)
+	}
+	return buf
+}
+
+func TestTIFFPages(t *testing.T) {
+	content := grayTIFF(10, 120, 240)
+
+	pages := normalizeImages(t, New(Options{MaxPages: 10}), content, MimeTIFF)
+	if len(pages) != 3 {
+		t.Fatalf("got %d pages, want 3", len(pages))
+	}
+	for i, want := range []uint8{10, 120, 240} {
+		if got := rgba(pages[i].At(1, 1)); got.R != want || got.G != want {
+			t.Errorf("page %d = %v, want gray %d", i, got, want)
+		}
+	}
+
+	if pages := normalizeImages(t, New(Options{MaxPages: 2}), content, MimeTIFF); len(pages) != 2 {
+		t.Fatalf("got %d pages, want MaxPages 2", len(pages))
+	}
+}
+
+func TestTooManyPixels(t *testing.T) {
+	var gifBuf bytes.Buffer
+	gif.Encode(&gifBuf, filled(image.Rect(0, 0, 2, 2), red), nil)
+	hugeGIF := gifBuf.Bytes()
+	// The logical screen is declared in the header: 65535x65535.
+	binary.LittleEndian.PutUint16(hugeGIF[6:], 65535)
+	binary.LittleEndian.PutUint16(hugeGIF[8:], 65535)
+
+	var bmpBuf bytes.Buffer
+	bmp.Encode(&bmpBuf, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+	hugeBMP := bmpBuf.Bytes()
+	binary.LittleEndian.PutUint32(hugeBMP[18:], 100000)
+	binary.LittleEndian.PutUint32(hugeBMP[22:], 100000)
+
+	for name, tc := range map[string]struct {
+		content   []byte
+		mimeType  string
+		maxPixels int64
+	}{
+		"gif":  {hugeGIF, MimeGIF, 1000},
+		"bmp":  {hugeBMP, MimeBMP, 1000},
+		"tiff": {grayTIFF(1), MimeTIFF, 3},
+	} {
+		n := New(Options{MaxPages: 10, MaxPixels: tc.maxPixels})
+		_, err := n.Normalize(context.Background(), normalize.Request{Content: tc.content, MimeType: tc.mimeType})
+		if !errors.Is(err, recognize.ErrTooLarge) {
+			t.Errorf("%s: Normalize() error = %v, want too large", name, err)
+		}
+	}
+}
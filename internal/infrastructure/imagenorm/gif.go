@@ -0,0 +1,66 @@
+package imagenorm
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/gif"
+)
+
+// gifFrames composes the frames of a GIF onto its logical screen, honouring
+// disposal, and drops frames that repeat the previous one.
+func gifFrames(content []byte, maxPages int, maxPixels int64) ([]image.Image, error) {
+	// Frames must lie within the logical screen, so checking it bounds them
+	// all.
+	cfg, err := gif.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPixels(cfg, maxPixels); err != nil {
+		return nil, err
+	}
+	g, err := gif.DecodeAll(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() && len(g.Image) > 0 {
+		bounds = g.Image[0].Bounds()
+	}
+
+	canvas := image.NewRGBA(bounds)
+	var (
+		frames []image.Image
+		last   []byte
+	)
+	for i, frame := range g.Image {
+		var saved *image.RGBA
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			saved = image.NewRGBA(bounds)
+			copy(saved.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		if !bytes.Equal(canvas.Pix, last) {
+			snapshot := image.NewRGBA(bounds)
+			copy(snapshot.Pix, canvas.Pix)
+			frames = append(frames, snapshot)
+			last = snapshot.Pix
+			if len(frames) >= maxPages {
+				break
+			}
+		}
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			copy(canvas.Pix, saved.Pix)
+		}
+	}
+	return frames, nil
+}
@@ -0,0 +1,162 @@
+package imagenorm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"os/exec"
+	"strings"
+
+	"doc2text/internal/core/abstraction/normalize"
+	"doc2text/internal/core/abstraction/recognize"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/webp"
+)
+
+const (
+	MimeHEIC = "image/heic"
+	MimeHEIF = "image/heif"
+	MimeWEBP = "image/webp"
+	MimeTIFF = "image/tiff"
+	MimeBMP  = "image/bmp"
+	MimeXBMP = "image/x-ms-bmp"
+	MimeGIF  = "image/gif"
+)
+
+// MimeTypes lists the formats the normalizer converts.
+var MimeTypes = []string{MimeHEIC, MimeHEIF, MimeWEBP, MimeTIFF, MimeBMP, MimeXBMP, MimeGIF}
+
+type Options struct {
+	// MaxPages caps how many pages of a multi-page TIFF or GIF are kept.
+	MaxPages int
+	// HEICConverter is an ImageMagick-compatible executable that reads
+	// HEIC on stdin and writes JPEG to stdout; there is no pure Go decoder.
+	// Empty disables HEIC.
+	HEICConverter string
+	// MaxPixels rejects images larger than this before they are decoded;
+	// zero allows any size.
+	MaxPixels int64
+}
+
+type normalizer struct {
+	maxPages      int
+	maxPixels     int64
+	heicConverter string
+}
+
+func New(o Options) normalize.Normalizer {
+	if o.MaxPages <= 0 {
+		o.MaxPages = 1
+	}
+	return &normalizer{maxPages: o.MaxPages, maxPixels: o.MaxPixels, heicConverter: o.HEICConverter}
+}
+
+func (n *normalizer) Normalize(ctx context.Context, req normalize.Request) (normalize.Response, error) {
+	var (
+		frames []image.Image
+		err    error
+	)
+	switch strings.ToLower(strings.TrimSpace(strings.SplitN(req.MimeType, ";", 2)[0])) {
+	case MimeHEIC, MimeHEIF:
+		return n.heic(ctx, req.Content)
+	case MimeWEBP:
+		var cfg image.Config
+		if cfg, err = webp.DecodeConfig(bytes.NewReader(req.Content)); err == nil {
+			err = checkPixels(cfg, n.maxPixels)
+		}
+		if err == nil {
+			frames, err = single(webp.Decode(bytes.NewReader(req.Content)))
+		}
+	case MimeBMP, MimeXBMP:
+		var cfg image.Config
+		if cfg, err = bmp.DecodeConfig(bytes.NewReader(req.Content)); err == nil {
+			err = checkPixels(cfg, n.maxPixels)
+		}
+		if err == nil {
+			frames, err = single(bmp.Decode(bytes.NewReader(req.Content)))
+		}
+	case MimeTIFF:
+		frames, err = tiffPages(req.Content, n.maxPages, n.maxPixels)
+	case MimeGIF:
+		frames, err = gifFrames(req.Content, n.maxPages, n.maxPixels)
+	default:
+		return normalize.Response{}, fmt.Errorf("imagenorm: %w: %q", recognize.ErrUnsupportedType, req.MimeType)
+	}
+	if err != nil {
+		return normalize.Response{}, fmt.Errorf("imagenorm: decode %s: %w", req.MimeType, err)
+	}
+
+	res := normalize.Response{Images: make([]normalize.Image, 0, len(frames))}
+	for _, f := range frames {
+		if err := ctx.Err(); err != nil {
+			return normalize.Response{}, err
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, flatten(f)); err != nil {
+			return normalize.Response{}, fmt.Errorf("imagenorm: encode png: %w", err)
+		}
+		res.Images = append(res.Images, normalize.Image{Content: buf.Bytes(), MimeType: "image/png"})
+	}
+	return res, nil
+}
+
+func (n *normalizer) heic(ctx context.Context, content []byte) (normalize.Response, error) {
+	if n.heicConverter == "" {
+		return normalize.Response{}, fmt.Errorf("imagenorm: %w: HEIC converter is not configured", recognize.ErrUnsupportedType)
+	}
+	// HEIC keeps orientation in its own metadata, so it is applied here.
+	cmd := exec.CommandContext(ctx, n.heicConverter, "heic:-", "-auto-orient", "jpeg:-")
+	cmd.Stdin = bytes.NewReader(content)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return normalize.Response{}, ctxErr
+		}
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+			return normalize.Response{}, fmt.Errorf("imagenorm: %w: %s", normalize.ErrConverterMissing, n.heicConverter)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return normalize.Response{}, fmt.Errorf("imagenorm: %s: %w", n.heicConverter, err)
+	}
+	return normalize.Response{Images: []normalize.Image{{Content: stdout.Bytes(), MimeType: "image/jpeg"}}}, nil
+}
+
+// checkPixels rejects images over maxPixels by their header, so that a
+// small file declaring a huge image fails before anything is allocated.
+func checkPixels(cfg image.Config, maxPixels int64) error {
+	if px := int64(cfg.Width) * int64(cfg.Height); maxPixels > 0 && px > maxPixels {
+		return fmt.Errorf("%w: %dx%d image exceeds %d pixels", recognize.ErrTooLarge, cfg.Width, cfg.Height, maxPixels)
+	}
+	return nil
+}
+
+func single(img image.Image, err error) ([]image.Image, error) {
+	if err != nil {
+		return nil, err
+	}
+	return []image.Image{img}, nil
+}
+
+// flatten composes transparent images (stickers, mostly) onto white, which
+// recognizers would otherwise read as black.
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
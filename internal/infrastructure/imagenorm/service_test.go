@@ -0,0 +1,26 @@
+package imagenorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"doc2text/internal/core/abstraction/normalize"
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+func TestHEICWithoutConverter(t *testing.T) {
+	_, err := New(Options{MaxPages: 1}).Normalize(context.Background(), normalize.Request{Content: []byte("heic"), MimeType: MimeHEIC})
+	if !errors.Is(err, recognize.ErrUnsupportedType) {
+		t.Fatalf("Normalize() error = %v, want unsupported type", err)
+	}
+}
+
+func TestHEICConverterMissing(t *testing.T) {
+	for _, conv := range []string{"doc2text-no-such-converter", "/nonexistent/magick"} {
+		_, err := New(Options{MaxPages: 1, HEICConverter: conv}).Normalize(context.Background(), normalize.Request{Content: []byte("heic"), MimeType: MimeHEIC})
+		if !errors.Is(err, normalize.ErrConverterMissing) {
+			t.Fatalf("Normalize() with %q error = %v, want converter missing", conv, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package imagenorm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+
+	"golang.org/x/image/tiff"
+)
+
+// tiffPages decodes every page of a TIFF. x/image/tiff only reads the IFD
+// the header points at, so each page is decoded from a copy whose header
+// points at that page's IFD; offsets in TIFF are absolute, so nothing else
+// has to move.
+func tiffPages(content []byte, maxPages int, maxPixels int64) ([]image.Image, error) {
+	offsets, err := tiffIFDs(content, maxPages)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		pages    []image.Image
+		firstErr error
+		order    = tiffByteOrder(content)
+		buf      = make([]byte, len(content))
+	)
+	for _, off := range offsets {
+		copy(buf, content)
+		order.PutUint32(buf[4:8], off)
+		var img image.Image
+		cfg, err := tiff.DecodeConfig(bytes.NewReader(buf))
+		if err == nil {
+			err = checkPixels(cfg, maxPixels)
+		}
+		if err == nil {
+			img, err = tiff.Decode(bytes.NewReader(buf))
+		}
+		if err != nil {
+			// Thumbnails and other sub-images may use what the decoder does
+			// not support; a page we cannot read, or too large to, is
+			// skipped, not fatal.
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		pages = append(pages, img)
+	}
+	if len(pages) == 0 {
+		return nil, firstErr
+	}
+	return pages, nil
+}
+
+func tiffByteOrder(content []byte) binary.ByteOrder {
+	if len(content) >= 2 && content[0] == 'M' && content[1] == 'M' {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// tiffIFDs walks the IFD chain and returns up to maxPages offsets.
+func tiffIFDs(content []byte, maxPages int) ([]uint32, error) {
+	if len(content) < 8 || !(string(content[:4]) == "II*\x00" || string(content[:4]) == "MM\x00*") {
+		return nil, errors.New("not a TIFF file")
+	}
+	order := tiffByteOrder(content)
+	var offsets []uint32
+	seen := map[uint32]bool{}
+	for off := order.Uint32(content[4:8]); off != 0 && len(offsets) < maxPages; {
+		if seen[off] || int(off)+2 > len(content) {
+			break
+		}
+		seen[off] = true
+		offsets = append(offsets, off)
+		n := int(order.Uint16(content[off : off+2]))
+		next := int(off) + 2 + n*12
+		if next+4 > len(content) {
+			break
+		}
+		off = order.Uint32(content[next : next+4])
+	}
+	if len(offsets) == 0 {
+		return nil, errors.New("TIFF has no pages")
+	}
+	return offsets, nil
+}
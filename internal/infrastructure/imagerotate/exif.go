@@ -0,0 +1,61 @@
+package imagerotate
+
+import "encoding/binary"
+
+// exifOrientation returns the EXIF Orientation tag (1-8) of a JPEG, or 1
+// when there is none.
+func exifOrientation(jpeg []byte) int {
+	if len(jpeg) < 4 || jpeg[0] != 0xFF || jpeg[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(jpeg); {
+		if jpeg[i] != 0xFF {
+			return 1
+		}
+		marker := jpeg[i+1]
+		if marker == 0xDA || marker == 0xD9 { // start of scan, end of image
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(jpeg[i+2 : i+4]))
+		end := i + 2 + size
+		if size < 2 || end > len(jpeg) {
+			return 1
+		}
+		seg := jpeg[i+4 : end]
+		if marker == 0xE1 && len(seg) > 14 && string(seg[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(seg[6:])
+		}
+		i = end
+	}
+	return 1
+}
+
+func tiffOrientation(t []byte) int {
+	var order binary.ByteOrder
+	switch string(t[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	off := int(order.Uint32(t[4:8]))
+	if off+2 > len(t) {
+		return 1
+	}
+	n := int(order.Uint16(t[off : off+2]))
+	for k := 0; k < n; k++ {
+		e := off + 2 + k*12
+		if e+12 > len(t) {
+			return 1
+		}
+		if order.Uint16(t[e:e+2]) == 0x0112 {
+			if v := int(order.Uint16(t[e+8 : e+10])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
@@ -0,0 +1,175 @@
+package imagerotate
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"unicode"
+
+	"doc2text/internal/core/abstraction/logger"
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+type Options struct {
+	// AutoRotate retries at 90, 180 and 270 degrees when the first pass
+	// scores below MinConfidence, keeping the best result.
+	AutoRotate    bool
+	MinConfidence float64
+	// Deskew straightens scans tilted by up to MaxSkew degrees.
+	Deskew  bool
+	MaxSkew float64
+	Logger  logger.Logger
+}
+
+type rotateRecognizer struct {
+	next recognize.Recognizer
+	o    Options
+}
+
+// Wrap returns a recognizer that turns JPEG and PNG images upright before
+// calling next. Images that need no change are passed through untouched.
+func Wrap(next recognize.Recognizer, o Options) recognize.Recognizer {
+	return &rotateRecognizer{next: next, o: o}
+}
+
+func (r *rotateRecognizer) Recognize(ctx context.Context, req recognize.Request) (recognize.Response, error) {
+	mimeType := strings.ToLower(strings.TrimSpace(strings.SplitN(req.MimeType, ";", 2)[0]))
+	if mimeType != "image/jpeg" && mimeType != "image/png" {
+		return r.next.Recognize(ctx, req)
+	}
+	content, err := base64.StdEncoding.DecodeString(req.ContentBase64)
+	if err != nil {
+		return recognize.Response{}, fmt.Errorf("imagerotate: decode base64: %w", err)
+	}
+
+	orientation := 1
+	if mimeType == "image/jpeg" {
+		orientation = exifOrientation(content)
+	}
+	var (
+		img      image.Image
+		rotation int
+		skew     float64
+	)
+	if orientation != 1 || r.o.Deskew {
+		if img, _, err = image.Decode(bytes.NewReader(content)); err != nil {
+			// Let the recognizer report what is wrong with the file.
+			return r.next.Recognize(ctx, req)
+		}
+		img, rotation = orient(img, orientation)
+		if r.o.Deskew {
+			if skew = detectSkew(img, r.o.MaxSkew); skew != 0 {
+				img = rotateAngle(img, -skew)
+			}
+		}
+		if orientation != 1 || skew != 0 {
+			if req, err = withImage(req, img, mimeType); err != nil {
+				return recognize.Response{}, err
+			}
+		}
+	}
+
+	res, err := r.next.Recognize(ctx, req)
+	if err != nil {
+		return recognize.Response{}, err
+	}
+	if r.o.AutoRotate && score(res) < r.o.MinConfidence {
+		if img == nil {
+			if img, _, err = image.Decode(bytes.NewReader(content)); err != nil {
+				return mark(res, rotation, skew), nil
+			}
+		}
+		best, bestTurn := res, 0
+		for _, turn := range []int{90, 180, 270} {
+			turned, err := withImage(req, rotate(img, turn), mimeType)
+			if err != nil {
+				return recognize.Response{}, err
+			}
+			alt, err := r.next.Recognize(ctx, turned)
+			if err != nil {
+				if ctx.Err() != nil {
+					return recognize.Response{}, ctx.Err()
+				}
+				if r.o.Logger != nil {
+					r.o.Logger.Error("imagerotate: retry at %d°: %v", turn, err)
+				}
+				continue
+			}
+			if score(alt) > score(best) {
+				best, bestTurn = alt, turn
+			}
+		}
+		res = best
+		rotation = (rotation + bestTurn) % 360
+	}
+	return mark(res, rotation, skew), nil
+}
+
+func withImage(req recognize.Request, img image.Image, mimeType string) (recognize.Request, error) {
+	var buf bytes.Buffer
+	var err error
+	if mimeType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 92})
+	}
+	if err != nil {
+		return req, fmt.Errorf("imagerotate: encode %s: %w", mimeType, err)
+	}
+	req.ContentBase64 = base64.StdEncoding.EncodeToString(buf.Bytes())
+	req.MimeType = mimeType
+	return req, nil
+}
+
+func mark(res recognize.Response, rotation int, skew float64) recognize.Response {
+	for i := range res.Pages {
+		res.Pages[i].Rotation = rotation
+		res.Pages[i].Skew = skew
+	}
+	return res
+}
+
+// score is the mean word confidence. Backends that report no confidence
+// score by the share of words that look like words: a sideways page comes
+// back as short fragments of punctuation and stray letters.
+func score(res recognize.Response) float64 {
+	var (
+		words, wordy int
+		conf         float64
+	)
+	for _, p := range res.Pages {
+		for _, b := range p.Blocks {
+			for _, l := range b.Lines {
+				for _, w := range l.Words {
+					words++
+					conf += w.Confidence
+					if letters(w.Text) >= 3 {
+						wordy++
+					}
+				}
+			}
+		}
+	}
+	if words == 0 {
+		return 0
+	}
+	if conf > 0 {
+		return conf / float64(words)
+	}
+	return float64(wordy) / float64(words)
+}
+
+func letters(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			n++
+		}
+	}
+	return n
+}
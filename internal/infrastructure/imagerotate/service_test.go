@@ -0,0 +1,274 @@
+package imagerotate
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+// exifSegment builds an APP1 segment whose IFD holds an unrelated tag and
+// then Orientation.
+func exifSegment(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+2*12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 2)
+	entry := func(at int, tag, typ uint16, value uint16) {
+		order.PutUint16(tiff[at:], tag)
+		order.PutUint16(tiff[at+2:], typ)
+		order.PutUint32(tiff[at+4:], 1)
+		order.PutUint16(tiff[at+8:], value)
+	}
+	entry(10, 0x010F, 2, 0) // Make
+	entry(22, 0x0112, 3, orientation)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	seg := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// withSegments inserts segments right after the SOI marker of a JPEG.
+func withSegments(jpg []byte, segs ...[]byte) []byte {
+	out := append([]byte{}, jpg[:2]...)
+	for _, s := range segs {
+		out = append(out, s...)
+	}
+	return append(out, jpg[2:]...)
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExifOrientation(t *testing.T) {
+	base := encodeJPEG(t, image.NewGray(image.Rect(0, 0, 8, 8)))
+	app0 := []byte{0xFF, 0xE0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 1, 0, 0, 1, 0, 1, 0, 0}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for o := uint16(1); o <= 8; o++ {
+			if got := exifOrientation(withSegments(base, app0, exifSegment(order, o))); got != int(o) {
+				t.Errorf("%v orientation %d: got %d", order, o, got)
+			}
+		}
+	}
+
+	truncated := exifSegment(binary.BigEndian, 6)
+	binary.BigEndian.PutUint16(truncated[2:], 0xFFF0)
+	for name, jpg := range map[string][]byte{
+		"no exif":         base,
+		"out of range":    withSegments(base, exifSegment(binary.LittleEndian, 9)),
+		"segment too big": withSegments(base, truncated),
+		"not a jpeg":      append([]byte{0x89, 'P', 'N', 'G'}, exifSegment(binary.LittleEndian, 6)...),
+		"empty":           nil,
+	} {
+		if got := exifOrientation(jpg); got != 1 {
+			t.Errorf("%s: got %d, want 1", name, got)
+		}
+	}
+	// An IFD pointing past the segment is ignored.
+	bad := exifSegment(binary.LittleEndian, 6)
+	binary.LittleEndian.PutUint32(bad[4+6+4:], 1000)
+	if got := tiffOrientation(bad[4+6:]); got != 1 {
+		t.Errorf("bad IFD offset: got %d", got)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 3x2 image marked at its stored top-left corner.
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	src.Set(0, 0, color.Black)
+
+	for _, tc := range []struct {
+		orientation int
+		size        image.Point
+		mark        image.Point
+		rotation    int
+	}{
+		{1, image.Pt(3, 2), image.Pt(0, 0), 0},
+		{2, image.Pt(3, 2), image.Pt(2, 0), 0},
+		{3, image.Pt(3, 2), image.Pt(2, 1), 180},
+		{4, image.Pt(3, 2), image.Pt(0, 1), 180},
+		{5, image.Pt(2, 3), image.Pt(0, 0), 90},
+		{6, image.Pt(2, 3), image.Pt(1, 0), 90},
+		{7, image.Pt(2, 3), image.Pt(1, 2), 270},
+		{8, image.Pt(2, 3), image.Pt(0, 2), 270},
+	} {
+		img, rotation := orient(src, tc.orientation)
+		if size := img.Bounds().Size(); size != tc.size || rotation != tc.rotation {
+			t.Errorf("orientation %d: %v turned %d, want %v turned %d", tc.orientation, size, rotation, tc.size, tc.rotation)
+			continue
+		}
+		if r, _, _, _ := img.At(tc.mark.X, tc.mark.Y).RGBA(); r != 0 {
+			t.Errorf("orientation %d: mark is not at %v", tc.orientation, tc.mark)
+		}
+	}
+}
+
+// cornerRecognizer is confident only when the image it gets has a dark
+// top-left corner, as if that were the start of the text.
+type cornerRecognizer struct {
+	calls int
+	sizes []image.Point
+	fail  map[int]bool // calls that fail, counted from 1
+}
+
+func (r *cornerRecognizer) Recognize(_ context.Context, req recognize.Request) (recognize.Response, error) {
+	r.calls++
+	if r.fail[r.calls] {
+		return recognize.Response{}, errors.New("backend hiccup")
+	}
+	raw, _ := base64.StdEncoding.DecodeString(req.ContentBase64)
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return recognize.Response{}, err
+	}
+	r.sizes = append(r.sizes, img.Bounds().Size())
+	conf := 0.1
+	if l, _, _, _ := img.At(img.Bounds().Min.X+2, img.Bounds().Min.Y+2).RGBA(); l < 0x8000 {
+		conf = 0.9
+	}
+	return recognize.Response{Pages: []recognize.Page{{Blocks: []recognize.Block{{Lines: []recognize.Line{{
+		Words: []recognize.Word{{Text: "word", Confidence: conf}},
+	}}}}}}}, nil
+}
+
+// cornerImage is a white 64x32 image with a dark block at corner.
+func cornerImage(corner image.Point) image.Image {
+	img := image.NewGray(image.Rect(0, 0, 64, 32))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	block := image.Rect(0, 0, 12, 8).Add(image.Pt(corner.X*(64-12), corner.Y*(32-8)))
+	draw.Draw(img, block, image.NewUniform(color.Black), image.Point{}, draw.Src)
+	return img
+}
+
+func pngRequest(t *testing.T, img image.Image) recognize.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return recognize.Request{ContentBase64: base64.StdEncoding.EncodeToString(buf.Bytes()), MimeType: "image/png"}
+}
+
+func confidence(res recognize.Response) float64 {
+	return res.Pages[0].Blocks[0].Lines[0].Words[0].Confidence
+}
+
+func TestAutoRotatePicksBest(t *testing.T) {
+	// Which corner the block must sit in for a clockwise turn to bring it
+	// to the top left.
+	for turn, corner := range map[int]image.Point{90: {0, 1}, 180: {1, 1}, 270: {1, 0}} {
+		next := &cornerRecognizer{}
+		res, err := Wrap(next, Options{AutoRotate: true, MinConfidence: 0.5}).Recognize(context.Background(), pngRequest(t, cornerImage(corner)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Pages[0].Rotation != turn || confidence(res) != 0.9 || next.calls != 4 {
+			t.Errorf("block at %v: rotation %d, confidence %v after %d calls; want %d, 0.9, 4",
+				corner, res.Pages[0].Rotation, confidence(res), next.calls, turn)
+		}
+	}
+}
+
+func TestAutoRotateKeepsConfidentPass(t *testing.T) {
+	next := &cornerRecognizer{}
+	req := pngRequest(t, cornerImage(image.Pt(0, 0)))
+	res, err := Wrap(next, Options{AutoRotate: true, MinConfidence: 0.5}).Recognize(context.Background(), req)
+	if err != nil || next.calls != 1 || res.Pages[0].Rotation != 0 {
+		t.Fatalf("Recognize() = rotation %d after %d calls, %v", res.Pages[0].Rotation, next.calls, err)
+	}
+}
+
+func TestAutoRotateSkipsFailedTurn(t *testing.T) {
+	// The 90° retry, the second call, fails; 180° still wins.
+	next := &cornerRecognizer{fail: map[int]bool{2: true}}
+	res, err := Wrap(next, Options{AutoRotate: true, MinConfidence: 0.5}).Recognize(context.Background(), pngRequest(t, cornerImage(image.Pt(1, 1))))
+	if err != nil || res.Pages[0].Rotation != 180 || next.calls != 4 {
+		t.Fatalf("Recognize() = rotation %d after %d calls, %v", res.Pages[0].Rotation, next.calls, err)
+	}
+}
+
+func TestExifRotatesBeforeRecognizing(t *testing.T) {
+	// Stored with the block bottom left, Orientation 6 turns it upright.
+	jpg := withSegments(encodeJPEG(t, cornerImage(image.Pt(0, 1))), exifSegment(binary.BigEndian, 6))
+	next := &cornerRecognizer{}
+	req := recognize.Request{ContentBase64: base64.StdEncoding.EncodeToString(jpg), MimeType: "image/jpeg"}
+	res, err := Wrap(next, Options{AutoRotate: true, MinConfidence: 0.5}).Recognize(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.calls != 1 || next.sizes[0] != image.Pt(32, 64) || res.Pages[0].Rotation != 90 || confidence(res) != 0.9 {
+		t.Fatalf("recognizer got %v in %d calls; rotation %d", next.sizes, next.calls, res.Pages[0].Rotation)
+	}
+}
+
+// textLines draws a page of dashed lines, like words on a scan, turned
+// clockwise by deg.
+func textLines(deg float64) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for y := 60; y < 540; y += 24 {
+		for x := 60; x < 740; x += 40 {
+			draw.Draw(img, image.Rect(x, y, x+30, y+5), image.NewUniform(color.Black), image.Point{}, draw.Src)
+		}
+	}
+	if deg == 0 {
+		return img
+	}
+	return rotateAngle(img, deg)
+}
+
+func TestDetectSkew(t *testing.T) {
+	for _, deg := range []float64{0, 2, -3.5, 6} {
+		if got := detectSkew(textLines(deg), 10); math.Abs(got-deg) > 0.25 {
+			t.Errorf("lines turned %v°: detected %v°", deg, got)
+		}
+	}
+	// Beyond the search range the best guess is the edge, never further.
+	if got := detectSkew(textLines(6), 3); math.Abs(got) > 3 {
+		t.Errorf("detected %v° with a 3° limit", got)
+	}
+
+	blank := image.NewGray(image.Rect(0, 0, 400, 300))
+	draw.Draw(blank, blank.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	dark := image.NewGray(image.Rect(0, 0, 400, 300))
+	draw.Draw(dark, image.Rect(0, 0, 400, 150), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for name, img := range map[string]image.Image{"blank": blank, "half dark": dark, "tiny": image.NewGray(image.Rect(0, 0, 20, 20))} {
+		if got := detectSkew(img, 10); got != 0 {
+			t.Errorf("%s: detected %v°", name, got)
+		}
+	}
+}
+
+func TestDeskewMarksPage(t *testing.T) {
+	next := &cornerRecognizer{}
+	res, err := Wrap(next, Options{Deskew: true, MaxSkew: 10}).Recognize(context.Background(), pngRequest(t, textLines(4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skew := res.Pages[0].Skew; math.Abs(skew-4) > 0.25 {
+		t.Fatalf("page skew = %v, want about 4", skew)
+	}
+}
@@ -0,0 +1,126 @@
+package imagerotate
+
+import (
+	"image"
+	"math"
+)
+
+const (
+	// skewSampleWidth is the width the image is sampled at for detection.
+	skewSampleWidth = 800
+	// minSkew is the smallest angle worth resampling the image for.
+	minSkew = 0.3
+)
+
+// detectSkew estimates how many degrees clockwise the text lines of a scan
+// are tilted, searching within ±maxAngle. It maximises the variance of the
+// row profile of dark pixels, which peaks when lines are horizontal, and
+// reports 0 for images that do not look like text on a light background.
+func detectSkew(img image.Image, maxAngle float64) float64 {
+	xs, ys := darkPoints(img)
+	if len(xs) == 0 {
+		return 0
+	}
+
+	score := func(deg float64) float64 {
+		rad := deg * math.Pi / 180
+		sin, cos := math.Sin(rad), math.Cos(rad)
+		bins := map[int]int{}
+		for i := range xs {
+			bins[int(math.Round(ys[i]*cos-xs[i]*sin))]++
+		}
+		var s float64
+		for _, n := range bins {
+			s += float64(n) * float64(n)
+		}
+		return s
+	}
+
+	best, bestScore := 0.0, score(0)
+	flat := bestScore
+	for a := -maxAngle; a <= maxAngle; a += 0.5 {
+		if s := score(a); s > bestScore {
+			best, bestScore = a, s
+		}
+	}
+	for a := max(best-0.4, -maxAngle); a <= min(best+0.4, maxAngle); a += 0.1 {
+		if s := score(a); s > bestScore {
+			best, bestScore = a, s
+		}
+	}
+	// A weak peak means no clear line structure; leave the image alone.
+	if math.Abs(best) < minSkew || bestScore < flat*1.05 {
+		return 0
+	}
+	return math.Round(best*10) / 10
+}
+
+// darkPoints samples the image down and returns the centred coordinates of
+// pixels darker than the Otsu threshold.
+func darkPoints(img image.Image) ([]float64, []float64) {
+	b := img.Bounds()
+	step := max(1, (b.Dx()+skewSampleWidth-1)/skewSampleWidth)
+	w, h := b.Dx()/step, b.Dy()/step
+	if w < 32 || h < 32 {
+		return nil, nil
+	}
+
+	lum := make([]uint8, w*h)
+	var hist [256]int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, bl, _ := img.At(b.Min.X+x*step, b.Min.Y+y*step).RGBA()
+			l := uint8((299*r + 587*g + 114*bl) / 1000 >> 8)
+			lum[y*w+x] = l
+			hist[l]++
+		}
+	}
+	t := otsu(hist, w*h)
+
+	var xs, ys []float64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if lum[y*w+x] < t {
+				xs = append(xs, float64(x)-float64(w)/2)
+				ys = append(ys, float64(y)-float64(h)/2)
+			}
+		}
+	}
+	// Text is a small share of a page; photos and dark backgrounds are not
+	// worth guessing about.
+	if frac := float64(len(xs)) / float64(w*h); frac < 0.002 || frac > 0.35 {
+		return nil, nil
+	}
+	return xs, ys
+}
+
+func otsu(hist [256]int, total int) uint8 {
+	var sum float64
+	for i, n := range hist {
+		sum += float64(i * n)
+	}
+	var (
+		sumB, wB float64
+		best     float64
+		t        uint8
+	)
+	for i, n := range hist {
+		wB += float64(n)
+		if wB == 0 {
+			continue
+		}
+		wF := float64(total) - wB
+		if wF == 0 {
+			break
+		}
+		sumB += float64(i * n)
+		mB, mF := sumB/wB, (sum-sumB)/wF
+		if v := wB * wF * (mB - mF) * (mB - mF); v > best {
+			best, t = v, uint8(i)
+		}
+	}
+	if t == 255 {
+		return t
+	}
+	return t + 1
+}
@@ -0,0 +1,107 @@
+package imagerotate
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// orient applies an EXIF orientation and returns the upright image with the
+// clockwise rotation it took, ignoring the mirroring some values add.
+func orient(img image.Image, orientation int) (image.Image, int) {
+	switch orientation {
+	case 2:
+		return flipH(img), 0
+	case 3:
+		return rotate(img, 180), 180
+	case 4:
+		return flipH(rotate(img, 180)), 180
+	case 5:
+		return flipH(rotate(img, 90)), 90
+	case 6:
+		return rotate(img, 90), 90
+	case 7:
+		return flipH(rotate(img, 270)), 270
+	case 8:
+		return rotate(img, 270), 270
+	}
+	return img, 0
+}
+
+// rotate turns the image clockwise by a multiple of 90 degrees.
+func rotate(img image.Image, deg int) image.Image {
+	src := toRGBA(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	var dst *image.RGBA
+	var at func(x, y int) (int, int)
+	switch deg % 360 {
+	case 90:
+		dst = image.NewRGBA(image.Rect(0, 0, h, w))
+		at = func(x, y int) (int, int) { return h - 1 - y, x }
+	case 180:
+		dst = image.NewRGBA(image.Rect(0, 0, w, h))
+		at = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case 270:
+		dst = image.NewRGBA(image.Rect(0, 0, h, w))
+		at = func(x, y int) (int, int) { return y, w - 1 - x }
+	default:
+		return img
+	}
+	remap(dst, src, at)
+	return dst
+}
+
+func flipH(img image.Image) image.Image {
+	src := toRGBA(img)
+	w := src.Rect.Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, w, src.Rect.Dy()))
+	remap(dst, src, func(x, y int) (int, int) { return w - 1 - x, y })
+	return dst
+}
+
+// remap copies every source pixel (x, y) to dst at at(x, y).
+func remap(dst, src *image.RGBA, at func(x, y int) (int, int)) {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride:]
+		for x := 0; x < w; x++ {
+			dx, dy := at(x, y)
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], row[x*4:x*4+4])
+		}
+	}
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// rotateAngle turns the image clockwise by deg degrees around its centre,
+// keeping its size and filling the uncovered corners with white.
+func rotateAngle(img image.Image, deg float64) image.Image {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	rad := deg * math.Pi / 180
+	sin, cos := math.Sin(rad), math.Cos(rad)
+	cx, cy := float64(b.Min.X)+float64(b.Dx())/2, float64(b.Min.Y)+float64(b.Dy())/2
+	dx, dy := float64(b.Dx())/2, float64(b.Dy())/2
+	// Maps source to destination: translate the centre to the origin,
+	// rotate, translate to the destination centre.
+	m := f64.Aff3{
+		cos, -sin, dx - cos*cx + sin*cy,
+		sin, cos, dy - sin*cx - cos*cy,
+	}
+	xdraw.BiLinear.Transform(dst, m, img, b, xdraw.Over, nil)
+	return dst
+}
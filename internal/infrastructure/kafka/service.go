@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"doc2text/internal/core/abstraction/queue"
+
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+type SubscriptionOptions struct {
+	Brokers []string
+	GroupID string
+	Topic   string
+}
+
+type subscription struct {
+	r *kafkago.Reader
+}
+
+// NewSubscription joins the consumer group on the topic. Offsets are
+// committed only by Ack, so unacked messages are read again after a
+// restart or rebalance.
+func NewSubscription(o SubscriptionOptions) queue.Subscription {
+	return &subscription{r: kafkago.NewReader(kafkago.ReaderConfig{
+		Brokers:        o.Brokers,
+		GroupID:        o.GroupID,
+		Topic:          o.Topic,
+		CommitInterval: 0,
+		StartOffset:    kafkago.FirstOffset,
+	})}
+}
+
+func (s *subscription) Receive(ctx context.Context) (queue.Delivery, error) {
+	m, err := s.r.FetchMessage(ctx)
+	if err != nil {
+		return queue.Delivery{}, fmt.Errorf("kafka: fetch: %w", err)
+	}
+	headers := make(map[string]string, len(m.Headers))
+	for _, h := range m.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	return queue.Delivery{
+		Message: queue.Message{Key: m.Key, Value: m.Value, Headers: headers},
+		Ack: func(ctx context.Context) error {
+			if err := s.r.CommitMessages(ctx, m); err != nil {
+				return fmt.Errorf("kafka: commit %s/%d@%d: %w", m.Topic, m.Partition, m.Offset, err)
+			}
+			return nil
+		},
+	}, nil
+}
+
+func (s *subscription) Close() error {
+	return s.r.Close()
+}
+
+type PublisherOptions struct {
+	Brokers []string
+}
+
+type publisher struct {
+	w *kafkago.Writer
+}
+
+// NewPublisher writes each message synchronously and waits for all in-sync
+// replicas. Messages with the same key go to the same partition.
+func NewPublisher(o PublisherOptions) queue.Publisher {
+	return &publisher{w: &kafkago.Writer{
+		Addr:         kafkago.TCP(o.Brokers...),
+		Balancer:     &kafkago.Hash{},
+		RequiredAcks: kafkago.RequireAll,
+		BatchSize:    1,
+		BatchTimeout: 10 * time.Millisecond,
+	}}
+}
+
+func (p *publisher) Publish(ctx context.Context, topic string, m queue.Message) error {
+	msg := kafkago.Message{Topic: topic, Key: m.Key, Value: m.Value}
+	for k, v := range m.Headers {
+		msg.Headers = append(msg.Headers, kafkago.Header{Key: k, Value: []byte(v)})
+	}
+	if err := p.w.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("kafka: write to %s: %w", topic, err)
+	}
+	return nil
+}
+
+func (p *publisher) Close() error {
+	return p.w.Close()
+}
@@ -0,0 +1,76 @@
+package memcache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"doc2text/internal/core/abstraction/cache"
+)
+
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// Cache is an in-process LRU bounded by the total size of its values.
+type Cache struct {
+	maxBytes int64
+
+	mu    sync.Mutex
+	size  int64
+	order *list.List
+	items map[string]*list.Element
+}
+
+var _ cache.Store = (*Cache)(nil)
+
+func New(maxBytes int64) *Cache {
+	return &Cache{maxBytes: maxBytes, order: list.New(), items: map[string]*list.Element{}}
+}
+
+func (c *Cache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := el.Value.(*entry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.remove(el)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	return e.value, true, nil
+}
+
+// Set drops values larger than the whole cache rather than flushing it.
+func (c *Cache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	if int64(len(value)) > c.maxBytes {
+		return nil
+	}
+	e := &entry{key: key, value: value}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	c.items[key] = c.order.PushFront(e)
+	c.size += int64(len(value))
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *Cache) remove(el *list.Element) {
+	e := c.order.Remove(el).(*entry)
+	delete(c.items, e.key)
+	c.size -= int64(len(e.value))
+}
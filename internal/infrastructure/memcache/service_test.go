@@ -0,0 +1,79 @@
+package memcache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, c *Cache, key string) (string, bool) {
+	t.Helper()
+	b, ok, err := c.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", key, err)
+	}
+	return string(b), ok
+}
+
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := New(6)
+	c.Set(ctx, "a", []byte("aa"), 0)
+	c.Set(ctx, "b", []byte("bb"), 0)
+	c.Set(ctx, "c", []byte("cc"), 0)
+	// Reading a makes b the oldest.
+	if v, ok := get(t, c, "a"); !ok || v != "aa" {
+		t.Fatalf("Get(a) = %q, %t", v, ok)
+	}
+	c.Set(ctx, "d", []byte("dd"), 0)
+
+	if _, ok := get(t, c, "b"); ok {
+		t.Fatal("b was not evicted")
+	}
+	for _, k := range []string{"a", "c", "d"} {
+		if _, ok := get(t, c, k); !ok {
+			t.Fatalf("%s was evicted", k)
+		}
+	}
+	if c.size != 6 {
+		t.Fatalf("size = %d, want 6", c.size)
+	}
+}
+
+func TestReplaceKeepsSize(t *testing.T) {
+	ctx := context.Background()
+	c := New(10)
+	c.Set(ctx, "a", []byte("aaaa"), 0)
+	c.Set(ctx, "a", []byte("a"), 0)
+	if v, _ := get(t, c, "a"); v != "a" || c.size != 1 {
+		t.Fatalf("Get(a) = %q, size %d", v, c.size)
+	}
+}
+
+func TestSkipsValueLargerThanCache(t *testing.T) {
+	ctx := context.Background()
+	c := New(4)
+	c.Set(ctx, "a", []byte("aa"), 0)
+	c.Set(ctx, "big", []byte("bigger"), 0)
+	if _, ok := get(t, c, "big"); ok {
+		t.Fatal("value larger than the cache was stored")
+	}
+	if _, ok := get(t, c, "a"); !ok {
+		t.Fatal("large value flushed the cache")
+	}
+}
+
+func TestExpires(t *testing.T) {
+	c := New(10)
+	c.Set(context.Background(), "a", []byte("a"), 10*time.Millisecond)
+	if _, ok := get(t, c, "a"); !ok {
+		t.Fatal("entry missing before its TTL")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := get(t, c, "a"); ok {
+		t.Fatal("entry served after its TTL")
+	}
+	if c.size != 0 || len(c.items) != 0 {
+		t.Fatalf("expired entry kept: size %d, items %d", c.size, len(c.items))
+	}
+}
@@ -0,0 +1,67 @@
+package mimetypes
+
+import (
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const Default = "application/octet-stream"
+
+// byExt backs up mime.TypeByExtension, which knows little beyond the basics
+// when the system has no mime.types (e.g. distroless images).
+var byExt = map[string]string{
+	".pdf":  "application/pdf",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".odt":  "application/vnd.oasis.opendocument.text",
+	".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+	".odp":  "application/vnd.oasis.opendocument.presentation",
+	".txt":  "text/plain",
+	".htm":  "text/html",
+	".html": "text/html",
+	".rtf":  "application/rtf",
+	".md":   "text/markdown",
+	".eml":  "message/rfc822",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".tif":  "image/tiff",
+	".tiff": "image/tiff",
+	".bmp":  "image/bmp",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".heic": "image/heic",
+	".heif": "image/heif",
+	".zip":  "application/zip",
+	".tar":  "application/x-tar",
+	".gz":   "application/gzip",
+	".tgz":  "application/gzip",
+	".7z":   "application/x-7z-compressed",
+}
+
+// ByExtension returns the MIME type for the file name's extension, or "".
+func ByExtension(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == "" {
+		return ""
+	}
+	if t, ok := byExt[ext]; ok {
+		return t
+	}
+	return mime.TypeByExtension(ext)
+}
+
+// Detect trusts the extension first and sniffs the content otherwise.
+func Detect(name string, content []byte) string {
+	if t := ByExtension(name); t != "" {
+		return t
+	}
+	if t := mimetype.Detect(content).String(); t != "" {
+		return t
+	}
+	return Default
+}
@@ -0,0 +1,254 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"doc2text/internal/core/abstraction/queue"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// fetchWait bounds one pull so Receive notices a cancelled ctx.
+const fetchWait = 2 * time.Second
+
+type Options struct {
+	URL       string
+	Name      string
+	CredsFile string
+}
+
+type Conn struct {
+	nc *natsgo.Conn
+	js jetstream.JetStream
+}
+
+// Connect dials the server and keeps reconnecting for as long as the
+// process runs.
+func Connect(o Options) (*Conn, error) {
+	opts := []natsgo.Option{natsgo.Name(o.Name), natsgo.MaxReconnects(-1)}
+	if o.CredsFile != "" {
+		opts = append(opts, natsgo.UserCredentials(o.CredsFile))
+	}
+	nc, err := natsgo.Connect(o.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("nats: connect %s: %w", o.URL, err)
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("nats: jetstream: %w", err)
+	}
+	return &Conn{nc: nc, js: js}, nil
+}
+
+// Close drains subscriptions, flushes pending publishes and disconnects.
+func (c *Conn) Close() error {
+	return c.nc.Drain()
+}
+
+type StreamOptions struct {
+	Name     string
+	Subjects []string
+	MaxAge   time.Duration
+}
+
+// EnsureStream creates the stream or updates its subjects and retention.
+func (c *Conn) EnsureStream(ctx context.Context, o StreamOptions) error {
+	_, err := c.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      o.Name,
+		Subjects:  o.Subjects,
+		Retention: jetstream.LimitsPolicy,
+		Storage:   jetstream.FileStorage,
+		MaxAge:    o.MaxAge,
+	})
+	if err != nil {
+		return fmt.Errorf("nats: stream %s: %w", o.Name, err)
+	}
+	return nil
+}
+
+type subscription struct {
+	sub *natsgo.Subscription
+}
+
+// Subscribe joins the queue group on a core NATS subject. Each message goes
+// to one member of the group and is not redelivered, so Ack does nothing;
+// messages with a reply subject can be answered with Respond.
+func (c *Conn) Subscribe(subject, group string) (queue.Subscription, error) {
+	sub, err := c.nc.QueueSubscribeSync(subject, group)
+	if err != nil {
+		return nil, fmt.Errorf("nats: subscribe %s: %w", subject, err)
+	}
+	return &subscription{sub: sub}, nil
+}
+
+func (s *subscription) Receive(ctx context.Context) (queue.Delivery, error) {
+	m, err := s.sub.NextMsgWithContext(ctx)
+	if err != nil {
+		return queue.Delivery{}, fmt.Errorf("nats: next on %s: %w", s.sub.Subject, err)
+	}
+	d := queue.Delivery{
+		Message: queue.Message{Value: m.Data, Headers: headers(m.Header)},
+		Ack:     func(context.Context) error { return nil },
+	}
+	if m.Reply != "" {
+		d.Respond = func(_ context.Context, r queue.Message) error {
+			return m.RespondMsg(message(m.Reply, r))
+		}
+	}
+	return d, nil
+}
+
+func (s *subscription) Close() error {
+	return s.sub.Unsubscribe()
+}
+
+type ConsumerOptions struct {
+	Stream  string
+	Durable string
+	Subject string
+	// MaxDeliver caps deliveries of one message; after that the server
+	// drops it from the consumer.
+	MaxDeliver int
+	// AckWait is how long a silent consumer keeps a message. Messages in
+	// work are kept alive, so it only bounds how soon a crashed worker's
+	// messages come back.
+	AckWait time.Duration
+}
+
+type consumer struct {
+	c       jetstream.Consumer
+	ackWait time.Duration
+}
+
+// Consume creates or updates a durable pull consumer. All workers sharing
+// the durable name split its messages between them.
+func (c *Conn) Consume(ctx context.Context, o ConsumerOptions) (queue.Subscription, error) {
+	cons, err := c.js.CreateOrUpdateConsumer(ctx, o.Stream, jetstream.ConsumerConfig{
+		Durable:       o.Durable,
+		FilterSubject: o.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       o.AckWait,
+		MaxDeliver:    o.MaxDeliver,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("nats: consumer %s on %s: %w", o.Durable, o.Stream, err)
+	}
+	return &consumer{c: cons, ackWait: o.AckWait}, nil
+}
+
+func (s *consumer) Receive(ctx context.Context) (queue.Delivery, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return queue.Delivery{}, err
+		}
+		batch, err := s.c.Fetch(1, jetstream.FetchMaxWait(fetchWait))
+		if err != nil {
+			return queue.Delivery{}, fmt.Errorf("nats: fetch: %w", err)
+		}
+		for m := range batch.Messages() {
+			return s.delivery(ctx, m), nil
+		}
+		if err := batch.Error(); err != nil && !errors.Is(err, natsgo.ErrTimeout) && !errors.Is(err, jetstream.ErrNoMessages) {
+			return queue.Delivery{}, fmt.Errorf("nats: fetch: %w", err)
+		}
+	}
+}
+
+func (s *consumer) delivery(ctx context.Context, m jetstream.Msg) queue.Delivery {
+	attempt := 0
+	if md, err := m.Metadata(); err == nil {
+		attempt = int(md.NumDelivered)
+	}
+	done := make(chan struct{})
+	go s.keepAlive(ctx, m, done)
+	var once sync.Once
+	settle := func(f func() error) error {
+		once.Do(func() { close(done) })
+		return f()
+	}
+	return queue.Delivery{
+		Message: queue.Message{Value: m.Data(), Headers: headers(m.Headers())},
+		Attempt: attempt,
+		Ack: func(ctx context.Context) error {
+			return settle(func() error { return m.DoubleAck(ctx) })
+		},
+		Nak: func(_ context.Context, delay time.Duration) error {
+			return settle(func() error { return m.NakWithDelay(delay) })
+		},
+	}
+}
+
+// keepAlive resets the ack timer while the message is being worked on.
+func (s *consumer) keepAlive(ctx context.Context, m jetstream.Msg, done <-chan struct{}) {
+	if s.ackWait <= 0 {
+		return
+	}
+	t := time.NewTicker(s.ackWait / 2)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-t.C:
+			_ = m.InProgress()
+		}
+	}
+}
+
+func (s *consumer) Close() error {
+	return nil
+}
+
+type publisher struct {
+	js jetstream.JetStream
+}
+
+// Publisher publishes to JetStream and waits for the stream's ack; the
+// subject must belong to a stream.
+func (c *Conn) Publisher() queue.Publisher {
+	return &publisher{js: c.js}
+}
+
+func (p *publisher) Publish(ctx context.Context, subject string, m queue.Message) error {
+	if _, err := p.js.PublishMsg(ctx, message(subject, m)); err != nil {
+		return fmt.Errorf("nats: publish to %s: %w", subject, err)
+	}
+	return nil
+}
+
+func (p *publisher) Close() error {
+	return nil
+}
+
+// NATS has no message keys; correlation travels in headers only.
+func message(subject string, m queue.Message) *natsgo.Msg {
+	msg := natsgo.NewMsg(subject)
+	msg.Data = m.Value
+	for k, v := range m.Headers {
+		msg.Header.Set(k, v)
+	}
+	return msg
+}
+
+// headers drops the server's own Nats-* headers: copied onto a dead letter,
+// ones like Nats-Msg-Id or Nats-Expected-Stream would change how it is
+// stored.
+func headers(h natsgo.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k := range h {
+		if !strings.HasPrefix(k, "Nats-") {
+			out[k] = h.Get(k)
+		}
+	}
+	return out
+}
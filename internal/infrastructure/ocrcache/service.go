@@ -0,0 +1,100 @@
+package ocrcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"doc2text/internal/core/abstraction/cache"
+	"doc2text/internal/core/abstraction/logger"
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+type Options struct {
+	// TTL bounds how long a result is reused; zero keeps it until the
+	// store evicts it.
+	TTL time.Duration
+	// Fingerprint describes the recognizer setup (backends, models,
+	// languages, preprocessing). Changing it invalidates every entry.
+	Fingerprint string
+	Logger      logger.Logger
+}
+
+type cacheRecognizer struct {
+	next  recognize.Recognizer
+	store cache.Store
+	o     Options
+}
+
+// Wrap returns a recognizer that reuses results of next for content it has
+// already seen with the same options. The cache is an optimization: store
+// errors are logged and treated as misses, and failures are not cached.
+func Wrap(next recognize.Recognizer, store cache.Store, o Options) recognize.Recognizer {
+	return &cacheRecognizer{next: next, store: store, o: o}
+}
+
+func (r *cacheRecognizer) Recognize(ctx context.Context, req recognize.Request) (recognize.Response, error) {
+	key, err := r.key(req)
+	if err != nil {
+		// Let the recognizer report the broken content.
+		return r.next.Recognize(ctx, req)
+	}
+
+	if b, ok, err := r.store.Get(ctx, key); err != nil {
+		r.o.Logger.Error("ocrcache: get %s: %v", key, err)
+	} else if ok {
+		var res recognize.Response
+		if err := json.Unmarshal(b, &res); err == nil {
+			res.Cached = true
+			return res, nil
+		}
+		r.o.Logger.Error("ocrcache: decode %s: %v", key, err)
+	}
+
+	res, err := r.next.Recognize(ctx, req)
+	if err != nil {
+		return res, err
+	}
+	b, err := json.Marshal(res)
+	if err == nil {
+		err = r.store.Set(ctx, key, b, r.o.TTL)
+	}
+	if err != nil {
+		r.o.Logger.Error("ocrcache: set %s: %v", key, err)
+	}
+	return res, nil
+}
+
+// key hashes the decoded content together with everything else that
+// changes the result.
+func (r *cacheRecognizer) key(req recognize.Request) (string, error) {
+	content := sha256.New()
+	dec := base64.NewDecoder(base64.StdEncoding, strings.NewReader(req.ContentBase64))
+	if _, err := io.Copy(content, dec); err != nil {
+		return "", fmt.Errorf("ocrcache: decode base64: %w", err)
+	}
+
+	minConf := "-"
+	if req.MinConfidence != nil {
+		minConf = strconv.FormatFloat(*req.MinConfidence, 'g', -1, 64)
+	}
+	h := sha256.New()
+	for _, part := range []string{
+		r.o.Fingerprint,
+		strings.ToLower(strings.TrimSpace(strings.SplitN(req.MimeType, ";", 2)[0])),
+		minConf,
+		req.Backend,
+		hex.EncodeToString(content.Sum(nil)),
+	} {
+		// Length prefixes keep the parts from running into each other.
+		fmt.Fprintf(h, "%d:%s\n", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
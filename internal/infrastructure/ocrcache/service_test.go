@@ -0,0 +1,123 @@
+package ocrcache
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"doc2text/internal/core/abstraction/recognize"
+	"doc2text/internal/infrastructure/memcache"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// countingRecognizer answers with the call number, or fails with err.
+type countingRecognizer struct {
+	calls int
+	err   error
+}
+
+func (r *countingRecognizer) Recognize(context.Context, recognize.Request) (recognize.Response, error) {
+	r.calls++
+	if r.err != nil {
+		return recognize.Response{}, r.err
+	}
+	return recognize.Response{ExtractedText: "text", Backend: "fake", Pages: []recognize.Page{{Width: int64(r.calls)}}}, nil
+}
+
+func request(content string) recognize.Request {
+	return recognize.Request{ContentBase64: base64.StdEncoding.EncodeToString([]byte(content)), MimeType: "image/png"}
+}
+
+func TestHitAfterMiss(t *testing.T) {
+	next := &countingRecognizer{}
+	r := Wrap(next, memcache.New(1<<20), Options{TTL: time.Hour, Fingerprint: "fp", Logger: nopLogger{}})
+
+	first, err := r.Recognize(context.Background(), request("img"))
+	if err != nil || first.Cached {
+		t.Fatalf("first Recognize() = cached %t, %v", first.Cached, err)
+	}
+	second, err := r.Recognize(context.Background(), request("img"))
+	if err != nil || !second.Cached || next.calls != 1 {
+		t.Fatalf("second Recognize() = cached %t, %v after %d calls", second.Cached, err, next.calls)
+	}
+	if second.ExtractedText != "text" || second.Backend != "fake" || second.Pages[0].Width != 1 {
+		t.Fatalf("cached response = %+v", second)
+	}
+}
+
+func TestKeyCoversOptions(t *testing.T) {
+	half := 0.5
+	base := request("img")
+	variants := map[string]recognize.Request{}
+	for name, change := range map[string]func(*recognize.Request){
+		"content":    func(r *recognize.Request) { r.ContentBase64 = base64.StdEncoding.EncodeToString([]byte("other")) },
+		"mime":       func(r *recognize.Request) { r.MimeType = "image/jpeg" },
+		"confidence": func(r *recognize.Request) { r.MinConfidence = &half },
+		"backend":    func(r *recognize.Request) { r.Backend = "tesseract" },
+	} {
+		req := base
+		change(&req)
+		variants[name] = req
+	}
+
+	r := &cacheRecognizer{o: Options{Fingerprint: "fp"}}
+	baseKey, err := r.key(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, req := range variants {
+		if k, _ := r.key(req); k == baseKey {
+			t.Errorf("%s does not change the key", name)
+		}
+	}
+	params := base
+	params.MimeType = "IMAGE/PNG; charset=binary"
+	if k, _ := r.key(params); k != baseKey {
+		t.Error("MIME parameters change the key")
+	}
+	other := &cacheRecognizer{o: Options{Fingerprint: "fp2"}}
+	if k, _ := other.key(base); k == baseKey {
+		t.Error("fingerprint does not change the key")
+	}
+}
+
+func TestErrorsNotCached(t *testing.T) {
+	next := &countingRecognizer{err: errors.New("boom")}
+	r := Wrap(next, memcache.New(1<<20), Options{Logger: nopLogger{}})
+
+	for range 2 {
+		if _, err := r.Recognize(context.Background(), request("img")); err == nil {
+			t.Fatal("Recognize() error = nil")
+		}
+	}
+	if next.calls != 2 {
+		t.Fatalf("recognizer called %d times, want 2", next.calls)
+	}
+}
+
+// failingStore fails every call.
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("store down")
+}
+
+func (failingStore) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("store down")
+}
+
+func TestStoreErrorIsMiss(t *testing.T) {
+	next := &countingRecognizer{}
+	r := Wrap(next, failingStore{}, Options{Logger: nopLogger{}})
+
+	res, err := r.Recognize(context.Background(), request("img"))
+	if err != nil || res.Cached || next.calls != 1 {
+		t.Fatalf("Recognize() = cached %t, %v after %d calls", res.Cached, err, next.calls)
+	}
+}
@@ -0,0 +1,64 @@
+package ocrchain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+// ParseRoute reads a route written as
+//
+//	<mime>[|<mime>...][@<min>-<max>]=<backend>[,<backend>...]
+//
+// e.g. "image/*@-2MiB=tesseract,yandex" or "application/pdf=yandex". Either
+// size bound may be omitted; "*" matches any MIME type.
+func ParseRoute(s string) (Route, error) {
+	cond, backends, ok := strings.Cut(strings.TrimSpace(s), "=")
+	if !ok || backends == "" {
+		return Route{}, fmt.Errorf("route %q: expected <condition>=<backends>", s)
+	}
+
+	var rt Route
+	for _, b := range strings.Split(backends, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			rt.Backends = append(rt.Backends, b)
+		}
+	}
+
+	if len(rt.Backends) == 0 {
+		return Route{}, fmt.Errorf("route %q: no backends", s)
+	}
+
+	mimes, sizes, hasSize := strings.Cut(cond, "@")
+	for _, m := range strings.Split(mimes, "|") {
+		if m = strings.ToLower(strings.TrimSpace(m)); m != "" && m != "*" {
+			rt.MimeTypes = append(rt.MimeTypes, m)
+		}
+	}
+	if hasSize {
+		lo, hi, ok := strings.Cut(sizes, "-")
+		if !ok {
+			return Route{}, fmt.Errorf("route %q: size must be <min>-<max>", s)
+		}
+		var err error
+		if rt.MinSize, err = parseSize(lo); err != nil {
+			return Route{}, fmt.Errorf("route %q: %w", s, err)
+		}
+		if rt.MaxSize, err = parseSize(hi); err != nil {
+			return Route{}, fmt.Errorf("route %q: %w", s, err)
+		}
+	}
+	return rt, nil
+}
+
+func parseSize(s string) (int64, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return 0, nil
+	}
+	n, err := humanize.ParseBytes(s)
+	if err != nil {
+		return 0, err
+	}
+	return int64(n), nil
+}
@@ -0,0 +1,197 @@
+package ocrchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"strings"
+
+	"doc2text/internal/core/abstraction/logger"
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+// Error classes that trigger a fallback to the next backend.
+const (
+	FallbackQuota       = "quota"
+	FallbackUnavailable = "unavailable"
+	FallbackTimeout     = "timeout"
+	FallbackUnsupported = "unsupported"
+	FallbackAny         = "any"
+)
+
+type Options struct {
+	Backends map[string]recognize.Recognizer
+	// Default is the backend chain used when no route matches.
+	Default []string
+	// Routes are checked in order; the first match picks the chain.
+	Routes     []Route
+	FallbackOn []string
+	Logger     logger.Logger
+}
+
+type chainRecognizer struct {
+	backends   map[string]recognize.Recognizer
+	defaults   []string
+	routes     []Route
+	fallbackOn map[string]bool
+	log        logger.Logger
+}
+
+func New(o Options) (recognize.Recognizer, error) {
+	if len(o.Default) == 0 {
+		return nil, errors.New("ocrchain: default chain is empty")
+	}
+	check := func(names []string) error {
+		for _, n := range names {
+			if _, ok := o.Backends[n]; !ok {
+				return fmt.Errorf("ocrchain: unknown backend %q", n)
+			}
+		}
+		return nil
+	}
+	if err := check(o.Default); err != nil {
+		return nil, err
+	}
+	for i, rt := range o.Routes {
+		if len(rt.Backends) == 0 {
+			return nil, fmt.Errorf("ocrchain: route %d has no backends", i+1)
+		}
+		if err := check(rt.Backends); err != nil {
+			return nil, err
+		}
+	}
+
+	fallbackOn := make(map[string]bool, len(o.FallbackOn))
+	for _, c := range o.FallbackOn {
+		switch c {
+		case FallbackQuota, FallbackUnavailable, FallbackTimeout, FallbackUnsupported, FallbackAny:
+			fallbackOn[c] = true
+		default:
+			return nil, fmt.Errorf("ocrchain: unknown fallback class %q", c)
+		}
+	}
+
+	return &chainRecognizer{
+		backends:   o.Backends,
+		defaults:   o.Default,
+		routes:     o.Routes,
+		fallbackOn: fallbackOn,
+		log:        o.Logger,
+	}, nil
+}
+
+func (r *chainRecognizer) Recognize(ctx context.Context, req recognize.Request) (recognize.Response, error) {
+	chain, err := r.chainFor(req)
+	if err != nil {
+		return recognize.Response{}, err
+	}
+
+	var errs []error
+	for i, name := range chain {
+		res, err := r.backends[name].Recognize(ctx, req)
+		if err == nil {
+			res.Backend = name
+			return res, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+
+		if ctx.Err() != nil || i == len(chain)-1 || !r.shouldFallback(err) {
+			break
+		}
+		if r.log != nil {
+			r.log.Info("ocrchain: %s failed (%v), falling back to %s", name, err, chain[i+1])
+		}
+	}
+	return recognize.Response{}, errors.Join(errs...)
+}
+
+// chainFor picks the backend order for the request: an explicit hint goes
+// first, followed by the chain of the first matching route or the default.
+func (r *chainRecognizer) chainFor(req recognize.Request) ([]string, error) {
+	chain := r.defaults
+	size := int64(base64DecodedLen(req.ContentBase64))
+	for _, rt := range r.routes {
+		if rt.Match(req.MimeType, size) {
+			chain = rt.Backends
+			break
+		}
+	}
+
+	if req.Backend == "" {
+		return chain, nil
+	}
+	if _, ok := r.backends[req.Backend]; !ok {
+		return nil, fmt.Errorf("ocrchain: %w %q", recognize.ErrUnknownBackend, req.Backend)
+	}
+	out := []string{req.Backend}
+	for _, n := range chain {
+		if n != req.Backend {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+func (r *chainRecognizer) shouldFallback(err error) bool {
+	if r.fallbackOn[FallbackAny] {
+		return true
+	}
+	if r.fallbackOn[FallbackQuota] && errors.Is(err, recognize.ErrQuotaExceeded) {
+		return true
+	}
+	if r.fallbackOn[FallbackUnavailable] && errors.Is(err, recognize.ErrUnavailable) {
+		return true
+	}
+	if r.fallbackOn[FallbackUnsupported] && errors.Is(err, recognize.ErrUnsupportedType) {
+		return true
+	}
+	if r.fallbackOn[FallbackTimeout] {
+		var ne net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &ne) && ne.Timeout()) {
+			return true
+		}
+	}
+	return false
+}
+
+func base64DecodedLen(s string) int {
+	n := len(s) / 4 * 3
+	if strings.HasSuffix(s, "==") {
+		return n - 2
+	}
+	if strings.HasSuffix(s, "=") {
+		return n - 1
+	}
+	return n
+}
+
+// Route sends requests whose MIME type matches one of MimeTypes (path.Match
+// patterns such as image/*) and whose size is within [MinSize, MaxSize] to
+// Backends. Empty MimeTypes match any type; zero sizes are unbounded.
+type Route struct {
+	MimeTypes []string
+	MinSize   int64
+	MaxSize   int64
+	Backends  []string
+}
+
+func (rt Route) Match(mimeType string, size int64) bool {
+	if rt.MinSize > 0 && size < rt.MinSize {
+		return false
+	}
+	if rt.MaxSize > 0 && size > rt.MaxSize {
+		return false
+	}
+	if len(rt.MimeTypes) == 0 {
+		return true
+	}
+	mimeType = strings.ToLower(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0]))
+	for _, p := range rt.MimeTypes {
+		if ok, _ := path.Match(p, mimeType); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package ocrchain
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+func TestParseRoute(t *testing.T) {
+	for in, want := range map[string]Route{
+		"image/*@-2MiB=tesseract,yandex":   {MimeTypes: []string{"image/*"}, MaxSize: 2 << 20, Backends: []string{"tesseract", "yandex"}},
+		"Application/PDF|image/png=yandex": {MimeTypes: []string{"application/pdf", "image/png"}, Backends: []string{"yandex"}},
+		"*@1KB-=tesseract":                 {MinSize: 1000, Backends: []string{"tesseract"}},
+		" * = yandex , ":                   {Backends: []string{"yandex"}},
+	} {
+		got, err := ParseRoute(in)
+		if err != nil {
+			t.Errorf("ParseRoute(%q) error = %v", in, err)
+			continue
+		}
+		if !slices.Equal(got.MimeTypes, want.MimeTypes) || got.MinSize != want.MinSize || got.MaxSize != want.MaxSize || !slices.Equal(got.Backends, want.Backends) {
+			t.Errorf("ParseRoute(%q) = %+v, want %+v", in, got, want)
+		}
+	}
+	for _, in := range []string{"image/*", "image/*=", "image/*=,", "image/*= , ", "image/*@2MiB=yandex", "image/*@x-=yandex"} {
+		if _, err := ParseRoute(in); err == nil {
+			t.Errorf("ParseRoute(%q) error = nil", in)
+		}
+	}
+}
+
+func TestRouteMatch(t *testing.T) {
+	rt := Route{MimeTypes: []string{"image/*", "application/pdf"}, MinSize: 10, MaxSize: 100}
+	for _, tc := range []struct {
+		mime string
+		size int64
+		want bool
+	}{
+		{"image/png", 50, true},
+		{"IMAGE/JPEG; q=1", 10, true},
+		{"application/pdf", 100, true},
+		{"text/plain", 50, false},
+		{"image/png", 9, false},
+		{"image/png", 101, false},
+	} {
+		if got := rt.Match(tc.mime, tc.size); got != tc.want {
+			t.Errorf("Match(%q, %d) = %t, want %t", tc.mime, tc.size, got, tc.want)
+		}
+	}
+	if !(Route{}).Match("anything", 1<<40) {
+		t.Error("empty route does not match everything")
+	}
+}
+
+func TestNewRejectsEmptyRoute(t *testing.T) {
+	_, err := New(Options{
+		Backends: map[string]recognize.Recognizer{"a": &fakeBackend{}},
+		Default:  []string{"a"},
+		Routes:   []Route{{MimeTypes: []string{"image/*"}}},
+	})
+	if err == nil {
+		t.Fatal("New() error = nil, want route without backends rejected")
+	}
+}
+
+// fakeBackend fails with err, or answers with its name.
+type fakeBackend struct {
+	name  string
+	err   error
+	calls *[]string
+}
+
+func (b *fakeBackend) Recognize(context.Context, recognize.Request) (recognize.Response, error) {
+	*b.calls = append(*b.calls, b.name)
+	if b.err != nil {
+		return recognize.Response{}, b.err
+	}
+	return recognize.Response{ExtractedText: b.name}, nil
+}
+
+func newChain(t *testing.T, errs map[string]error, o Options) (recognize.Recognizer, *[]string) {
+	t.Helper()
+	calls := &[]string{}
+	o.Backends = map[string]recognize.Recognizer{}
+	for _, name := range []string{"a", "b", "c"} {
+		o.Backends[name] = &fakeBackend{name: name, err: errs[name], calls: calls}
+	}
+	r, err := New(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, calls
+}
+
+func request(mimeType string, size int) recognize.Request {
+	return recognize.Request{ContentBase64: base64.StdEncoding.EncodeToString(make([]byte, size)), MimeType: mimeType}
+}
+
+func TestRoutesAndHint(t *testing.T) {
+	routes := []Route{
+		{MimeTypes: []string{"image/*"}, MaxSize: 10, Backends: []string{"c", "b"}},
+		{MimeTypes: []string{"image/*"}, Backends: []string{"b"}},
+	}
+	fail := errors.New("down")
+	for name, tc := range map[string]struct {
+		req  recognize.Request
+		want []string
+	}{
+		"default":            {request("application/pdf", 100), []string{"a", "b"}},
+		"small image":        {request("image/png", 5), []string{"c", "b"}},
+		"large image":        {request("image/png", 50), []string{"b"}},
+		"hint first":         {func() recognize.Request { r := request("image/png", 5); r.Backend = "b"; return r }(), []string{"b", "c"}},
+		"hint off the route": {func() recognize.Request { r := request("image/png", 50); r.Backend = "a"; return r }(), []string{"a", "b"}},
+	} {
+		r, calls := newChain(t, map[string]error{"a": fail, "b": fail, "c": fail}, Options{
+			Default:    []string{"a", "b"},
+			Routes:     routes,
+			FallbackOn: []string{FallbackAny},
+		})
+		if _, err := r.Recognize(context.Background(), tc.req); err == nil {
+			t.Errorf("%s: Recognize() error = nil", name)
+		}
+		if !slices.Equal(*calls, tc.want) {
+			t.Errorf("%s: tried %v, want %v", name, *calls, tc.want)
+		}
+	}
+}
+
+func TestUnknownHint(t *testing.T) {
+	r, calls := newChain(t, nil, Options{Default: []string{"a"}})
+	req := request("image/png", 1)
+	req.Backend = "nope"
+	if _, err := r.Recognize(context.Background(), req); !errors.Is(err, recognize.ErrUnknownBackend) {
+		t.Fatalf("Recognize() error = %v, want unknown backend", err)
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("tried %v", *calls)
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestFallbackClasses(t *testing.T) {
+	errs := []struct {
+		name  string
+		err   error
+		class string
+	}{
+		{"quota", fmt.Errorf("x: %w", recognize.ErrQuotaExceeded), FallbackQuota},
+		{"unavailable", fmt.Errorf("x: %w", recognize.ErrUnavailable), FallbackUnavailable},
+		{"unsupported", fmt.Errorf("x: %w", recognize.ErrUnsupportedType), FallbackUnsupported},
+		{"deadline", fmt.Errorf("x: %w", context.DeadlineExceeded), FallbackTimeout},
+		{"net timeout", fmt.Errorf("x: %w", timeoutError{}), FallbackTimeout},
+		{"other", errors.New("bad request"), ""},
+	}
+	for _, enabled := range []string{FallbackQuota, FallbackUnavailable, FallbackUnsupported, FallbackTimeout, FallbackAny} {
+		for _, e := range errs {
+			r, calls := newChain(t, map[string]error{"a": e.err}, Options{Default: []string{"a", "b"}, FallbackOn: []string{enabled}})
+			res, err := r.Recognize(context.Background(), request("image/png", 1))
+
+			want := enabled == FallbackAny || enabled == e.class
+			if fellBack := len(*calls) == 2; fellBack != want {
+				t.Errorf("fallback on %s, %s error: tried %v", enabled, e.name, *calls)
+				continue
+			}
+			if want && (err != nil || res.Backend != "b") {
+				t.Errorf("fallback on %s, %s error: Recognize() = %q, %v", enabled, e.name, res.Backend, err)
+			}
+			if !want && (err == nil || !strings.HasPrefix(err.Error(), "a: ")) {
+				t.Errorf("fallback on %s, %s error: Recognize() error = %v", enabled, e.name, err)
+			}
+		}
+	}
+}
+
+func TestNoFallbackAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, calls := newChain(t, map[string]error{"a": context.Canceled}, Options{Default: []string{"a", "b"}, FallbackOn: []string{FallbackAny}})
+	if _, err := r.Recognize(ctx, request("image/png", 1)); err == nil {
+		t.Fatal("Recognize() error = nil")
+	}
+	if !slices.Equal(*calls, []string{"a"}) {
+		t.Fatalf("tried %v, want only a", *calls)
+	}
+}
@@ -0,0 +1,133 @@
+package officetext
+
+import (
+	"context"
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+const (
+	nsText  = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	nsTable = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	nsDraw  = "urn:oasis:names:tc:opendocument:xmlns:drawing:1.0"
+
+	// maxRepeat bounds table:number-columns-repeated so styled empty
+	// columns do not expand into millions of cells.
+	maxRepeat = 256
+)
+
+// readODF handles ODT, ODS and ODP from content.xml. Text documents become
+// one page with a block per paragraph and a line per table row. pageElement
+// names the element that starts a labelled page of its own: table:table for
+// ODS sheets, draw:page for ODP slides.
+func readODF(ctx context.Context, p *pkg, pageElement string) ([]recognize.Page, error) {
+	var (
+		pages   []recognize.Page
+		body    pageBuilder
+		cur     = &body
+		label   string
+		nested  int
+		inFrame int
+		cells   []string
+		cell    strings.Builder
+		repeat  int
+		inCell  bool
+		inPara  int
+		section *pageBuilder
+	)
+	isPage := func(n xml.Name) bool {
+		return pageElement != "" && n.Local == pageElement &&
+			(n.Space == nsTable || n.Space == nsDraw)
+	}
+	para := func() *strings.Builder {
+		if inCell {
+			return &cell
+		}
+		return &cur.line
+	}
+
+	err := p.decode(ctx, "content.xml", func(tok xml.Token) error {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case isPage(t.Name):
+				nested++
+				if nested == 1 {
+					section = &pageBuilder{}
+					cur = section
+					label = attr(t, "name")
+				}
+				cur.startBlock()
+			case t.Name.Space == nsTable && t.Name.Local == "table-row":
+				cells = cells[:0]
+			case t.Name.Space == nsTable && t.Name.Local == "table-cell":
+				inCell = true
+				cell.Reset()
+				repeat, _ = strconv.Atoi(attr(t, "number-columns-repeated"))
+				repeat = min(max(repeat, 1), maxRepeat)
+			case t.Name.Space == nsDraw && t.Name.Local == "frame":
+				inFrame++
+				cur.startBlock()
+			case t.Name.Space == nsText && (t.Name.Local == "p" || t.Name.Local == "h"):
+				inPara++
+				if inCell && cell.Len() > 0 {
+					cell.WriteByte(' ')
+				}
+			case t.Name.Space == nsText && t.Name.Local == "s":
+				n, _ := strconv.Atoi(attr(t, "c"))
+				para().WriteString(strings.Repeat(" ", min(max(n, 1), maxRepeat)))
+			case t.Name.Space == nsText && t.Name.Local == "tab":
+				para().WriteByte('\t')
+			case t.Name.Space == nsText && t.Name.Local == "line-break":
+				if !inCell {
+					cur.endLine()
+				}
+			}
+		case xml.EndElement:
+			switch {
+			case isPage(t.Name):
+				nested--
+				if nested == 0 {
+					pages = append(pages, section.finish(label))
+					cur = &body
+				}
+			case t.Name.Space == nsTable && t.Name.Local == "table-cell":
+				inCell = false
+				for i := 0; i < repeat; i++ {
+					cells = append(cells, cell.String())
+				}
+			case t.Name.Space == nsTable && t.Name.Local == "table-row":
+				cur.endRow(cells)
+			case t.Name.Space == nsDraw && t.Name.Local == "frame":
+				inFrame--
+				cur.endBlock()
+			case t.Name.Space == nsText && (t.Name.Local == "p" || t.Name.Local == "h"):
+				inPara--
+				switch {
+				case inCell:
+				case inFrame > 0:
+					cur.endLine()
+				default:
+					cur.endBlock()
+					cur.startBlock()
+				}
+			}
+		case xml.CharData:
+			if inPara > 0 {
+				para().Write(t)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if doc := body.finish(""); len(doc.Blocks) > 0 || len(pages) == 0 {
+		pages = append([]recognize.Page{doc}, pages...)
+	}
+	return pages, nil
+}
@@ -0,0 +1,263 @@
+package officetext
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+// readDOCX returns the document body as one page with a block per paragraph.
+// Table cells are paragraphs too, so tables come out row by row.
+func readDOCX(ctx context.Context, p *pkg) ([]recognize.Page, error) {
+	var b pageBuilder
+	inText, inRun := false, false
+	err := p.decode(ctx, "word/document.xml", func(tok xml.Token) error {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				b.startBlock()
+			case "r":
+				inRun = true
+			case "t":
+				inText = true
+			case "tab":
+				// w:tab also defines tab stops in paragraph properties.
+				if inRun {
+					b.line.WriteByte('\t')
+				}
+			case "br", "cr":
+				b.endLine()
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p":
+				b.endBlock()
+			case "r":
+				inRun = false
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				b.line.Write(t)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []recognize.Page{b.finish("")}, nil
+}
+
+// readXLSX returns a page per worksheet, labelled with the sheet name, with
+// one line per row and cells separated by tabs.
+func readXLSX(ctx context.Context, p *pkg) ([]recognize.Page, error) {
+	shared, err := readSharedStrings(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	rels, err := p.relationships(ctx, "xl/_rels/workbook.xml.rels", "xl/")
+	if err != nil {
+		return nil, err
+	}
+
+	type sheet struct{ name, part string }
+	var sheets []sheet
+	err = p.decode(ctx, "xl/workbook.xml", func(tok xml.Token) error {
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "sheet" {
+			if part, ok := rels[relID(se)]; ok {
+				sheets = append(sheets, sheet{name: attr(se, "name"), part: part})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]recognize.Page, 0, len(sheets))
+	for _, sh := range sheets {
+		page, err := readWorksheet(ctx, p, sh.part, shared)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page.finish(sh.name))
+	}
+	return pages, nil
+}
+
+func readSharedStrings(ctx context.Context, p *pkg) ([]string, error) {
+	var (
+		out    []string
+		cur    strings.Builder
+		inText bool
+		inPh   bool
+	)
+	err := p.decode(ctx, "xl/sharedStrings.xml", func(tok xml.Token) error {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				cur.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				inPh = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				out = append(out, cur.String())
+			case "t":
+				inText = false
+			case "rPh":
+				inPh = false
+			}
+		case xml.CharData:
+			if inText && !inPh {
+				cur.Write(t)
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errPartNotFound) {
+		return nil, nil
+	}
+	return out, err
+}
+
+func readWorksheet(ctx context.Context, p *pkg, part string, shared []string) (*pageBuilder, error) {
+	b := &pageBuilder{}
+	b.startBlock()
+	var (
+		cells    []string
+		cellType string
+		column   int
+		value    strings.Builder
+		inValue  bool
+	)
+	err := p.decode(ctx, part, func(tok xml.Token) error {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				cells = cells[:0]
+			case "c":
+				cellType = attr(t, "t")
+				column = columnIndex(attr(t, "r"))
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				v := value.String()
+				if cellType == "s" {
+					if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && i >= 0 && i < len(shared) {
+						v = shared[i]
+					}
+				}
+				// Empty cells are usually left out; r says where this one is.
+				for len(cells) < column {
+					cells = append(cells, "")
+				}
+				cells = append(cells, v)
+			case "row":
+				b.endRow(cells)
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// columnIndex returns the zero-based column of a cell reference such as
+// "C5", or -1 when ref has none. Columns end at XFD, so longer ones are
+// not valid either.
+func columnIndex(ref string) int {
+	col, n := 0, 0
+	for ; n < len(ref) && ref[n] >= 'A' && ref[n] <= 'Z'; n++ {
+		col = col*26 + int(ref[n]-'A') + 1
+	}
+	if n == 0 || n > 3 {
+		return -1
+	}
+	return col - 1
+}
+
+// readPPTX returns a page per slide in presentation order, with a block per
+// shape and a line per paragraph.
+func readPPTX(ctx context.Context, p *pkg) ([]recognize.Page, error) {
+	rels, err := p.relationships(ctx, "ppt/_rels/presentation.xml.rels", "ppt/")
+	if err != nil {
+		return nil, err
+	}
+	var slides []string
+	err = p.decode(ctx, "ppt/presentation.xml", func(tok xml.Token) error {
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "sldId" {
+			if part, ok := rels[relID(se)]; ok {
+				slides = append(slides, path.Clean(part))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]recognize.Page, 0, len(slides))
+	for i, part := range slides {
+		var b pageBuilder
+		inText := false
+		err := p.decode(ctx, part, func(tok xml.Token) error {
+			switch t := tok.(type) {
+			case xml.StartElement:
+				switch t.Name.Local {
+				case "txBody":
+					b.startBlock()
+				case "t":
+					inText = true
+				case "br":
+					b.endLine()
+				}
+			case xml.EndElement:
+				switch t.Name.Local {
+				case "txBody":
+					b.endBlock()
+				case "p":
+					b.endLine()
+				case "t":
+					inText = false
+				}
+			case xml.CharData:
+				if inText {
+					b.line.Write(t)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, b.finish(fmt.Sprintf("slide %d", i+1)))
+	}
+	return pages, nil
+}
@@ -0,0 +1,217 @@
+package officetext
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"doc2text/internal/core/abstraction/extract"
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+const (
+	MimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	MimePPTX = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	MimeODT  = "application/vnd.oasis.opendocument.text"
+	MimeODS  = "application/vnd.oasis.opendocument.spreadsheet"
+	MimeODP  = "application/vnd.oasis.opendocument.presentation"
+)
+
+// MimeTypes lists the formats the extractor understands.
+var MimeTypes = []string{MimeDOCX, MimeXLSX, MimePPTX, MimeODT, MimeODS, MimeODP}
+
+// maxPartSize caps how much of a single XML part is inflated.
+const maxPartSize = 64 << 20
+
+type officeExtractor struct{}
+
+func New() extract.Extractor {
+	return &officeExtractor{}
+}
+
+func (e *officeExtractor) Extract(ctx context.Context, req extract.Request) (extract.Response, error) {
+	zr, err := zip.NewReader(bytes.NewReader(req.Content), int64(len(req.Content)))
+	if err != nil {
+		return extract.Response{}, fmt.Errorf("officetext: open zip: %w", err)
+	}
+	pkg := &pkg{zr: zr}
+
+	var pages []recognize.Page
+	switch strings.ToLower(strings.TrimSpace(strings.SplitN(req.MimeType, ";", 2)[0])) {
+	case MimeDOCX:
+		pages, err = readDOCX(ctx, pkg)
+	case MimeXLSX:
+		pages, err = readXLSX(ctx, pkg)
+	case MimePPTX:
+		pages, err = readPPTX(ctx, pkg)
+	case MimeODT:
+		pages, err = readODF(ctx, pkg, "")
+	case MimeODS:
+		pages, err = readODF(ctx, pkg, "table")
+	case MimeODP:
+		pages, err = readODF(ctx, pkg, "page")
+	default:
+		return extract.Response{}, fmt.Errorf("officetext: unsupported mime type %q", req.MimeType)
+	}
+	if err != nil {
+		return extract.Response{}, fmt.Errorf("officetext: %w", err)
+	}
+	for i := range pages {
+		pages[i].Source = recognize.SourceDocument
+	}
+	return extract.Response{Pages: pages}, nil
+}
+
+type pkg struct {
+	zr *zip.Reader
+}
+
+var errPartNotFound = errors.New("part not found")
+
+func (p *pkg) open(name string) (io.ReadCloser, error) {
+	name = strings.TrimPrefix(name, "/")
+	for _, f := range p.zr.File {
+		if f.Name == name {
+			rc, err := f.Open()
+			if err != nil {
+				return nil, fmt.Errorf("open %s: %w", name, err)
+			}
+			return struct {
+				io.Reader
+				io.Closer
+			}{io.LimitReader(rc, maxPartSize), rc}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", name, errPartNotFound)
+}
+
+// decode streams the tokens of an XML part into fn.
+func (p *pkg) decode(ctx context.Context, name string, fn func(tok xml.Token) error) error {
+	rc, err := p.open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	dec := xml.NewDecoder(rc)
+	dec.Strict = false
+	for n := 0; ; n++ {
+		if n%4096 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", name, err)
+		}
+		if err := fn(tok); err != nil {
+			return err
+		}
+	}
+}
+
+// relationships maps relationship IDs to part paths resolved against dir.
+func (p *pkg) relationships(ctx context.Context, relsPart, dir string) (map[string]string, error) {
+	rels := map[string]string{}
+	err := p.decode(ctx, relsPart, func(tok xml.Token) error {
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "Relationship" {
+			target := attr(se, "Target")
+			if !strings.HasPrefix(target, "/") {
+				target = dir + target
+			}
+			rels[attr(se, "Id")] = strings.TrimPrefix(target, "/")
+		}
+		return nil
+	})
+	return rels, err
+}
+
+// relID returns the r:id attribute, which has to be told apart from the
+// unqualified id some elements (p:sldId) carry as well.
+func relID(se xml.StartElement) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == "id" && a.Name.Space != "" {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func attr(se xml.StartElement, local string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// pageBuilder accumulates paragraphs into blocks of lines.
+type pageBuilder struct {
+	page  recognize.Page
+	block *recognize.Block
+	line  strings.Builder
+}
+
+func (b *pageBuilder) startBlock() {
+	b.endBlock()
+	b.block = &recognize.Block{}
+}
+
+func (b *pageBuilder) endLine() {
+	text := strings.TrimSpace(b.line.String())
+	b.line.Reset()
+	b.addLine(text)
+}
+
+// endRow adds a table row as tab-separated cells. Leading empty cells are
+// kept so that the columns stay in place.
+func (b *pageBuilder) endRow(cells []string) {
+	b.endLine()
+	b.addLine(strings.TrimRight(strings.Join(cells, "\t"), "\t"))
+}
+
+func (b *pageBuilder) addLine(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	if b.block == nil {
+		b.block = &recognize.Block{}
+	}
+	b.block.Lines = append(b.block.Lines, textLine(text))
+}
+
+func (b *pageBuilder) endBlock() {
+	b.endLine()
+	if b.block != nil && len(b.block.Lines) > 0 {
+		b.page.Blocks = append(b.page.Blocks, *b.block)
+	}
+	b.block = nil
+}
+
+func (b *pageBuilder) finish(label string) recognize.Page {
+	b.endBlock()
+	p := b.page
+	p.Label = label
+	b.page = recognize.Page{}
+	return p
+}
+
+func textLine(text string) recognize.Line {
+	fields := strings.Fields(text)
+	words := make([]recognize.Word, 0, len(fields))
+	for _, f := range fields {
+		words = append(words, recognize.Word{Text: f, Confidence: 1})
+	}
+	return recognize.Line{Text: text, Confidence: 1, Words: words}
+}
@@ -0,0 +1,180 @@
+package officetext
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+
+	"doc2text/internal/core/abstraction/extract"
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+const (
+	nsW = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	nsR = `xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+)
+
+// office zips parts into a package.
+func office(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func extractPages(t *testing.T, content []byte, mimeType string) []recognize.Page {
+	t.Helper()
+	res, err := New().Extract(context.Background(), extract.Request{Content: content, MimeType: mimeType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.Pages
+}
+
+// lines returns the text of every line of p, a block after another.
+func lines(p recognize.Page) []string {
+	var out []string
+	for _, b := range p.Blocks {
+		for _, l := range b.Lines {
+			out = append(out, l.Text)
+		}
+	}
+	return out
+}
+
+func equal(t *testing.T, what string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s = %q, want %q", what, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("%s = %q, want %q", what, got, want)
+		}
+	}
+}
+
+func TestDOCX(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<w:document ` + nsW + `><w:body>
+<w:p>
+  <w:pPr><w:tabs><w:tab w:val="left" w:pos="720"/><w:tab w:val="right" w:pos="9000"/></w:tabs></w:pPr>
+  <w:r><w:t>Name</w:t></w:r><w:r><w:tab/><w:t>Value</w:t></w:r>
+</w:p>
+<w:p><w:r><w:t xml:space="preserve">first </w:t></w:r><w:r><w:t>line</w:t><w:br/><w:t>second line</w:t></w:r></w:p>
+<w:p><w:pPr><w:tabs><w:tab w:val="left" w:pos="720"/></w:tabs></w:pPr></w:p>
+<w:tbl><w:tr>
+  <w:tc><w:p><w:r><w:t>cell 1</w:t></w:r></w:p></w:tc>
+  <w:tc><w:p><w:r><w:t>cell 2</w:t></w:r></w:p></w:tc>
+</w:tr></w:tbl>
+</w:body></w:document>`
+	pages := extractPages(t, office(t, map[string]string{"word/document.xml": doc}), MimeDOCX)
+	if len(pages) != 1 || pages[0].Source != recognize.SourceDocument {
+		t.Fatalf("pages = %+v", pages)
+	}
+	equal(t, "lines", lines(pages[0]), []string{"Name\tValue", "first line", "second line", "cell 1", "cell 2"})
+	if n := len(pages[0].Blocks); n != 4 {
+		t.Fatalf("%d blocks, want one per non-empty paragraph", n)
+	}
+}
+
+const (
+	workbookRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`
+	workbook = `<workbook ` + nsR + `><sheets>
+<sheet name="Summary" sheetId="1" r:id="rId2"/>
+<sheet name="Data" sheetId="2" r:id="rId1"/>
+</sheets></workbook>`
+	sharedStrings = `<sst><si><t>Total</t></si><si><r><t>Rich </t></r><r><t>text</t></r></si><si><t>東京</t><rPh><t>トウキョウ</t></rPh></si></sst>`
+)
+
+func TestXLSX(t *testing.T) {
+	data := `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1"><v>42</v></c></row>
+<row r="2"><c r="D2" t="inlineStr"><is><t>far right</t></is></c></row>
+<row r="3"><c t="s"><v>1</v></c><c t="s"><v>2</v></c></row>
+<row r="4"><c r="B4" t="s"><v>99</v></c><c r="AA4"><v>x</v></c></row>
+<row r="5"><c r="A5"/><c r="B5"><v></v></c></row>
+</sheetData></worksheet>`
+	summary := `<worksheet><sheetData><row><c><v>1</v></c></row></sheetData></worksheet>`
+	pages := extractPages(t, office(t, map[string]string{
+		"xl/workbook.xml":            workbook,
+		"xl/_rels/workbook.xml.rels": workbookRels,
+		"xl/sharedStrings.xml":       sharedStrings,
+		"xl/worksheets/sheet1.xml":   data,
+		"xl/worksheets/sheet2.xml":   summary,
+	}), MimeXLSX)
+	if len(pages) != 2 || pages[0].Label != "Summary" || pages[1].Label != "Data" {
+		t.Fatalf("pages = %+v", pages)
+	}
+	aa := "99"
+	for range 25 {
+		aa += "\t"
+	}
+	equal(t, "Data", lines(pages[1]), []string{
+		"Total\t\t42",
+		"\t\t\tfar right",
+		"Rich text\t東京",
+		// An out-of-range shared string index is kept as written.
+		"\t" + aa + "x",
+	})
+}
+
+func TestColumnIndex(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "C5": 2, "Z9": 25, "AA1": 26, "XFD1048576": 16383, "": -1, "12": -1, "ABCD1": -1} {
+		if got := columnIndex(ref); got != want {
+			t.Errorf("columnIndex(%q) = %d, want %d", ref, got, want)
+		}
+	}
+}
+
+func TestPPTX(t *testing.T) {
+	rels := `<Relationships><Relationship Id="rId7" Target="slides/slide2.xml"/><Relationship Id="rId8" Target="slides/slide1.xml"/></Relationships>`
+	pres := `<p:presentation ` + nsR + ` xmlns:p="p"><p:sldIdLst><p:sldId id="256" r:id="rId8"/><p:sldId id="257" r:id="rId7"/></p:sldIdLst></p:presentation>`
+	slide := func(shapes ...string) string {
+		s := `<p:sld xmlns:p="p" xmlns:a="a"><p:cSld><p:spTree>`
+		for _, sh := range shapes {
+			s += `<p:sp><p:txBody>` + sh + `</p:txBody></p:sp>`
+		}
+		return s + `</p:spTree></p:cSld></p:sld>`
+	}
+	pages := extractPages(t, office(t, map[string]string{
+		"ppt/presentation.xml":            pres,
+		"ppt/_rels/presentation.xml.rels": rels,
+		"ppt/slides/slide1.xml":           slide(`<a:p><a:r><a:t>Title</a:t></a:r></a:p>`, `<a:p><a:r><a:t>one</a:t></a:r><a:br/><a:r><a:t>two</a:t></a:r></a:p><a:p><a:r><a:t>three</a:t></a:r></a:p>`),
+		"ppt/slides/slide2.xml":           slide(`<a:p><a:r><a:t>Second</a:t></a:r></a:p>`),
+	}), MimePPTX)
+	if len(pages) != 2 || pages[0].Label != "slide 1" || pages[1].Label != "slide 2" {
+		t.Fatalf("pages = %+v", pages)
+	}
+	equal(t, "slide 1", lines(pages[0]), []string{"Title", "one", "two", "three"})
+	if len(pages[0].Blocks) != 2 {
+		t.Fatalf("%d blocks, want one per shape", len(pages[0].Blocks))
+	}
+	equal(t, "slide 2", lines(pages[1]), []string{"Second"})
+}
+
+func TestODSKeepsLeadingEmptyCells(t *testing.T) {
+	content := `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="` + nsTable + `" xmlns:text="` + nsText + `">
+<office:body><office:spreadsheet><table:table table:name="Sheet1">
+<table:table-row><table:table-cell table:number-columns-repeated="2"/><table:table-cell><text:p>c</text:p></table:table-cell></table:table-row>
+</table:table></office:spreadsheet></office:body></office:document-content>`
+	pages := extractPages(t, office(t, map[string]string{"content.xml": content}), MimeODS)
+	if len(pages) != 1 || pages[0].Label != "Sheet1" {
+		t.Fatalf("pages = %+v", pages)
+	}
+	equal(t, "Sheet1", lines(pages[0]), []string{"\t\tc"})
+}
@@ -0,0 +1,62 @@
+package pdfsplit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"doc2text/internal/core/abstraction/split"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+const MimePDF = "application/pdf"
+
+type splitter struct{}
+
+// New returns a splitter writing every page of a PDF as a PDF of its own,
+// with the resources that page uses.
+func New() split.Splitter {
+	// Keep pdfcpu from creating, and exiting on, a config directory.
+	model.ConfigPath = "disable"
+	return splitter{}
+}
+
+func (splitter) Split(ctx context.Context, req split.Request) (split.Response, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	conf.Cmd = model.EXTRACTPAGES
+	doc, err := api.ReadValidateAndOptimize(bytes.NewReader(req.Content), conf)
+	if err != nil {
+		return split.Response{}, fmt.Errorf("pdfsplit: read: %w", err)
+	}
+
+	pages := req.Pages
+	if len(pages) == 0 {
+		pages = make([]int, doc.PageCount)
+		for i := range pages {
+			pages[i] = i
+		}
+	}
+	res := split.Response{Pages: make([]split.Page, 0, len(pages)), Total: doc.PageCount}
+	for _, i := range pages {
+		if err := ctx.Err(); err != nil {
+			return split.Response{}, err
+		}
+		if i < 0 || i >= doc.PageCount {
+			return split.Response{}, fmt.Errorf("pdfsplit: page %d of %d", i+1, doc.PageCount)
+		}
+		r, err := api.ExtractPage(doc, i+1)
+		if err != nil {
+			return split.Response{}, fmt.Errorf("pdfsplit: page %d: %w", i+1, err)
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return split.Response{}, fmt.Errorf("pdfsplit: page %d: %w", i+1, err)
+		}
+		res.Pages = append(res.Pages, split.Page{Index: i, Content: content, MimeType: MimePDF})
+	}
+	return res, nil
+}
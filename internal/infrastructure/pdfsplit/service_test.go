@@ -0,0 +1,91 @@
+package pdfsplit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"doc2text/internal/core/abstraction/split"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// samplePDF builds a document of n pages, each showing its number.
+func samplePDF(n int) []byte {
+	var objs []string
+	kids := make([]string, n)
+	for i := range n {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	objs = append(objs,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), n),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	for i := range n {
+		text := fmt.Sprintf("BT /F1 24 Tf 72 720 Td (page %d) Tj ET", i+1)
+		objs = append(objs,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(text), text))
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objs))
+	for i, o := range objs {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+	return b.Bytes()
+}
+
+func TestSplitPages(t *testing.T) {
+	res, err := New().Split(context.Background(), split.Request{Content: samplePDF(3), MimeType: MimePDF, Pages: []int{2, 0}})
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if res.Total != 3 || len(res.Pages) != 2 {
+		t.Fatalf("Split() = %d pages of %d, want 2 of 3", len(res.Pages), res.Total)
+	}
+	for i, want := range []int{2, 0} {
+		p := res.Pages[i]
+		if p.Index != want || p.MimeType != MimePDF {
+			t.Fatalf("page %d = index %d, mime %q", i, p.Index, p.MimeType)
+		}
+		n, err := api.PageCount(bytes.NewReader(p.Content), nil)
+		if err != nil || n != 1 {
+			t.Fatalf("page %d has %d pages, %v", p.Index, n, err)
+		}
+	}
+}
+
+func TestSplitAllPages(t *testing.T) {
+	res, err := New().Split(context.Background(), split.Request{Content: samplePDF(2), MimeType: MimePDF})
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(res.Pages) != 2 || res.Pages[0].Index != 0 || res.Pages[1].Index != 1 {
+		t.Fatalf("Split() = %+v", res.Pages)
+	}
+}
+
+func TestSplitPageOutOfRange(t *testing.T) {
+	_, err := New().Split(context.Background(), split.Request{Content: samplePDF(2), MimeType: MimePDF, Pages: []int{2}})
+	if err == nil {
+		t.Fatal("Split() error = nil, want page out of range")
+	}
+}
+
+func TestSplitNotPDF(t *testing.T) {
+	_, err := New().Split(context.Background(), split.Request{Content: []byte("not a pdf"), MimeType: MimePDF})
+	if err == nil {
+		t.Fatal("Split() error = nil, want read error")
+	}
+}
@@ -0,0 +1,216 @@
+package pdftext
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"doc2text/internal/core/abstraction/extract"
+	"doc2text/internal/core/abstraction/recognize"
+
+	"github.com/ledongthuc/pdf"
+)
+
+type Options struct {
+	// MinChars is how many letters or digits a page needs before its text
+	// layer is trusted; pages below it are reported for OCR.
+	MinChars int
+}
+
+type pdfExtractor struct {
+	minChars int
+}
+
+func New(o Options) extract.Extractor {
+	if o.MinChars <= 0 {
+		o.MinChars = 1
+	}
+	return &pdfExtractor{minChars: o.MinChars}
+}
+
+func (e *pdfExtractor) Extract(ctx context.Context, req extract.Request) (res extract.Response, err error) {
+	// The PDF reader panics on malformed objects.
+	defer func() {
+		if rec := recover(); rec != nil {
+			res, err = extract.Response{}, fmt.Errorf("pdftext: %v", rec)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(req.Content), int64(len(req.Content)))
+	if err != nil {
+		return extract.Response{}, fmt.Errorf("pdftext: open: %w", err)
+	}
+
+	n := r.NumPage()
+	res = extract.Response{Pages: make([]recognize.Page, 0, n)}
+	for i := 1; i <= n; i++ {
+		if err := ctx.Err(); err != nil {
+			return extract.Response{}, err
+		}
+		page, err := readPage(r.Page(i))
+		if err != nil {
+			return extract.Response{}, fmt.Errorf("pdftext: page %d: %w", i, err)
+		}
+		if meaningfulChars(page) < e.minChars {
+			page.Blocks = nil
+			res.NeedsOCR = append(res.NeedsOCR, i-1)
+		}
+		page.Source = recognize.SourceTextLayer
+		res.Pages = append(res.Pages, page)
+	}
+	return res, nil
+}
+
+type glyph struct {
+	x, y, w, size float64
+	s             string
+}
+
+// readPage lays the page glyphs out into lines and words. PDF coordinates are
+// bottom-up; boxes are flipped so the origin is the top-left corner like OCR
+// results.
+func readPage(p pdf.Page) (page recognize.Page, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%v", rec)
+		}
+	}()
+
+	width, height := pageSize(p)
+	page = recognize.Page{Width: int64(width), Height: int64(height)}
+
+	var glyphs []glyph
+	for _, t := range p.Content().Text {
+		glyphs = append(glyphs, glyph{x: t.X, y: t.Y, w: t.W, size: t.FontSize, s: t.S})
+	}
+	if len(glyphs) == 0 {
+		return page, nil
+	}
+
+	var rows [][]glyph
+	sort.SliceStable(glyphs, func(i, j int) bool { return glyphs[i].y > glyphs[j].y })
+	for _, g := range glyphs {
+		if n := len(rows); n > 0 {
+			last := rows[n-1][0]
+			if math.Abs(last.y-g.y) <= math.Max(last.size, g.size)/2 {
+				rows[n-1] = append(rows[n-1], g)
+				continue
+			}
+		}
+		rows = append(rows, []glyph{g})
+	}
+
+	block := recognize.Block{}
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool { return row[i].x < row[j].x })
+		line := buildLine(row, height)
+		if line.Text != "" {
+			block.Lines = append(block.Lines, line)
+		}
+	}
+	if len(block.Lines) > 0 {
+		block.BoundingBox = bounds(block.Lines)
+		page.Blocks = []recognize.Block{block}
+	}
+	return page, nil
+}
+
+func buildLine(row []glyph, pageHeight float64) recognize.Line {
+	var (
+		line  recognize.Line
+		word  strings.Builder
+		wl    float64
+		wr    float64
+		wt    float64
+		wb    float64
+		prevR = math.Inf(-1)
+	)
+	flush := func() {
+		if word.Len() == 0 {
+			return
+		}
+		line.Words = append(line.Words, recognize.Word{
+			Text:        word.String(),
+			BoundingBox: rect(wl, wt, wr, wb),
+			Confidence:  1,
+		})
+		word.Reset()
+	}
+
+	for _, g := range row {
+		top, bottom := pageHeight-(g.y+g.size), pageHeight-g.y
+		if strings.TrimSpace(g.s) == "" || (word.Len() > 0 && g.x-prevR > g.size*0.25) {
+			flush()
+		}
+		prevR = g.x + g.w
+		if strings.TrimSpace(g.s) == "" {
+			continue
+		}
+		if word.Len() == 0 {
+			wl, wt, wb = g.x, top, bottom
+		}
+		word.WriteString(g.s)
+		wr = g.x + g.w
+		wt, wb = math.Min(wt, top), math.Max(wb, bottom)
+	}
+	flush()
+
+	texts := make([]string, 0, len(line.Words))
+	for _, w := range line.Words {
+		texts = append(texts, w.Text)
+	}
+	line.Text = strings.Join(texts, " ")
+	line.Confidence = 1
+	if len(line.Words) > 0 {
+		first, last := line.Words[0].BoundingBox.Vertices, line.Words[len(line.Words)-1].BoundingBox.Vertices
+		line.BoundingBox = recognize.Polygon{Vertices: []recognize.Point{
+			first[0], first[1], last[2], last[3],
+		}}
+	}
+	return line
+}
+
+func pageSize(p pdf.Page) (float64, float64) {
+	for v := p.V; !v.IsNull(); v = v.Key("Parent") {
+		box := v.Key("MediaBox")
+		if box.Len() == 4 {
+			return box.Index(2).Float64() - box.Index(0).Float64(), box.Index(3).Float64() - box.Index(1).Float64()
+		}
+	}
+	// US Letter is the PDF default user space.
+	return 612, 792
+}
+
+func rect(l, t, r, b float64) recognize.Polygon {
+	li, ti, ri, bi := int64(l), int64(t), int64(math.Ceil(r)), int64(math.Ceil(b))
+	return recognize.Polygon{Vertices: []recognize.Point{{X: li, Y: ti}, {X: li, Y: bi}, {X: ri, Y: bi}, {X: ri, Y: ti}}}
+}
+
+func bounds(lines []recognize.Line) recognize.Polygon {
+	l, t := int64(math.MaxInt64), int64(math.MaxInt64)
+	r, b := int64(math.MinInt64), int64(math.MinInt64)
+	for _, ln := range lines {
+		for _, v := range ln.BoundingBox.Vertices {
+			l, t, r, b = min(l, v.X), min(t, v.Y), max(r, v.X), max(b, v.Y)
+		}
+	}
+	return recognize.Polygon{Vertices: []recognize.Point{{X: l, Y: t}, {X: l, Y: b}, {X: r, Y: b}, {X: r, Y: t}}}
+}
+
+func meaningfulChars(p recognize.Page) int {
+	n := 0
+	for _, b := range p.Blocks {
+		for _, ln := range b.Lines {
+			for _, ch := range ln.Text {
+				if unicode.IsLetter(ch) || unicode.IsDigit(ch) {
+					n++
+				}
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,107 @@
+package plaintext
+
+import (
+	"bytes"
+	"mime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+// guessCandidates are tried, in order of preference, for 8-bit text that
+// does not declare its charset. Our traffic is mostly Russian.
+var guessCandidates = []encoding.Encoding{charmap.Windows1251, charmap.KOI8R, charmap.CodePage866}
+
+// decodeText converts content to UTF-8. A BOM wins, then the declared
+// charset (a label or a Content-Type with a charset parameter), then valid
+// UTF-8, and finally a guess among the single-byte Cyrillic code pages.
+func decodeText(content []byte, declared string) string {
+	if enc := bomEncoding(content); enc != nil {
+		if s, err := enc.NewDecoder().Bytes(content); err == nil {
+			return string(s)
+		}
+	}
+	// A declared UTF-8 that does not validate is a mislabelled legacy
+	// charset more often than not, so it goes to the guess below.
+	if enc := lookupCharset(declared); enc != nil && (enc != xunicode.UTF8 || utf8.Valid(content)) {
+		if s, err := enc.NewDecoder().Bytes(content); err == nil {
+			return string(s)
+		}
+	}
+	if utf8.Valid(content) {
+		return string(content)
+	}
+	return guessCharset(content)
+}
+
+func bomEncoding(content []byte) encoding.Encoding {
+	switch {
+	case bytes.HasPrefix(content, []byte{0xEF, 0xBB, 0xBF}):
+		return xunicode.UTF8BOM
+	case bytes.HasPrefix(content, []byte{0xFF, 0xFE}):
+		return xunicode.UTF16(xunicode.LittleEndian, xunicode.ExpectBOM)
+	case bytes.HasPrefix(content, []byte{0xFE, 0xFF}):
+		return xunicode.UTF16(xunicode.BigEndian, xunicode.ExpectBOM)
+	}
+	return nil
+}
+
+// lookupCharset accepts either a bare label ("koi8-r") or a media type
+// carrying a charset parameter ("text/plain; charset=cp1251").
+func lookupCharset(declared string) encoding.Encoding {
+	declared = strings.TrimSpace(declared)
+	if declared == "" {
+		return nil
+	}
+	if strings.Contains(declared, "/") {
+		_, params, err := mime.ParseMediaType(declared)
+		if err != nil {
+			return nil
+		}
+		declared = params["charset"]
+	}
+	if declared == "" {
+		return nil
+	}
+	enc, err := htmlindex.Get(declared)
+	if err != nil {
+		return nil
+	}
+	return enc
+}
+
+// guessCharset picks the candidate whose decoding looks most like prose:
+// Cyrillic letters, mostly lower case. Decoding KOI8-R text as windows-1251
+// (and vice versa) swaps the cases, which is what tells them apart.
+func guessCharset(content []byte) string {
+	best, bestScore := "", -1<<31
+	for _, enc := range guessCandidates {
+		s, err := enc.NewDecoder().Bytes(content)
+		if err != nil {
+			continue
+		}
+		score := 0
+		for _, r := range string(s) {
+			switch {
+			case unicode.Is(unicode.Cyrillic, r) && unicode.IsLower(r):
+				score += 2
+			case unicode.Is(unicode.Cyrillic, r):
+				score++
+			case r >= 0x80 && !unicode.IsLetter(r):
+				score -= 2
+			}
+		}
+		if score > bestScore {
+			best, bestScore = string(s), score
+		}
+	}
+	if bestScore == -1<<31 {
+		return strings.ToValidUTF8(string(content), "�")
+	}
+	return best
+}
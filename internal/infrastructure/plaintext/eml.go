@@ -0,0 +1,293 @@
+package plaintext
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+
+	"doc2text/internal/core/abstraction/extract"
+	"doc2text/internal/core/abstraction/recognize"
+	"doc2text/internal/infrastructure/mimetypes"
+)
+
+const (
+	// maxDepth bounds nesting of multiparts and forwarded messages.
+	maxDepth = 8
+	// maxParts bounds how many MIME parts a message may have.
+	maxParts = 1000
+	// maxPartSize caps a single decoded part.
+	maxPartSize = 64 << 20
+)
+
+var headerDecoder = &mime.WordDecoder{
+	CharsetReader: func(label string, input io.Reader) (io.Reader, error) {
+		enc := lookupCharset(label)
+		if enc == nil {
+			return nil, fmt.Errorf("unknown charset %q", label)
+		}
+		return enc.NewDecoder().Reader(input), nil
+	},
+}
+
+var shownHeaders = []string{"From", "To", "Cc", "Date", "Subject"}
+
+type attachment struct {
+	name     string
+	mimeType string
+	data     []byte
+}
+
+// message collects what walking a MIME tree yields.
+type message struct {
+	body        []string
+	attachments []attachment
+	parts       int
+}
+
+// readMessage returns the headers and body as the first page, followed by
+// the pages of every attachment that can be read without OCR, labelled with
+// the attachment's file name.
+func (e *textExtractor) readMessage(ctx context.Context, content []byte, depth int) ([]recognize.Page, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("message nested deeper than %d levels", maxDepth)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("parse message: %w", err)
+	}
+
+	m := &message{}
+	if err := m.walk(textproto.MIMEHeader(msg.Header), msg.Body, depth); err != nil {
+		return nil, err
+	}
+
+	var head strings.Builder
+	for _, name := range shownHeaders {
+		if v := msg.Header.Get(name); v != "" {
+			if dec, err := headerDecoder.DecodeHeader(v); err == nil {
+				v = dec
+			}
+			fmt.Fprintf(&head, "%s: %s\n", name, v)
+		}
+	}
+	if len(m.attachments) > 0 {
+		names := make([]string, 0, len(m.attachments))
+		for _, a := range m.attachments {
+			names = append(names, a.name)
+		}
+		fmt.Fprintf(&head, "Attachments: %s\n", strings.Join(names, ", "))
+	}
+
+	text := head.String() + "\n" + strings.Join(m.body, "\n\n")
+	page := recognize.Page{Label: "message"}
+	for _, p := range textPages(strings.ReplaceAll(text, "\f", "\n")) {
+		page.Blocks = append(page.Blocks, p.Blocks...)
+	}
+	pages := []recognize.Page{page}
+
+	for _, a := range m.attachments {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, p := range e.readAttachment(ctx, a, depth) {
+			if p.Label == "" {
+				p.Label = a.name
+			} else {
+				p.Label = a.name + ": " + p.Label
+			}
+			pages = append(pages, p)
+		}
+	}
+	return pages, nil
+}
+
+// readAttachment extracts what it can and skips the rest: an unreadable
+// attachment should not cost the message its text.
+func (e *textExtractor) readAttachment(ctx context.Context, a attachment, depth int) []recognize.Page {
+	if pages, err := e.extract(ctx, a.data, a.mimeType, depth+1); err == nil {
+		return pages
+	}
+	mimeType := baseMimeType(a.mimeType)
+	if mimeType == mimetypes.Default {
+		if byExt := mimetypes.ByExtension(a.name); byExt != "" {
+			mimeType = baseMimeType(byExt)
+			if pages, err := e.extract(ctx, a.data, mimeType, depth+1); err == nil {
+				return pages
+			}
+		}
+	}
+	// Formats read here already failed above. Handing them to the
+	// attachment extractors could reach this extractor again at depth 0
+	// and lift the nesting limit.
+	if slices.Contains(MimeTypes, mimeType) {
+		return nil
+	}
+	ex, ok := e.attachments[mimeType]
+	if !ok {
+		return nil
+	}
+	res, err := ex.Extract(ctx, extract.Request{Content: a.data, MimeType: mimeType})
+	if err != nil {
+		return nil
+	}
+	// Pages that need OCR cannot be sent on their own, so they are dropped.
+	skip := make(map[int]bool, len(res.NeedsOCR))
+	for _, i := range res.NeedsOCR {
+		skip[i] = true
+	}
+	var pages []recognize.Page
+	for i, p := range res.Pages {
+		if !skip[i] {
+			pages = append(pages, p)
+		}
+	}
+	return pages
+}
+
+func (m *message) walk(h textproto.MIMEHeader, body io.Reader, depth int) error {
+	m.parts++
+	if m.parts > maxParts {
+		return fmt.Errorf("message has more than %d parts", maxParts)
+	}
+	if depth > maxDepth {
+		return fmt.Errorf("multipart nested deeper than %d levels", maxDepth)
+	}
+
+	ctype := h.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(ctype)
+	if err != nil {
+		mediaType, params = MimeText, nil
+		ctype = MimeText
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		if mediaType == "multipart/alternative" {
+			return m.walkAlternative(mr, depth)
+		}
+		for {
+			p, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("read multipart: %w", err)
+			}
+			if err := m.walk(p.Header, p, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(decodeTransfer(h.Get("Content-Transfer-Encoding"), body), maxPartSize))
+	if err != nil {
+		return fmt.Errorf("read part: %w", err)
+	}
+
+	name := partFileName(h, params)
+	disposition, _, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	inline := disposition != "attachment" && name == ""
+	switch {
+	case inline && mediaType == MimeText:
+		m.body = append(m.body, decodeText(data, ctype))
+	case inline && mediaType == MimeHTML:
+		text, err := htmlText(data, ctype)
+		if err != nil {
+			return err
+		}
+		m.body = append(m.body, text)
+	case mediaType == MimeEML || name != "":
+		if name == "" {
+			name = "message.eml"
+		}
+		m.attachments = append(m.attachments, attachment{name: name, mimeType: ctype, data: data})
+	}
+	return nil
+}
+
+// walkAlternative keeps one rendering of the body, preferring text/plain.
+func (m *message) walkAlternative(mr *multipart.Reader, depth int) error {
+	var plain, other *message
+	for {
+		p, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read multipart: %w", err)
+		}
+		alt := &message{parts: m.parts}
+		if err := alt.walk(p.Header, p, depth+1); err != nil {
+			return err
+		}
+		m.parts = alt.parts
+		mediaType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		switch {
+		case mediaType == MimeText && plain == nil:
+			plain = alt
+		case other == nil && len(alt.body) > 0:
+			other = alt
+		}
+	}
+	chosen := plain
+	if chosen == nil {
+		chosen = other
+	}
+	if chosen != nil {
+		m.body = append(m.body, chosen.body...)
+		m.attachments = append(m.attachments, chosen.attachments...)
+	}
+	return nil
+}
+
+func decodeTransfer(cte string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(cte)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &base64Cleaner{r: r})
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+func partFileName(h textproto.MIMEHeader, ctypeParams map[string]string) string {
+	name := ""
+	if _, params, err := mime.ParseMediaType(h.Get("Content-Disposition")); err == nil {
+		name = params["filename"]
+	}
+	if name == "" {
+		name = ctypeParams["name"]
+	}
+	if dec, err := headerDecoder.DecodeHeader(name); err == nil {
+		name = dec
+	}
+	return name
+}
+
+// base64Cleaner drops whitespace, which base64.NewDecoder only tolerates
+// as CR and LF.
+type base64Cleaner struct {
+	r io.Reader
+}
+
+func (c *base64Cleaner) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	j := 0
+	for _, b := range p[:n] {
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			p[j] = b
+			j++
+		}
+	}
+	return j, err
+}
@@ -0,0 +1,160 @@
+package plaintext
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// htmlText renders an HTML document as text, keeping paragraphs, headings,
+// list items and table rows on lines of their own.
+func htmlText(content []byte, contentType string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(decodeHTML(content, contentType)))
+	if err != nil {
+		return "", fmt.Errorf("parse html: %w", err)
+	}
+	w := &htmlWriter{}
+	w.walk(doc)
+	return w.b.String(), nil
+}
+
+// decodeHTML honours a BOM, the Content-Type charset and a <meta> charset.
+// Without any of them it falls back to decodeText's guess instead of the
+// windows-1252 default browsers use.
+func decodeHTML(content []byte, contentType string) string {
+	if bomEncoding(content) != nil || lookupCharset(contentType) != nil {
+		return decodeText(content, contentType)
+	}
+	enc, name, _ := charset.DetermineEncoding(content, "")
+	if name == "windows-1252" {
+		head := bytes.ToLower(content[:min(len(content), 1024)])
+		if !bytes.Contains(head, []byte("1252")) && !bytes.Contains(head, []byte("8859-1")) {
+			return decodeText(content, "")
+		}
+	}
+	s, err := enc.NewDecoder().Bytes(content)
+	if err != nil {
+		return decodeText(content, "")
+	}
+	return string(s)
+}
+
+type htmlWriter struct {
+	b     strings.Builder
+	brk   int  // pending line breaks, flushed before the next text
+	space bool // pending space between inline runs
+	pre   int
+}
+
+var skipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Iframe: true, atom.Object: true, atom.Svg: true,
+}
+
+// blocks start a new paragraph; rows start a new line.
+var blocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true, atom.Ul: true, atom.Ol: true,
+	atom.Dl: true, atom.Table: true, atom.Blockquote: true, atom.Pre: true,
+	atom.Section: true, atom.Article: true, atom.Header: true, atom.Footer: true,
+	atom.Nav: true, atom.Aside: true, atom.Main: true, atom.Figure: true,
+	atom.Form: true, atom.Fieldset: true, atom.Address: true, atom.Hr: true,
+	atom.Caption: true,
+}
+
+var rows = map[atom.Atom]bool{
+	atom.Li: true, atom.Tr: true, atom.Dt: true, atom.Dd: true, atom.Figcaption: true,
+}
+
+func (w *htmlWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+		if skipped[n.DataAtom] {
+			return
+		}
+	}
+
+	switch {
+	case blocks[n.DataAtom]:
+		w.breakLines(2)
+	case rows[n.DataAtom]:
+		w.breakLines(1)
+	case n.DataAtom == atom.Br:
+		if w.b.Len() > 0 {
+			w.brk = min(w.brk+1, 2)
+		}
+	case n.DataAtom == atom.Td || n.DataAtom == atom.Th:
+		if n.PrevSibling != nil && w.b.Len() > 0 && w.brk == 0 {
+			w.b.WriteByte('\t')
+			w.space = false
+		}
+	}
+
+	if n.DataAtom == atom.Pre {
+		w.pre++
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+	if n.DataAtom == atom.Pre {
+		w.pre--
+	}
+
+	switch {
+	case blocks[n.DataAtom]:
+		w.breakLines(2)
+	case rows[n.DataAtom]:
+		w.breakLines(1)
+	}
+}
+
+func (w *htmlWriter) breakLines(n int) {
+	if w.b.Len() > 0 {
+		w.brk = max(w.brk, n)
+	}
+}
+
+func (w *htmlWriter) text(s string) {
+	if w.pre > 0 {
+		w.flush()
+		w.b.WriteString(s)
+		return
+	}
+	w.space = w.space || startsWithSpace(s)
+	fields := strings.Fields(s)
+	for _, f := range fields {
+		if w.brk > 0 {
+			w.flush()
+		} else if w.space && w.b.Len() > 0 {
+			w.b.WriteByte(' ')
+		}
+		w.b.WriteString(f)
+		w.space = true
+	}
+	if len(fields) > 0 {
+		w.space = endsWithSpace(s)
+	}
+}
+
+func (w *htmlWriter) flush() {
+	if w.brk > 0 {
+		w.b.WriteString(strings.Repeat("\n", w.brk))
+		w.brk = 0
+	}
+	w.space = false
+}
+
+func startsWithSpace(s string) bool {
+	return s != "" && strings.TrimLeft(s, " \t\r\n\f") != s
+}
+
+func endsWithSpace(s string) bool {
+	return s != "" && strings.TrimRight(s, " \t\r\n\f") != s
+}
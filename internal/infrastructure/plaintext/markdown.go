@@ -0,0 +1,88 @@
+package plaintext
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mdImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink       = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	mdRefLink    = regexp.MustCompile(`\[([^\]]+)\]\[[^\]]*\]`)
+	mdRefDef     = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s+\S+`)
+	mdAutolink   = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	mdHTMLTag    = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	mdHeading    = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	mdHeadingEnd = regexp.MustCompile(`\s+#+\s*$`)
+	mdSetext     = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	mdRule       = regexp.MustCompile(`^\s{0,3}([-*_])(\s*([-*_])){2,}\s*$`)
+	mdQuote      = regexp.MustCompile(`^\s{0,3}(>\s?)+`)
+	mdList       = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(\[[ xX]\]\s+)?`)
+	mdTableSep   = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdEmphasis   = regexp.MustCompile(`(\*\*\*|\*\*|\*|___|__|~~)([^\s*_~](?:.*?[^\s*_~])?)(\*\*\*|\*\*|\*|___|__|~~)`)
+	mdCode       = regexp.MustCompile("`+([^`]*)`+")
+)
+
+// stripMarkdown drops Markdown syntax and keeps the text: headings, list
+// items and table rows stay on their own lines, fenced code is kept as is.
+func stripMarkdown(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	lines := strings.Split(src, "\n")
+	out := make([]string, 0, len(lines))
+	fence := ""
+	for _, l := range lines {
+		trimmed := strings.TrimSpace(l)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				continue
+			}
+			out = append(out, l)
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		switch {
+		case mdRefDef.MatchString(l), mdRule.MatchString(l), mdTableSep.MatchString(l) && strings.Contains(l, "-") && strings.Contains(l, "|"):
+			out = append(out, "")
+			continue
+		case mdSetext.MatchString(l) && len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "":
+			continue
+		}
+
+		if mdHeading.MatchString(l) {
+			l = mdHeadingEnd.ReplaceAllString(mdHeading.ReplaceAllString(l, ""), "")
+		}
+		l = mdQuote.ReplaceAllString(l, "")
+		l = mdList.ReplaceAllString(l, "$1")
+		if strings.Count(l, "|") >= 2 {
+			cells := strings.Split(strings.Trim(strings.TrimSpace(l), "|"), "|")
+			for i := range cells {
+				cells[i] = strings.TrimSpace(cells[i])
+			}
+			l = strings.Join(cells, "\t")
+		}
+		l = inlineMarkdown(l)
+		out = append(out, l)
+	}
+	return strings.Join(out, "\n")
+}
+
+func inlineMarkdown(l string) string {
+	l = mdImage.ReplaceAllString(l, "$1")
+	l = mdLink.ReplaceAllString(l, "$1")
+	l = mdRefLink.ReplaceAllString(l, "$1")
+	l = mdAutolink.ReplaceAllString(l, "$1")
+	l = mdCode.ReplaceAllString(l, "$1")
+	l = mdHTMLTag.ReplaceAllString(l, "")
+	for range 3 {
+		next := mdEmphasis.ReplaceAllString(l, "$2")
+		if next == l {
+			break
+		}
+		l = next
+	}
+	return strings.ReplaceAll(l, `\`, "")
+}
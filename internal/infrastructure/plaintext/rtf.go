@@ -0,0 +1,242 @@
+package plaintext
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// rtfDestinations are groups whose content is not document text.
+var rtfDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true,
+	"pict": true, "object": true, "header": true, "footer": true,
+	"headerl": true, "headerr": true, "headerf": true, "footerl": true,
+	"footerr": true, "footerf": true, "listtable": true, "listoverridetable": true,
+	"revtbl": true, "rsidtbl": true, "generator": true, "xmlnstbl": true,
+	"themedata": true, "colorschememapping": true, "datastore": true,
+	"latentstyles": true, "fldinst": true, "bkmkstart": true, "bkmkend": true,
+}
+
+var rtfCodePages = map[int]encoding.Encoding{
+	866:   charmap.CodePage866,
+	1250:  charmap.Windows1250,
+	1251:  charmap.Windows1251,
+	1252:  charmap.Windows1252,
+	20866: charmap.KOI8R,
+	21866: charmap.KOI8U,
+}
+
+type rtfGroup struct {
+	skip bool
+	uc   int // bytes to skip after \uN
+}
+
+// rtfText strips RTF markup. Hex escapes (\'hh) are decoded with the
+// document's \ansicpg (windows-1251 unless declared otherwise), \uN with
+// its \ucN fallback skipping, and paragraph and cell controls become line
+// breaks and tabs.
+func rtfText(content []byte) string {
+	var (
+		out    strings.Builder
+		raw    []byte // pending \'hh bytes
+		stack  []rtfGroup
+		cur                      = rtfGroup{uc: 1}
+		enc    encoding.Encoding = charmap.Windows1251
+		skipN  int
+		surr   rune
+		newGrp bool
+	)
+	flushRaw := func() {
+		if len(raw) == 0 {
+			return
+		}
+		if s, err := enc.NewDecoder().Bytes(raw); err == nil {
+			out.Write(s)
+		}
+		raw = raw[:0]
+	}
+	emit := func(s string) {
+		flushRaw()
+		if !cur.skip {
+			out.WriteString(s)
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '{':
+			stack = append(stack, cur)
+			newGrp = true
+			skipN = 0
+			continue
+		case '}':
+			flushRaw()
+			if len(stack) > 0 {
+				cur = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+			newGrp = false
+			skipN = 0
+			continue
+		case '\r', '\n':
+			continue
+		case '\\':
+		default:
+			newGrp = false
+			if skipN > 0 {
+				skipN--
+				continue
+			}
+			if !cur.skip {
+				flushRaw()
+				out.WriteByte(c)
+			}
+			continue
+		}
+
+		// Control symbol or word.
+		if i+1 >= len(content) {
+			break
+		}
+		next := content[i+1]
+		switch {
+		case next == '\'':
+			if i+3 < len(content) {
+				if b, err := strconv.ParseUint(string(content[i+2:i+4]), 16, 8); err == nil {
+					if skipN > 0 {
+						skipN--
+					} else if !cur.skip {
+						raw = append(raw, byte(b))
+					}
+				}
+			}
+			i += 3
+			newGrp = false
+			continue
+		case next == '*':
+			if newGrp {
+				cur.skip = true
+			}
+			i++
+			continue
+		case next == '\\' || next == '{' || next == '}':
+			emit(string(next))
+			i++
+			newGrp = false
+			continue
+		case next == '~':
+			emit(" ")
+			i++
+			continue
+		case next == '-':
+			i++
+			continue
+		case next == '_':
+			emit("-")
+			i++
+			continue
+		case next == '\r' || next == '\n':
+			emit("\n")
+			i++
+			continue
+		case !isASCIILetter(next):
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(content) && isASCIILetter(content[j]) {
+			j++
+		}
+		word := string(content[i+1 : j])
+		k := j
+		if k < len(content) && (content[k] == '-' || isDigit(content[k])) {
+			k++
+			for k < len(content) && isDigit(content[k]) {
+				k++
+			}
+		}
+		param, hasParam := 0, k > j
+		if hasParam {
+			param, _ = strconv.Atoi(string(content[j:k]))
+		}
+		if k < len(content) && content[k] == ' ' {
+			k++
+		}
+		i = k - 1
+
+		if newGrp {
+			newGrp = false
+			if rtfDestinations[word] {
+				cur.skip = true
+				continue
+			}
+		}
+		switch word {
+		case "ansicpg":
+			if e, ok := rtfCodePages[param]; ok {
+				flushRaw()
+				enc = e
+			}
+		case "uc":
+			cur.uc = param
+		case "u":
+			r := rune(param)
+			if r < 0 {
+				r += 0x10000
+			}
+			switch {
+			case utf16.IsSurrogate(r) && surr == 0:
+				surr = r
+			case surr != 0:
+				emit(string(utf16.DecodeRune(surr, r)))
+				surr = 0
+			default:
+				emit(string(r))
+			}
+			skipN = cur.uc
+		case "par", "line", "sect":
+			emit("\n")
+		case "page":
+			emit("\f")
+		case "tab":
+			emit("\t")
+		case "cell", "nestcell":
+			emit("\t")
+		case "row", "nestrow":
+			emit("\n")
+		case "emdash":
+			emit("—")
+		case "endash":
+			emit("–")
+		case "bullet":
+			emit("•")
+		case "lquote":
+			emit("‘")
+		case "rquote":
+			emit("’")
+		case "ldblquote":
+			emit("“")
+		case "rdblquote":
+			emit("”")
+		case "bin":
+			if hasParam && param > 0 {
+				i += param
+			}
+		}
+	}
+	flushRaw()
+	return out.String()
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
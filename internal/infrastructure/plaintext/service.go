@@ -0,0 +1,113 @@
+package plaintext
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"doc2text/internal/core/abstraction/extract"
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+const (
+	MimeText      = "text/plain"
+	MimeHTML      = "text/html"
+	MimeXHTML     = "application/xhtml+xml"
+	MimeRTF       = "application/rtf"
+	MimeTextRTF   = "text/rtf"
+	MimeMarkdown  = "text/markdown"
+	MimeXMarkdown = "text/x-markdown"
+	MimeEML       = "message/rfc822"
+)
+
+// MimeTypes lists the formats the extractor understands.
+var MimeTypes = []string{MimeText, MimeHTML, MimeXHTML, MimeRTF, MimeTextRTF, MimeMarkdown, MimeXMarkdown, MimeEML}
+
+type Options struct {
+	// Attachments are consulted for e-mail attachments this package does
+	// not read itself (PDF, office documents). May be nil.
+	Attachments map[string]extract.Extractor
+}
+
+type textExtractor struct {
+	attachments map[string]extract.Extractor
+}
+
+func New(o Options) extract.Extractor {
+	return &textExtractor{attachments: o.Attachments}
+}
+
+func (e *textExtractor) Extract(ctx context.Context, req extract.Request) (extract.Response, error) {
+	pages, err := e.extract(ctx, req.Content, req.MimeType, 0)
+	if err != nil {
+		return extract.Response{}, fmt.Errorf("plaintext: %w", err)
+	}
+	for i := range pages {
+		pages[i].Source = recognize.SourceDocument
+	}
+	return extract.Response{Pages: pages}, nil
+}
+
+func (e *textExtractor) extract(ctx context.Context, content []byte, mimeType string, depth int) ([]recognize.Page, error) {
+	switch baseMimeType(mimeType) {
+	case MimeText:
+		return textPages(decodeText(content, mimeType)), nil
+	case MimeMarkdown, MimeXMarkdown:
+		return textPages(stripMarkdown(decodeText(content, mimeType))), nil
+	case MimeHTML, MimeXHTML:
+		text, err := htmlText(content, mimeType)
+		if err != nil {
+			return nil, err
+		}
+		return textPages(text), nil
+	case MimeRTF, MimeTextRTF:
+		return textPages(rtfText(content)), nil
+	case MimeEML:
+		return e.readMessage(ctx, content, depth)
+	default:
+		return nil, fmt.Errorf("unsupported mime type %q", mimeType)
+	}
+}
+
+// textPages turns normalized text into pages: form feeds separate pages,
+// blank lines separate blocks.
+func textPages(text string) []recognize.Page {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var pages []recognize.Page
+	for _, chunk := range strings.Split(text, "\f") {
+		var page recognize.Page
+		var block recognize.Block
+		flush := func() {
+			if len(block.Lines) > 0 {
+				page.Blocks = append(page.Blocks, block)
+			}
+			block = recognize.Block{}
+		}
+		for _, l := range strings.Split(chunk, "\n") {
+			l = strings.TrimRight(l, " \t")
+			if strings.TrimSpace(l) == "" {
+				flush()
+				continue
+			}
+			block.Lines = append(block.Lines, textLine(l))
+		}
+		flush()
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+func textLine(text string) recognize.Line {
+	fields := strings.Fields(text)
+	words := make([]recognize.Word, 0, len(fields))
+	for _, f := range fields {
+		words = append(words, recognize.Word{Text: f, Confidence: 1})
+	}
+	return recognize.Line{Text: text, Confidence: 1, Words: words}
+}
+
+func baseMimeType(mimeType string) string {
+	return strings.ToLower(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0]))
+}
@@ -0,0 +1,123 @@
+package plaintext
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"doc2text/internal/core/abstraction/extract"
+	"doc2text/internal/core/abstraction/recognize"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func pagesText(pages []recognize.Page) string {
+	return strings.TrimSpace(recognize.JoinText(pages))
+}
+
+// nestedMessage forwards a message levels times.
+func nestedMessage(levels int) string {
+	msg := "Subject: level 0\r\nContent-Type: text/plain\r\n\r\nbody 0\r\n"
+	for i := 1; i <= levels; i++ {
+		msg = fmt.Sprintf("Subject: level %d\r\nContent-Type: multipart/mixed; boundary=b%d\r\n\r\n"+
+			"--b%d\r\nContent-Type: text/plain\r\n\r\nbody %d\r\n"+
+			"--b%d\r\nContent-Type: message/rfc822\r\n\r\n%s\r\n--b%d--\r\n", i, i, i, i, i, msg, i)
+	}
+	return msg
+}
+
+// countingExtractor counts the attachments handed to it.
+type countingExtractor struct {
+	next  extract.Extractor
+	calls int
+}
+
+func (c *countingExtractor) Extract(ctx context.Context, req extract.Request) (extract.Response, error) {
+	c.calls++
+	return c.next.Extract(ctx, req)
+}
+
+func TestNestedMessageDepth(t *testing.T) {
+	// As in main, the extractor is also registered for its own attachments.
+	attachments := map[string]extract.Extractor{}
+	ex := New(Options{Attachments: attachments})
+	self := &countingExtractor{next: ex}
+	attachments[MimeEML] = self
+
+	res, err := ex.Extract(context.Background(), extract.Request{Content: []byte(nestedMessage(maxDepth + 5)), MimeType: MimeEML})
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if self.calls != 0 {
+		t.Fatalf("nested message handed to the attachment extractors %d times", self.calls)
+	}
+	text := pagesText(res.Pages)
+	if !strings.Contains(text, fmt.Sprintf("body %d", maxDepth+5)) {
+		t.Fatalf("outer body missing: %q", text)
+	}
+	if strings.Contains(text, "body 0") {
+		t.Fatalf("message below the depth limit was read: %q", text)
+	}
+}
+
+func TestTooManyParts(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("Subject: parts\r\nContent-Type: multipart/mixed; boundary=x\r\n\r\n")
+	for range maxParts + 1 {
+		b.WriteString("--x\r\nContent-Type: text/plain\r\n\r\nhi\r\n")
+	}
+	b.WriteString("--x--\r\n")
+
+	_, err := New(Options{}).Extract(context.Background(), extract.Request{Content: []byte(b.String()), MimeType: MimeEML})
+	if err == nil || !strings.Contains(err.Error(), "parts") {
+		t.Fatalf("Extract() error = %v, want too many parts", err)
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	const text = "Привет, мир! Съешь же ещё этих мягких французских булок"
+	encode := func(enc *charmap.Charmap) []byte {
+		b, err := enc.NewEncoder().Bytes([]byte(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	for name, tc := range map[string]struct {
+		content  []byte
+		declared string
+	}{
+		"utf-8":              {[]byte(text), ""},
+		"utf-8 bom":          {append([]byte{0xEF, 0xBB, 0xBF}, text...), "text/plain; charset=koi8-r"},
+		"declared koi8-r":    {encode(charmap.KOI8R), "text/plain; charset=koi8-r"},
+		"declared label":     {encode(charmap.CodePage866), "ibm866"},
+		"guessed cp1251":     {encode(charmap.Windows1251), ""},
+		"guessed koi8-r":     {encode(charmap.KOI8R), ""},
+		"mislabelled utf-8":  {encode(charmap.Windows1251), "text/plain; charset=utf-8"},
+		"guessed cp866":      {encode(charmap.CodePage866), ""},
+		"unknown charset ok": {[]byte(text), "text/plain; charset=x-unknown"},
+	} {
+		if got := decodeText(tc.content, tc.declared); got != text {
+			t.Errorf("%s: decodeText() = %q", name, got)
+		}
+	}
+}
+
+func TestRTF(t *testing.T) {
+	for name, tc := range map[string]struct {
+		rtf  string
+		want string
+	}{
+		"cp1251 escapes": {`{\rtf1\ansi\ansicpg1251{\fonttbl{\f0 Arial;}}\f0 \'cf\'f0\'e8\'e2\'e5\'f2\par world}`, "Привет\nworld"},
+		"koi8-r escapes": {`{\rtf1\ansi\ansicpg20866 \'f0\'d2\'c9\'d7\'c5\'d4}`, "Привет"},
+		"unicode":        {`{\rtf1\uc1 \u1052?\u1080?\u1088? ok}`, "Мир ok"},
+		"skipped groups": {`{\rtf1{\info{\title secret}}{\*\generator x;}visible}`, "visible"},
+		"cells":          {`{\rtf1 a\cell b\cell\row}`, "a\tb"},
+		"escaped braces": {`{\rtf1 \{x\} \\}`, `{x} \`},
+	} {
+		if got := strings.TrimSpace(rtfText([]byte(tc.rtf))); got != tc.want {
+			t.Errorf("%s: rtfText() = %q, want %q", name, got, tc.want)
+		}
+	}
+}
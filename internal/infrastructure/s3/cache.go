@@ -0,0 +1,72 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"doc2text/internal/core/abstraction/cache"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// metaExpires holds the Unix time an entry expires at. Buckets may also
+// expire the prefix with a lifecycle rule; this keeps reads correct until
+// the rule runs.
+const metaExpires = "Doc2text-Expires"
+
+type s3Cache struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewCache stores entries as objects under prefix in cfg.Bucket.
+func NewCache(cfg Config, prefix string) (cache.Store, error) {
+	client, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Cache{client: client, bucket: cfg.Bucket, prefix: prefix}, nil
+}
+
+func (c *s3Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	obj, err := c.client.GetObject(ctx, c.bucket, c.prefix+key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, false, fmt.Errorf("get cache object: %w", err)
+	}
+	defer obj.Close()
+
+	info, err := obj.Stat()
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("stat cache object: %w", err)
+	}
+	if exp, err := strconv.ParseInt(info.UserMetadata[metaExpires], 10, 64); err == nil && time.Now().Unix() > exp {
+		return nil, false, nil
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, obj); err != nil {
+		return nil, false, fmt.Errorf("read cache object: %w", err)
+	}
+	return buf.Bytes(), true, nil
+}
+
+func (c *s3Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	opts := minio.PutObjectOptions{ContentType: "application/octet-stream"}
+	if ttl > 0 {
+		opts.UserMetadata = map[string]string{
+			metaExpires: strconv.FormatInt(time.Now().Add(ttl).Unix(), 10),
+		}
+	}
+	_, err := c.client.PutObject(ctx, c.bucket, c.prefix+key, bytes.NewReader(value), int64(len(value)), opts)
+	if err != nil {
+		return fmt.Errorf("put cache object: %w", err)
+	}
+	return nil
+}
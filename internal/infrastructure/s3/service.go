@@ -3,12 +3,11 @@ package s3
 import (
 	"bytes"
 	"context"
-	"doc2text/internal/core/abstraction/download"
+	"doc2text/internal/core/abstraction/storage"
+	"doc2text/internal/infrastructure/mimetypes"
 	"fmt"
 	"io"
-	"mime"
 	"net/http"
-	"path/filepath"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -22,70 +21,87 @@ type Config struct {
 	UseSSL    bool
 }
 
-type s3Downloader struct {
+type s3Storage struct {
 	client *minio.Client
 	bucket string
 }
 
 const (
-	defaultMimeType = "application/octet-stream"
+	defaultMimeType = mimetypes.Default
 )
 
-func NewDownloader(cfg Config) (download.Downloader, error) {
-	client, err := minio.New(cfg.Endpoint, &minio.Options{
+func newClient(cfg Config) (*minio.Client, error) {
+	return minio.New(cfg.Endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
 		Secure: cfg.UseSSL,
 	})
+}
+
+func NewStorage(cfg Config) (storage.Storage, error) {
+	client, err := newClient(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	return &s3Downloader{
+	return &s3Storage{
 		client: client,
 		bucket: cfg.Bucket,
 	}, nil
 }
 
-func (d *s3Downloader) GetInfo(ctx context.Context, req download.GetInfoRequest) (download.GetInfoResponse, error) {
+func (d *s3Storage) GetInfo(ctx context.Context, req storage.GetInfoRequest) (storage.GetInfoResponse, error) {
 	info, err := d.client.StatObject(ctx, d.bucket, req.ObjectKey, minio.StatObjectOptions{})
 	if err != nil {
-		return download.GetInfoResponse{}, fmt.Errorf("stat object: %w", err)
+		return storage.GetInfoResponse{}, fmt.Errorf("stat object: %w", err)
 	}
 
 	mimeType := info.ContentType
-	if mimeType == "" {
-		ext := filepath.Ext(req.ObjectKey)
-		if ext != "" {
-			if byExt := mime.TypeByExtension(ext); byExt != "" {
-				mimeType = byExt
-			}
+	if mimeType == "" || mimeType == defaultMimeType || mimeType == "application/zip" || mimeType == "application/x-zip-compressed" {
+		if byExt := mimetypes.ByExtension(req.ObjectKey); byExt != "" {
+			mimeType = byExt
 		}
 		if mimeType == "" {
 			mimeType = defaultMimeType
 		}
 	}
 
-	return download.GetInfoResponse{
+	return storage.GetInfoResponse{
 		MimeType: mimeType,
+		ETag:     info.ETag,
 	}, nil
 }
 
-func (d *s3Downloader) GetFile(ctx context.Context, req download.GetFileRequest) (download.GetFileResponse, error) {
+func (d *s3Storage) GetFile(ctx context.Context, req storage.GetFileRequest) (storage.GetFileResponse, error) {
 	obj, err := d.client.GetObject(ctx, d.bucket, req.ObjectKey, minio.GetObjectOptions{})
 	if err != nil {
-		return download.GetFileResponse{}, fmt.Errorf("get object: %w", err)
+		return storage.GetFileResponse{}, fmt.Errorf("get object: %w", err)
 	}
 	defer obj.Close()
 
 	var buf bytes.Buffer
 	if _, err := io.Copy(&buf, obj); err != nil {
-		return download.GetFileResponse{}, fmt.Errorf("read object: %w", err)
+		return storage.GetFileResponse{}, fmt.Errorf("read object: %w", err)
 	}
 	content := buf.Bytes()
 
 	_ = http.DetectContentType(content)
 
-	return download.GetFileResponse{
+	return storage.GetFileResponse{
 		Content: content,
 	}, nil
 }
+
+func (d *s3Storage) PutFile(ctx context.Context, req storage.PutFileRequest) error {
+	bucket := req.Bucket
+	if bucket == "" {
+		bucket = d.bucket
+	}
+	_, err := d.client.PutObject(ctx, bucket, req.ObjectKey, bytes.NewReader(req.Content), int64(len(req.Content)), minio.PutObjectOptions{
+		ContentType:  req.MimeType,
+		UserMetadata: req.Metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("put object: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package tesseract
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+type Options struct {
+	// Binary is the tesseract executable; defaults to "tesseract" from PATH.
+	Binary string
+	// Languages are tesseract traineddata names, e.g. rus, eng.
+	Languages []string
+	// PSM is the page segmentation mode (tesseract --psm); 0 keeps the default.
+	PSM           int
+	MinConfidence float64
+}
+
+type tessRecognizer struct {
+	binary        string
+	languages     []string
+	psm           int
+	minConfidence float64
+}
+
+func New(o Options) recognize.Recognizer {
+	if o.Binary == "" {
+		o.Binary = "tesseract"
+	}
+	return &tessRecognizer{
+		binary:        o.Binary,
+		languages:     o.Languages,
+		psm:           o.PSM,
+		minConfidence: o.MinConfidence,
+	}
+}
+
+var supportedMimeTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/jpg":  true,
+	"image/tiff": true,
+	"image/bmp":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+func (r *tessRecognizer) Recognize(ctx context.Context, req recognize.Request) (recognize.Response, error) {
+	if req.ContentBase64 == "" {
+		return recognize.Response{}, errors.New("empty ContentBase64")
+	}
+	mimeType := strings.ToLower(strings.TrimSpace(strings.SplitN(req.MimeType, ";", 2)[0]))
+	if !supportedMimeTypes[mimeType] {
+		return recognize.Response{}, fmt.Errorf("tesseract: %w: %q", recognize.ErrUnsupportedType, req.MimeType)
+	}
+	content, err := base64.StdEncoding.DecodeString(req.ContentBase64)
+	if err != nil {
+		return recognize.Response{}, fmt.Errorf("tesseract: decode base64: %w", err)
+	}
+
+	raw, err := r.run(ctx, content)
+	if err != nil {
+		return recognize.Response{}, err
+	}
+	pages, err := parseTSV(raw)
+	if err != nil {
+		return recognize.Response{}, err
+	}
+
+	minConf := r.minConfidence
+	if req.MinConfidence != nil {
+		minConf = *req.MinConfidence
+	}
+	res := recognize.Response{}
+	res.Pages, res.FilteredWords, res.FilteredLines = recognize.FilterByConfidence(pages, minConf)
+	res.ExtractedText = recognize.JoinText(res.Pages)
+	return res, nil
+}
+
+func (r *tessRecognizer) run(ctx context.Context, content []byte) ([]byte, error) {
+	args := []string{"stdin", "stdout"}
+	if len(r.languages) > 0 {
+		args = append(args, "-l", strings.Join(r.languages, "+"))
+	}
+	if r.psm > 0 {
+		args = append(args, "--psm", strconv.Itoa(r.psm))
+	}
+	args = append(args, "tsv")
+
+	cmd := exec.CommandContext(ctx, r.binary, args...)
+	cmd.Stdin = bytes.NewReader(content)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("tesseract: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+const (
+	levelPage  = 1
+	levelBlock = 2
+	levelWord  = 5
+)
+
+type lineKey struct {
+	block, par, line int
+}
+
+// parseTSV rebuilds the layout tree from tesseract's TSV output. Tesseract
+// reports confidence in percent and only for words; line confidence is the
+// mean of its words.
+func parseTSV(raw []byte) ([]recognize.Page, error) {
+	var (
+		pages     []recognize.Page
+		page      *recognize.Page
+		block     *recognize.Block
+		line      *recognize.Line
+		lineAt    lineKey
+		confSum   float64
+		closeLine = func() {
+			if line != nil && len(line.Words) > 0 {
+				line.Confidence = confSum / float64(len(line.Words))
+				texts := make([]string, 0, len(line.Words))
+				for _, w := range line.Words {
+					texts = append(texts, w.Text)
+				}
+				line.Text = strings.Join(texts, " ")
+				block.Lines = append(block.Lines, *line)
+			}
+			line, confSum = nil, 0
+		}
+		closeBlock = func() {
+			closeLine()
+			// A block row may come before any page row in broken output.
+			if page != nil && block != nil && len(block.Lines) > 0 {
+				page.Blocks = append(page.Blocks, *block)
+			}
+			block = nil
+		}
+	)
+
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	first := true
+	for sc.Scan() {
+		if first {
+			first = false
+			continue
+		}
+		cols := strings.Split(sc.Text(), "\t")
+		if len(cols) < 11 {
+			continue
+		}
+		n := make([]int, 10)
+		for i := range n {
+			v, err := strconv.Atoi(cols[i])
+			if err != nil {
+				return nil, fmt.Errorf("tesseract: parse tsv: %w", err)
+			}
+			n[i] = v
+		}
+		level, blockNum, parNum, lineNum := n[0], n[2], n[3], n[4]
+		box := rect(n[6], n[7], n[8], n[9])
+
+		switch level {
+		case levelPage:
+			closeBlock()
+			pages = append(pages, recognize.Page{Width: int64(n[8]), Height: int64(n[9])})
+			page = &pages[len(pages)-1]
+		case levelBlock:
+			closeBlock()
+			block = &recognize.Block{BoundingBox: box}
+		case levelWord:
+			if page == nil || block == nil {
+				continue
+			}
+			text := ""
+			if len(cols) > 11 {
+				text = strings.TrimSpace(cols[11])
+			}
+			if text == "" {
+				continue
+			}
+			conf, err := strconv.ParseFloat(cols[10], 64)
+			if err != nil {
+				return nil, fmt.Errorf("tesseract: parse tsv: %w", err)
+			}
+			key := lineKey{blockNum, parNum, lineNum}
+			if line == nil || key != lineAt {
+				closeLine()
+				line = &recognize.Line{}
+				lineAt = key
+			}
+			line.Words = append(line.Words, recognize.Word{
+				Text:        text,
+				BoundingBox: box,
+				Confidence:  max(conf, 0) / 100,
+			})
+			confSum += max(conf, 0) / 100
+			line.BoundingBox = union(line.BoundingBox, box)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("tesseract: read tsv: %w", err)
+	}
+	if page != nil {
+		closeBlock()
+	}
+	return pages, nil
+}
+
+func rect(left, top, width, height int) recognize.Polygon {
+	l, t, r, b := int64(left), int64(top), int64(left+width), int64(top+height)
+	return recognize.Polygon{Vertices: []recognize.Point{{X: l, Y: t}, {X: l, Y: b}, {X: r, Y: b}, {X: r, Y: t}}}
+}
+
+func union(a, b recognize.Polygon) recognize.Polygon {
+	if len(a.Vertices) != 4 {
+		return b
+	}
+	l := min(a.Vertices[0].X, b.Vertices[0].X)
+	t := min(a.Vertices[0].Y, b.Vertices[0].Y)
+	r := max(a.Vertices[2].X, b.Vertices[2].X)
+	bt := max(a.Vertices[2].Y, b.Vertices[2].Y)
+	return recognize.Polygon{Vertices: []recognize.Point{{X: l, Y: t}, {X: l, Y: bt}, {X: r, Y: bt}, {X: r, Y: t}}}
+}
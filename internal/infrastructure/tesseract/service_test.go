@@ -0,0 +1,103 @@
+package tesseract
+
+import (
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+func TestParseTSV(t *testing.T) {
+	raw, err := os.ReadFile("testdata/page.tsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages, err := parseTSV(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("%d pages, want 2", len(pages))
+	}
+	if p := pages[0]; p.Width != 640 || p.Height != 480 {
+		t.Fatalf("page 1 is %dx%d", p.Width, p.Height)
+	}
+	// The second block holds only a blank word and is dropped.
+	if n := len(pages[0].Blocks); n != 1 {
+		t.Fatalf("page 1 has %d blocks, want 1", n)
+	}
+
+	lines := pages[0].Blocks[0].Lines
+	for i, want := range []struct {
+		text string
+		conf float64
+	}{
+		{"Hello world", (0.965 + 0.895) / 2},
+		// -1 counts as no confidence, but the word stays.
+		{"noise ok", 0.3},
+		// A new paragraph starts a new line even with the same line number.
+		{"Para2", 0.91},
+	} {
+		if i >= len(lines) {
+			t.Fatalf("%d lines, want 3", len(lines))
+		}
+		if lines[i].Text != want.text || math.Abs(lines[i].Confidence-want.conf) > 1e-9 {
+			t.Errorf("line %d = %q (%v), want %q (%v)", i+1, lines[i].Text, lines[i].Confidence, want.text, want.conf)
+		}
+	}
+	if len(lines) != 3 {
+		t.Fatalf("%d lines, want 3", len(lines))
+	}
+
+	hello := lines[0].Words[0]
+	if hello.Confidence != 0.965 || !reflect.DeepEqual(hello.BoundingBox, rect(36, 92, 100, 24)) {
+		t.Errorf("word = %+v", hello)
+	}
+	if noise := lines[1].Words[0]; noise.Confidence != 0 {
+		t.Errorf("word with conf -1 = %v", noise.Confidence)
+	}
+	// The line box spans its words: Hello from (36, 92), world to (270, 118).
+	if got, want := lines[0].BoundingBox, rect(36, 92, 234, 26); !reflect.DeepEqual(got, want) {
+		t.Errorf("line box = %v, want %v", got.Vertices, want.Vertices)
+	}
+
+	if got := recognize.JoinText(pages[1:]); !strings.Contains(got, "Второй") || pages[1].Width != 100 {
+		t.Errorf("page 2 = %+v", pages[1])
+	}
+}
+
+func TestParseTSVBlockBeforePage(t *testing.T) {
+	raw := "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+		"2\t1\t1\t0\t0\t0\t0\t0\t10\t10\t-1\t\n" +
+		"5\t1\t1\t1\t1\t1\t0\t0\t10\t10\t90\tlost\n" +
+		"2\t1\t2\t0\t0\t0\t0\t0\t10\t10\t-1\t\n" +
+		"1\t1\t0\t0\t0\t0\t0\t0\t20\t20\t-1\t\n" +
+		"2\t1\t3\t0\t0\t0\t0\t0\t10\t10\t-1\t\n" +
+		"5\t1\t3\t1\t1\t1\t0\t0\t10\t10\t80\tkept\n"
+	pages, err := parseTSV([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || strings.TrimSpace(recognize.JoinText(pages)) != "kept" {
+		t.Fatalf("pages = %+v", pages)
+	}
+}
+
+func TestParseTSVMalformed(t *testing.T) {
+	header := "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n"
+	for name, row := range map[string]string{
+		"bad number":     "1\tx\t0\t0\t0\t0\t0\t0\t20\t20\t-1\t\n",
+		"bad confidence": "1\t1\t0\t0\t0\t0\t0\t0\t20\t20\t-1\t\n2\t1\t1\t0\t0\t0\t0\t0\t10\t10\t-1\t\n5\t1\t1\t1\t1\t1\t0\t0\t10\t10\thigh\tword\n",
+	} {
+		if _, err := parseTSV([]byte(header + row)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+	// Short rows, like the trailing newline, are skipped.
+	if pages, err := parseTSV([]byte(header + "1\t1\n")); err != nil || len(pages) != 0 {
+		t.Errorf("short row: %v, %v", pages, err)
+	}
+}
@@ -0,0 +1,282 @@
+package unarchive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"doc2text/internal/core/abstraction/unpack"
+	"doc2text/internal/infrastructure/mimetypes"
+
+	"github.com/bodgit/sevenzip"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	MimeZip      = "application/zip"
+	MimeTar      = "application/x-tar"
+	MimeGzip     = "application/gzip"
+	MimeXGzip    = "application/x-gzip"
+	MimeSevenZip = "application/x-7z-compressed"
+	// MimeXZip is what browsers on Windows send for .zip uploads.
+	MimeXZip = "application/x-zip-compressed"
+)
+
+// MimeTypes lists the archive formats the unpacker understands.
+var MimeTypes = []string{MimeZip, MimeXZip, MimeTar, MimeGzip, MimeXGzip, MimeSevenZip}
+
+var errEncrypted = errors.New("entry is encrypted")
+
+type Options struct {
+	// MaxEntries caps the number of files across all nesting levels.
+	MaxEntries int
+	// MaxTotalSize caps the uncompressed bytes across all nesting levels.
+	MaxTotalSize int64
+	// MaxDepth is how many archives may be nested inside the upload; 1
+	// means nested archives are not expanded.
+	MaxDepth int
+}
+
+type unpacker struct {
+	opts Options
+}
+
+func New(o Options) unpack.Unpacker {
+	return &unpacker{opts: o}
+}
+
+func (u *unpacker) Unpack(ctx context.Context, req unpack.Request) (unpack.Response, error) {
+	w := &walker{ctx: ctx, opts: u.opts}
+	if err := w.expand(req.Content, req.MimeType, "", 1); err != nil {
+		return unpack.Response{}, fmt.Errorf("unarchive: %w", err)
+	}
+	return unpack.Response{Entries: w.entries}, nil
+}
+
+// walker carries the limits shared by every nesting level.
+type walker struct {
+	ctx     context.Context
+	opts    Options
+	entries []unpack.Entry
+	count   int
+	total   int64
+}
+
+func (w *walker) expand(content []byte, mimeType, prefix string, depth int) error {
+	switch baseMimeType(mimeType) {
+	case MimeZip, MimeXZip:
+		return w.zip(content, prefix, depth)
+	case MimeTar:
+		return w.tar(bytes.NewReader(content), prefix, depth)
+	case MimeGzip, MimeXGzip:
+		return w.gzip(content, prefix, depth)
+	case MimeSevenZip:
+		return w.sevenZip(content, prefix, depth)
+	default:
+		return fmt.Errorf("unsupported archive type %q", mimeType)
+	}
+}
+
+func (w *walker) zip(content []byte, prefix string, depth int) error {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return fmt.Errorf("open zip %q: %w", prefix, err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := f.Name
+		if f.NonUTF8 {
+			// Windows' built-in zipper writes names in the OEM code page.
+			if dec, err := charmap.CodePage866.NewDecoder().String(name); err == nil {
+				name = dec
+			}
+		}
+		if f.Flags&0x1 != 0 {
+			if err := w.fail(prefix, name, errEncrypted); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			if err := w.fail(prefix, name, err); err != nil {
+				return err
+			}
+			continue
+		}
+		err = w.add(prefix, name, rc, depth)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) tar(r io.Reader, prefix string, depth int) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read tar %q: %w", prefix, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := w.add(prefix, hdr.Name, tr, depth); err != nil {
+			return err
+		}
+	}
+}
+
+// gzip unwraps a .tar.gz, or a single gzipped file named after the
+// original (or the archive minus ".gz").
+func (w *walker) gzip(content []byte, prefix string, depth int) error {
+	zr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("open gzip %q: %w", prefix, err)
+	}
+	defer zr.Close()
+
+	// The tar header sits in the first block; peek at it without inflating
+	// more than that.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(zr, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return fmt.Errorf("read gzip %q: %w", prefix, err)
+	}
+	rest := io.MultiReader(bytes.NewReader(head[:n]), zr)
+	if n == 512 && string(head[257:262]) == "ustar" {
+		return w.tar(rest, prefix, depth)
+	}
+
+	name := zr.Name
+	if name == "" {
+		name = strings.TrimSuffix(path.Base(strings.TrimSuffix(prefix, "/")), ".gz")
+		if name == "" || name == "." {
+			name = "content"
+		}
+	}
+	return w.add(prefix, name, rest, depth)
+}
+
+func (w *walker) sevenZip(content []byte, prefix string, depth int) error {
+	zr, err := sevenzip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return fmt.Errorf("open 7z %q: %w", prefix, err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			if err := w.fail(prefix, f.Name, err); err != nil {
+				return err
+			}
+			continue
+		}
+		err = w.add(prefix, f.Name, rc, depth)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add reads one file against the remaining budget and either records it or,
+// if it is an archive itself, expands it one level deeper.
+func (w *walker) add(prefix, name string, r io.Reader, depth int) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	p := cleanPath(name)
+	if p == "" || junk(p) {
+		return nil
+	}
+	if err := w.count1(); err != nil {
+		return err
+	}
+
+	left := w.opts.MaxTotalSize - w.total
+	data, err := io.ReadAll(io.LimitReader(r, left+1))
+	if err != nil {
+		w.entries = append(w.entries, unpack.Entry{Path: prefix + p, Err: err})
+		return nil
+	}
+	if int64(len(data)) > left {
+		return fmt.Errorf("%w: more than %d bytes uncompressed", unpack.ErrLimitExceeded, w.opts.MaxTotalSize)
+	}
+	w.total += int64(len(data))
+
+	mimeType := mimetypes.Detect(p, data)
+	if isArchive(mimeType) {
+		if depth >= w.opts.MaxDepth {
+			return fmt.Errorf("%w: archives nested deeper than %d levels", unpack.ErrLimitExceeded, w.opts.MaxDepth)
+		}
+		return w.expand(data, mimeType, prefix+p+"/", depth+1)
+	}
+	w.entries = append(w.entries, unpack.Entry{Path: prefix + p, MimeType: mimeType, Content: data})
+	return nil
+}
+
+func (w *walker) fail(prefix, name string, err error) error {
+	p := cleanPath(name)
+	if p == "" || junk(p) {
+		return nil
+	}
+	if err := w.count1(); err != nil {
+		return err
+	}
+	w.entries = append(w.entries, unpack.Entry{Path: prefix + p, Err: err})
+	return nil
+}
+
+func (w *walker) count1() error {
+	w.count++
+	if w.count > w.opts.MaxEntries {
+		return fmt.Errorf("%w: more than %d entries", unpack.ErrLimitExceeded, w.opts.MaxEntries)
+	}
+	return nil
+}
+
+func isArchive(mimeType string) bool {
+	base := baseMimeType(mimeType)
+	for _, m := range MimeTypes {
+		if base == m {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanPath normalizes an entry name to a relative slash path.
+func cleanPath(name string) string {
+	name = strings.ReplaceAll(name, `\`, "/")
+	p := path.Clean("/" + name)
+	return strings.TrimPrefix(p, "/")
+}
+
+// junk reports metadata files archivers leave behind.
+func junk(p string) bool {
+	base := path.Base(p)
+	return strings.HasPrefix(p, "__MACOSX/") || strings.Contains(p, "/__MACOSX/") ||
+		base == ".DS_Store" || base == "Thumbs.db" || base == "desktop.ini"
+}
+
+func baseMimeType(mimeType string) string {
+	return strings.ToLower(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0]))
+}
@@ -0,0 +1,215 @@
+package unarchive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"doc2text/internal/core/abstraction/unpack"
+)
+
+type file struct {
+	name string
+	data []byte
+}
+
+func zipOf(t *testing.T, files ...file) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzOf(t *testing.T, files ...file) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func text(s string) []byte { return []byte(s) }
+
+var generous = Options{MaxEntries: 100, MaxTotalSize: 1 << 20, MaxDepth: 3}
+
+func unpackWith(o Options, content []byte, mimeType string) ([]unpack.Entry, error) {
+	res, err := New(o).Unpack(context.Background(), unpack.Request{Content: content, MimeType: mimeType})
+	return res.Entries, err
+}
+
+func paths(entries []unpack.Entry) string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Path
+	}
+	return strings.Join(out, " ")
+}
+
+func TestUnpackZip(t *testing.T) {
+	inner := zipOf(t, file{"c.txt", text("see")})
+	archive := zipOf(t,
+		file{"a.txt", text("hello")},
+		file{"dir/", nil},
+		file{`win\b.txt`, text("bee")},
+		file{"../../etc/passwd", text("root")},
+		file{"__MACOSX/._a.txt", text("junk")},
+		file{"dir/.DS_Store", text("junk")},
+		file{"nested.zip", inner},
+	)
+	for _, mimeType := range []string{MimeZip, MimeXZip, "application/x-zip-compressed; name=scans.zip"} {
+		entries, err := unpackWith(generous, archive, mimeType)
+		if err != nil {
+			t.Fatalf("%s: %v", mimeType, err)
+		}
+		if got, want := paths(entries), "a.txt win/b.txt etc/passwd nested.zip/c.txt"; got != want {
+			t.Fatalf("%s: paths = %q, want %q", mimeType, got, want)
+		}
+		if string(entries[3].Content) != "see" || !strings.HasPrefix(entries[0].MimeType, "text/plain") {
+			t.Fatalf("%s: entries = %+v", mimeType, entries)
+		}
+	}
+}
+
+func TestUnpackTarGz(t *testing.T) {
+	entries, err := unpackWith(generous, tarGzOf(t, file{"a.txt", text("one")}, file{"sub/b.txt", text("two")}), MimeGzip)
+	if err != nil || paths(entries) != "a.txt sub/b.txt" || string(entries[1].Content) != "two" {
+		t.Fatalf("Unpack() = %+v, %v", entries, err)
+	}
+
+	// A lone gzipped file keeps the name stored in the header.
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Name = "scan.txt"
+	gz.Write(text("plain"))
+	gz.Close()
+	entries, err = unpackWith(generous, buf.Bytes(), MimeXGzip)
+	if err != nil || paths(entries) != "scan.txt" || string(entries[0].Content) != "plain" {
+		t.Fatalf("Unpack() = %+v, %v", entries, err)
+	}
+}
+
+func TestEntryLimit(t *testing.T) {
+	flat := zipOf(t, file{"1.txt", text("1")}, file{"2.txt", text("2")}, file{"3.txt", text("3")}, file{"4.txt", text("4")})
+	// Entries of nested archives count towards the same limit.
+	nested := zipOf(t, file{"1.txt", text("1")}, file{"inner.zip", zipOf(t, file{"2.txt", text("2")}, file{"3.txt", text("3")})})
+
+	for name, tc := range map[string]struct {
+		content []byte
+		max     int
+		fail    bool
+	}{
+		"flat at limit":   {flat, 4, false},
+		"flat over limit": {flat, 3, true},
+		// inner.zip itself is an entry: 1.txt, inner.zip, 2.txt, 3.txt.
+		"nested at limit":   {nested, 4, false},
+		"nested over limit": {nested, 3, true},
+	} {
+		o := generous
+		o.MaxEntries = tc.max
+		_, err := unpackWith(o, tc.content, MimeZip)
+		if tc.fail != errors.Is(err, unpack.ErrLimitExceeded) {
+			t.Errorf("%s: error = %v", name, err)
+		}
+	}
+}
+
+func TestSizeLimit(t *testing.T) {
+	half := bytes.Repeat([]byte("a"), 500)
+	two := zipOf(t, file{"a.txt", half}, file{"b.txt", half})
+	for limit, fail := range map[int64]bool{1000: false, 999: true} {
+		o := generous
+		o.MaxTotalSize = limit
+		if _, err := unpackWith(o, two, MimeZip); fail != errors.Is(err, unpack.ErrLimitExceeded) {
+			t.Errorf("total %d: error = %v", limit, err)
+		}
+	}
+
+	// A single entry that inflates past the budget is cut off while it is
+	// read: 64 MiB of zeros compress to a few dozen KiB.
+	bomb := zipOf(t, file{"zeros.txt", make([]byte, 64<<20)})
+	if len(bomb) > 1<<20 {
+		t.Fatalf("bomb is %d bytes compressed", len(bomb))
+	}
+	o := generous
+	o.MaxTotalSize = 1 << 20
+	if _, err := unpackWith(o, bomb, MimeZip); !errors.Is(err, unpack.ErrLimitExceeded) {
+		t.Fatalf("bomb: error = %v", err)
+	}
+	// The budget is shared with nested archives too.
+	nested := zipOf(t, file{"a.txt", half}, file{"inner.tar.gz", tarGzOf(t, file{"b.txt", half})})
+	o.MaxTotalSize = 900
+	if _, err := unpackWith(o, nested, MimeZip); !errors.Is(err, unpack.ErrLimitExceeded) {
+		t.Fatalf("nested: error = %v", err)
+	}
+}
+
+func TestDepthLimit(t *testing.T) {
+	archive := zipOf(t, file{"leaf.txt", text("0")})
+	for i := 1; i <= 3; i++ {
+		archive = zipOf(t, file{fmt.Sprintf("level%d.zip", i), archive})
+	}
+	// The upload is level 1 and holds three more.
+	for depth, fail := range map[int]bool{4: false, 3: true, 1: true} {
+		o := generous
+		o.MaxDepth = depth
+		entries, err := unpackWith(o, archive, MimeZip)
+		if fail != errors.Is(err, unpack.ErrLimitExceeded) {
+			t.Errorf("depth %d: error = %v", depth, err)
+		}
+		if !fail && paths(entries) != "level3.zip/level2.zip/level1.zip/leaf.txt" {
+			t.Errorf("depth %d: paths = %q", depth, paths(entries))
+		}
+	}
+}
+
+func TestEncryptedEntry(t *testing.T) {
+	archive := zipOf(t, file{"secret.txt", text("x")}, file{"open.txt", text("y")})
+	// Set the encryption flag in the local and central headers.
+	for _, sig := range [][]byte{{'P', 'K', 3, 4}, {'P', 'K', 1, 2}} {
+		off := bytes.Index(archive, sig)
+		flagAt := off + 6
+		if sig[2] == 1 {
+			flagAt = off + 8
+		}
+		archive[flagAt] |= 1
+	}
+	entries, err := unpackWith(generous, archive, MimeZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || !errors.Is(entries[0].Err, errEncrypted) || entries[1].Err != nil {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
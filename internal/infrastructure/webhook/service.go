@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"doc2text/internal/core/abstraction/callback"
+)
+
+// Headers sent with every notification. The signature is
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)); receivers
+// should recompute it and reject stale timestamps.
+const (
+	HeaderSignature = "X-Doc2Text-Signature"
+	HeaderTimestamp = "X-Doc2Text-Timestamp"
+	HeaderID        = "X-Doc2Text-Job-Id"
+	HeaderAttempt   = "X-Doc2Text-Attempt"
+)
+
+type Options struct {
+	Secret  string
+	Timeout time.Duration
+}
+
+type sender struct {
+	secret []byte
+	http   *http.Client
+}
+
+func New(o Options) callback.Sender {
+	return &sender{
+		secret: []byte(o.Secret),
+		http:   &http.Client{Timeout: o.Timeout},
+	}
+}
+
+func (s *sender) Send(ctx context.Context, req callback.Request) (callback.Response, error) {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return callback.Response{}, fmt.Errorf("webhook: build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "doc2text-webhook")
+	httpReq.Header.Set(HeaderTimestamp, ts)
+	httpReq.Header.Set(HeaderSignature, Sign(s.secret, ts, req.Body))
+	httpReq.Header.Set(HeaderID, req.ID)
+	httpReq.Header.Set(HeaderAttempt, strconv.Itoa(req.Attempt))
+
+	resp, err := s.http.Do(httpReq)
+	if err != nil {
+		return callback.Response{}, fmt.Errorf("webhook: post: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain a little so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	res := callback.Response{Status: resp.StatusCode}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return res, fmt.Errorf("webhook: receiver answered %s", resp.Status)
+	}
+	return res, nil
+}
+
+// Sign computes the signature header value for a body sent at timestamp.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"doc2text/internal/core/abstraction/callback"
+)
+
+func TestSignKnownValue(t *testing.T) {
+	// printf '1700000000.{"id":"j1"}' | openssl dgst -sha256 -hmac secret
+	const want = "sha256=1c67234337a0ba7e1f64f75cf610bec867d07e75e853a67b8469845f6eeef78e"
+	if got := Sign([]byte("secret"), "1700000000", []byte(`{"id":"j1"}`)); got != want {
+		t.Fatalf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestSignCoversEveryPart(t *testing.T) {
+	base := Sign([]byte("secret"), "1700000000", []byte("body"))
+	for name, got := range map[string]string{
+		"secret":    Sign([]byte("other"), "1700000000", []byte("body")),
+		"timestamp": Sign([]byte("secret"), "1700000001", []byte("body")),
+		"body":      Sign([]byte("secret"), "1700000000", []byte("bodY")),
+	} {
+		if got == base {
+			t.Errorf("changing the %s keeps the signature", name)
+		}
+	}
+}
+
+func TestSendSignsRequest(t *testing.T) {
+	body := []byte(`{"id":"j1","state":"done"}`)
+	var got *http.Request
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	before := time.Now().Unix()
+	res, err := New(Options{Secret: "secret", Timeout: time.Second}).Send(context.Background(), callback.Request{URL: srv.URL, Body: body, ID: "j1", Attempt: 2})
+	if err != nil || res.Status != http.StatusNoContent {
+		t.Fatalf("Send() = %+v, %v", res, err)
+	}
+
+	ts := got.Header.Get(HeaderTimestamp)
+	if n, err := strconv.ParseInt(ts, 10, 64); err != nil || n < before || n > time.Now().Unix() {
+		t.Fatalf("timestamp = %q", ts)
+	}
+	if sig := got.Header.Get(HeaderSignature); sig != Sign([]byte("secret"), ts, gotBody) {
+		t.Fatalf("signature %q does not match the body received", sig)
+	}
+	if string(gotBody) != string(body) || got.Header.Get(HeaderID) != "j1" || got.Header.Get(HeaderAttempt) != "2" {
+		t.Fatalf("request = %q, headers %v", gotBody, got.Header)
+	}
+	if got.Method != http.MethodPost || got.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("request = %s %s", got.Method, got.Header.Get("Content-Type"))
+	}
+}
+
+func TestSendRejectedStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusGone)
+	}))
+	defer srv.Close()
+
+	res, err := New(Options{Secret: "secret", Timeout: time.Second}).Send(context.Background(), callback.Request{URL: srv.URL, Body: []byte("{}")})
+	if err == nil || !strings.Contains(err.Error(), "410") || res.Status != http.StatusGone {
+		t.Fatalf("Send() = %+v, %v, want status 410", res, err)
+	}
+}
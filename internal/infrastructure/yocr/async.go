@@ -0,0 +1,124 @@
+package yocr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+const (
+	defaultPollInterval    = time.Second
+	defaultMaxPollInterval = 15 * time.Second
+)
+
+type operation struct {
+	ID    string   `json:"id"`
+	Done  bool     `json:"done"`
+	Error *ycError `json:"error"`
+}
+
+// recognizeAsync submits the document to recognizeTextAsync, polls the
+// operation with exponential backoff and then fetches the per-page results.
+func (r *textRecognizer) recognizeAsync(ctx context.Context, payload rtRequest) (recognize.Response, error) {
+	raw, status, err := doJSON(ctx, r.http, r.creds, r.folderID, http.MethodPost, r.asyncEndpoint, payload)
+	if err != nil {
+		return recognize.Response{}, httpError("submit", status, err)
+	}
+	var op operation
+	if err := json.Unmarshal(raw, &op); err != nil {
+		return recognize.Response{}, fmt.Errorf("unmarshal operation: %w", err)
+	}
+	if op.ID == "" {
+		return recognize.Response{}, fmt.Errorf("submit: empty operation id")
+	}
+
+	if err := r.waitOperation(ctx, op); err != nil {
+		return recognize.Response{}, err
+	}
+
+	resultURL := r.resultEndpoint + "?operationId=" + url.QueryEscape(op.ID)
+	raw, err = r.get(ctx, "get recognition", resultURL)
+	if err != nil {
+		return recognize.Response{}, err
+	}
+	pages, err := parseTextResults(raw)
+	if err != nil {
+		return recognize.Response{}, err
+	}
+	return recognize.Response{ExtractedText: recognize.JoinText(pages), Pages: pages}, nil
+}
+
+func (r *textRecognizer) waitOperation(ctx context.Context, op operation) error {
+	id := op.ID
+	opURL := strings.TrimRight(r.operationEndpoint, "/") + "/" + url.PathEscape(id)
+	delay := r.pollInterval
+	for !op.Done {
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+		raw, err := r.get(ctx, "poll operation "+id, opURL)
+		if err != nil {
+			return err
+		}
+		op = operation{}
+		if err := json.Unmarshal(raw, &op); err != nil {
+			return fmt.Errorf("unmarshal operation: %w", err)
+		}
+
+		delay = min(delay*3/2, r.maxPollInterval)
+	}
+	if op.Error != nil && op.Error.Message != "" {
+		return fmt.Errorf("operation %s: %s (code=%d)", id, op.Error.Message, op.Error.Code)
+	}
+	return nil
+}
+
+// get fetches the state or result of a submitted operation. The document is
+// already billed by then, so transport errors, 429 and 5xx are retried with
+// backoff until ctx ends rather than abandoning it. Anything else, such as
+// revoked credentials, fails at once.
+func (r *textRecognizer) get(ctx context.Context, stage, url string) ([]byte, error) {
+	delay := r.pollInterval
+	for {
+		raw, status, err := doJSON(ctx, r.http, r.creds, r.folderID, http.MethodGet, url, nil)
+		if err == nil {
+			return raw, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var te *transportError
+		if !errors.As(err, &te) && status != http.StatusTooManyRequests && status < 500 {
+			return nil, httpError(stage, status, err)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		delay = min(delay*2, r.maxPollInterval)
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func httpError(stage string, status int, err error) error {
+	if status != 0 {
+		return fmt.Errorf("yandex ocr %s http %d: %w", stage, status, err)
+	}
+	return fmt.Errorf("yandex ocr %s: %w", stage, err)
+}
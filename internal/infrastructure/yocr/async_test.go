@@ -0,0 +1,222 @@
+package yocr
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+// fakeOperations serves recognizeTextAsync, the operations API and
+// getRecognition for a single operation.
+type fakeOperations struct {
+	// pending is how many polls report the operation as running.
+	pending int32
+	// dropPolls closes the connection on that many polls, before any other
+	// answer.
+	dropPolls int32
+	// failPolls answers that many polls, and as many result fetches, with
+	// 503 before the real answer.
+	failPolls int32
+	// opError finishes the operation with an error.
+	opError string
+	// status answers every poll with this code when set.
+	status int
+
+	polls   atomic.Int32
+	fetches atomic.Int32
+}
+
+func (f *fakeOperations) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		http.Error(w, "no auth", http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/ocr/v1/recognizeTextAsync":
+		fmt.Fprint(w, `{"id":"op-1","done":false}`)
+
+	case r.Method == http.MethodGet && r.URL.Path == "/operations/op-1":
+		n := f.polls.Add(1)
+		switch {
+		case f.status != 0:
+			http.Error(w, `{"code":5,"message":"not found"}`, f.status)
+		case n <= f.dropPolls:
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		case n <= f.dropPolls+f.failPolls:
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+		case n <= f.dropPolls+f.failPolls+f.pending:
+			fmt.Fprint(w, `{"id":"op-1","done":false}`)
+		case f.opError != "":
+			fmt.Fprintf(w, `{"id":"op-1","done":true,"error":{"code":3,"message":%q}}`, f.opError)
+		default:
+			fmt.Fprint(w, `{"id":"op-1","done":true}`)
+		}
+
+	case r.Method == http.MethodGet && r.URL.Path == "/ocr/v1/getRecognition":
+		if r.URL.Query().Get("operationId") != "op-1" {
+			http.Error(w, "unknown operation", http.StatusNotFound)
+			return
+		}
+		if f.fetches.Add(1) <= f.failPolls {
+			http.Error(w, "try later", http.StatusBadGateway)
+			return
+		}
+		// Pages come out of order; the recognizer sorts them.
+		fmt.Fprint(w, asyncPage(1, "second"), "\n", asyncPage(0, "first"), "\n")
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func asyncPage(n int, text string) string {
+	return fmt.Sprintf(`{"result":{"page":"%d","textAnnotation":{"width":"10","height":"20","blocks":[{"lines":[{"text":%q,"words":[{"text":%q}]}]}]}}}`, n, text, text)
+}
+
+func newAsyncRecognizer(t *testing.T, f *fakeOperations) recognize.Recognizer {
+	t.Helper()
+	return newAsyncRecognizerWith(t, f, IAMToken("test-token"))
+}
+
+func newAsyncRecognizerWith(t *testing.T, f *fakeOperations, creds Credentials) recognize.Recognizer {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return NewTextRecognizer(TextOptions{
+		OcrEndpoint:       srv.URL + "/ocr/v1/recognizeText",
+		Credentials:       creds,
+		AsyncPDF:          true,
+		AsyncEndpoint:     srv.URL + "/ocr/v1/recognizeTextAsync",
+		ResultEndpoint:    srv.URL + "/ocr/v1/getRecognition",
+		OperationEndpoint: srv.URL + "/operations",
+		PollInterval:      time.Millisecond,
+		MaxPollInterval:   5 * time.Millisecond,
+	})
+}
+
+func pdfRequest() recognize.Request {
+	return recognize.Request{
+		ContentBase64: base64.StdEncoding.EncodeToString([]byte("%PDF-1.4")),
+		MimeType:      "application/pdf",
+	}
+}
+
+func TestAsyncRecognizeStitchesPages(t *testing.T) {
+	f := &fakeOperations{pending: 3, failPolls: 2}
+	r := newAsyncRecognizer(t, f)
+
+	res, err := r.Recognize(context.Background(), pdfRequest())
+	if err != nil {
+		t.Fatalf("Recognize() error = %v", err)
+	}
+	if len(res.Pages) != 2 || res.Pages[0].Blocks[0].Lines[0].Text != "first" || res.Pages[1].Blocks[0].Lines[0].Text != "second" {
+		t.Fatalf("pages = %+v", res.Pages)
+	}
+	if !strings.Contains(res.ExtractedText, "first") || strings.Index(res.ExtractedText, "first") > strings.Index(res.ExtractedText, "second") {
+		t.Fatalf("text = %q", res.ExtractedText)
+	}
+	if got, want := f.polls.Load(), f.failPolls+f.pending+1; got != want {
+		t.Fatalf("polled %d times, want %d", got, want)
+	}
+}
+
+func TestAsyncRecognizeOperationError(t *testing.T) {
+	r := newAsyncRecognizer(t, &fakeOperations{pending: 1, opError: "bad document"})
+
+	_, err := r.Recognize(context.Background(), pdfRequest())
+	if err == nil || !strings.Contains(err.Error(), "bad document") {
+		t.Fatalf("Recognize() error = %v, want operation error", err)
+	}
+}
+
+func TestAsyncRecognizePollRejected(t *testing.T) {
+	f := &fakeOperations{status: http.StatusNotFound}
+	r := newAsyncRecognizer(t, f)
+
+	_, err := r.Recognize(context.Background(), pdfRequest())
+	if err == nil || !strings.Contains(err.Error(), "http 404") {
+		t.Fatalf("Recognize() error = %v, want http 404", err)
+	}
+	if n := f.polls.Load(); n != 1 {
+		t.Fatalf("polled %d times, want 1", n)
+	}
+}
+
+func TestAsyncRecognizeTimeout(t *testing.T) {
+	for name, f := range map[string]*fakeOperations{
+		"running":     {pending: 1 << 30},
+		"unavailable": {failPolls: 1 << 30},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := newAsyncRecognizer(t, f)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, err := r.Recognize(ctx, pdfRequest())
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("Recognize() error = %v, want deadline exceeded", err)
+			}
+			if f.polls.Load() < 2 {
+				t.Fatalf("polled %d times, want several", f.polls.Load())
+			}
+		})
+	}
+}
+
+func TestAsyncRecognizeRetriesDroppedConnection(t *testing.T) {
+	f := &fakeOperations{dropPolls: 2}
+	r := newAsyncRecognizer(t, f)
+
+	if _, err := r.Recognize(context.Background(), pdfRequest()); err != nil {
+		t.Fatalf("Recognize() error = %v", err)
+	}
+	if n := f.polls.Load(); n != 3 {
+		t.Fatalf("polled %d times, want 3", n)
+	}
+}
+
+// revokedCredentials authorize the first call only, as a key revoked while
+// an operation runs would.
+type revokedCredentials struct {
+	calls atomic.Int32
+}
+
+func (c *revokedCredentials) Authorization(context.Context) (string, error) {
+	if c.calls.Add(1) > 1 {
+		return "", errors.New("key revoked")
+	}
+	return "Bearer test-token", nil
+}
+
+func TestAsyncRecognizeCredentialsFailFast(t *testing.T) {
+	f := &fakeOperations{pending: 1 << 30}
+	r := newAsyncRecognizerWith(t, f, &revokedCredentials{})
+
+	// No deadline: retrying a credentials error would never return.
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Recognize(context.Background(), pdfRequest())
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "key revoked") {
+			t.Fatalf("Recognize() error = %v, want credentials error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Recognize() kept retrying a credentials error")
+	}
+	if n := f.polls.Load(); n != 0 {
+		t.Fatalf("polled %d times, want 0", n)
+	}
+}
@@ -0,0 +1,339 @@
+package yocr
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const DefaultIAMEndpoint = "https://iam.api.cloud.yandex.net/iam/v1/tokens"
+
+// Credentials produce the Authorization header value for a Yandex Cloud call.
+type Credentials interface {
+	Authorization(ctx context.Context) (string, error)
+}
+
+type apiKeyCredentials string
+
+func APIKey(key string) Credentials { return apiKeyCredentials(key) }
+
+func (k apiKeyCredentials) Authorization(context.Context) (string, error) {
+	if k == "" {
+		return "", errors.New("api key is empty")
+	}
+	return "Api-Key " + string(k), nil
+}
+
+type iamTokenCredentials string
+
+func IAMToken(token string) Credentials { return iamTokenCredentials(token) }
+
+func (t iamTokenCredentials) Authorization(context.Context) (string, error) {
+	if t == "" {
+		return "", errors.New("iam token is empty")
+	}
+	return "Bearer " + string(t), nil
+}
+
+// ServiceAccountKey is the authorized key JSON issued by
+// `yc iam key create --service-account-id ...`.
+type ServiceAccountKey struct {
+	ID               string `json:"id"`
+	ServiceAccountID string `json:"service_account_id"`
+	PrivateKey       string `json:"private_key"`
+}
+
+func LoadServiceAccountKey(path string) (ServiceAccountKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ServiceAccountKey{}, fmt.Errorf("read sa key: %w", err)
+	}
+	return ParseServiceAccountKey(raw)
+}
+
+func ParseServiceAccountKey(raw []byte) (ServiceAccountKey, error) {
+	var k ServiceAccountKey
+	if err := json.Unmarshal(raw, &k); err != nil {
+		return ServiceAccountKey{}, fmt.Errorf("unmarshal sa key: %w", err)
+	}
+	if k.ID == "" || k.ServiceAccountID == "" || k.PrivateKey == "" {
+		return ServiceAccountKey{}, errors.New("sa key: id, service_account_id and private_key are required")
+	}
+	return k, nil
+}
+
+type ServiceAccountOptions struct {
+	Key ServiceAccountKey
+	// TokenEndpoint defaults to DefaultIAMEndpoint.
+	TokenEndpoint string
+	// RefreshBefore is how long before expiry the token is renewed in the
+	// background. Defaults to 1h.
+	RefreshBefore time.Duration
+	HTTPClient    *http.Client
+}
+
+// ServiceAccountCredentials exchange a signed JWT for an IAM token and keep it
+// fresh in the background until Close is called.
+type ServiceAccountCredentials struct {
+	key           ServiceAccountKey
+	privateKey    *rsa.PrivateKey
+	tokenEndpoint string
+	refreshBefore time.Duration
+	http          *http.Client
+
+	mu         sync.Mutex
+	token      string
+	expiresAt  time.Time
+	refreshing *refreshCall
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func NewServiceAccountCredentials(o ServiceAccountOptions) (*ServiceAccountCredentials, error) {
+	pk, err := parsePrivateKey(o.Key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	c := &ServiceAccountCredentials{
+		key:           o.Key,
+		privateKey:    pk,
+		tokenEndpoint: o.TokenEndpoint,
+		refreshBefore: o.RefreshBefore,
+		http:          o.HTTPClient,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if c.tokenEndpoint == "" {
+		c.tokenEndpoint = DefaultIAMEndpoint
+	}
+	if c.refreshBefore <= 0 {
+		c.refreshBefore = time.Hour
+	}
+	if c.http == nil {
+		c.http = &http.Client{Timeout: 10 * time.Second}
+	}
+	go c.refreshLoop()
+	return c, nil
+}
+
+// refreshCall is a token exchange in flight, shared by everyone who needs
+// a new token meanwhile.
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// refreshTimeout bounds one token exchange.
+const refreshTimeout = time.Minute
+
+// Authorization returns the current token while it is valid, even when a
+// background refresh is running, and waits for an exchange only when there
+// is no usable token.
+func (c *ServiceAccountCredentials) Authorization(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	if c.token != "" && time.Now().Before(c.expiresAt) {
+		token := c.token
+		c.mu.Unlock()
+		return "Bearer " + token, nil
+	}
+	call := c.refresh()
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	if call.err != nil {
+		return "", call.err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return "Bearer " + c.token, nil
+}
+
+// refresh starts a token exchange unless one is already running. The
+// exchange is detached from the caller, so one caller giving up does not
+// fail the others; c.mu must be held.
+func (c *ServiceAccountCredentials) refresh() *refreshCall {
+	if c.refreshing != nil {
+		return c.refreshing
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	c.refreshing = call
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+		token, expiresAt, err := c.exchange(ctx)
+		c.mu.Lock()
+		if err == nil {
+			c.token, c.expiresAt = token, expiresAt
+		}
+		c.refreshing = nil
+		c.mu.Unlock()
+		call.err = err
+		close(call.done)
+	}()
+	return call
+}
+
+func (c *ServiceAccountCredentials) Close() {
+	c.once.Do(func() { close(c.stop) })
+	<-c.done
+}
+
+func (c *ServiceAccountCredentials) refreshLoop() {
+	defer close(c.done)
+	const retryDelay = 30 * time.Second
+	for {
+		c.mu.Lock()
+		wait := time.Until(c.expiresAt.Add(-c.refreshBefore))
+		if wait <= 0 && c.token != "" {
+			// Short-lived token: renew halfway to expiry instead of spinning.
+			wait = max(time.Until(c.expiresAt)/2, time.Second)
+		}
+		c.mu.Unlock()
+		if wait < 0 {
+			wait = 0
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-c.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		c.mu.Lock()
+		call := c.refresh()
+		c.mu.Unlock()
+		select {
+		case <-c.stop:
+			return
+		case <-call.done:
+		}
+		if call.err != nil {
+			select {
+			case <-c.stop:
+				return
+			case <-time.After(retryDelay):
+			}
+		}
+	}
+}
+
+type iamTokenRequest struct {
+	JWT string `json:"jwt"`
+}
+
+type iamTokenResponse struct {
+	IAMToken  string    `json:"iamToken"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// exchange trades a freshly signed JWT for an IAM token. It takes no lock.
+func (c *ServiceAccountCredentials) exchange(ctx context.Context) (string, time.Time, error) {
+	jwt, err := c.signJWT(time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	body, err := json.Marshal(iamTokenRequest{JWT: jwt})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("marshal iam request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("build iam request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("iam token exchange: %w", err)
+	}
+	defer res.Body.Close()
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("read iam response: %w", err)
+	}
+	if res.StatusCode/100 != 2 {
+		return "", time.Time{}, fmt.Errorf("iam token exchange: unexpected status %d: %s", res.StatusCode, string(raw))
+	}
+
+	var tr iamTokenResponse
+	if err := json.Unmarshal(raw, &tr); err != nil {
+		return "", time.Time{}, fmt.Errorf("unmarshal iam response: %w", err)
+	}
+	if tr.IAMToken == "" {
+		return "", time.Time{}, errors.New("iam token exchange: empty token")
+	}
+	return tr.IAMToken, tr.ExpiresAt, nil
+}
+
+// signJWT builds the PS256 JWT the IAM token endpoint expects.
+func (c *ServiceAccountCredentials) signJWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"typ": "JWT",
+		"alg": "PS256",
+		"kid": c.key.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss": c.key.ServiceAccountID,
+		"aud": c.tokenEndpoint,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPSS(rand.Reader, c.privateKey, crypto.SHA256, digest[:], &rsa.PSSOptions{
+		SaltLength: rsa.PSSSaltLengthEqualsHash,
+	})
+	if err != nil {
+		return "", fmt.Errorf("sign jwt: %w", err)
+	}
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
+
+func parsePrivateKey(s string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("sa key: private_key is not PEM encoded")
+	}
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rk, ok := k.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("sa key: private_key is not RSA")
+		}
+		return rk, nil
+	}
+	k, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("sa key: parse private_key: %w", err)
+	}
+	return k, nil
+}
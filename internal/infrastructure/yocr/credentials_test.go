@@ -0,0 +1,183 @@
+package yocr
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeIAM is a token endpoint that checks the JWT and hands out numbered
+// tokens. The second exchange blocks on hold, when it is set.
+type fakeIAM struct {
+	t       *testing.T
+	pub     *rsa.PublicKey
+	calls   atomic.Int32
+	status  int
+	ttl     time.Duration
+	delay   time.Duration
+	hold    chan struct{}
+	holding chan struct{}
+}
+
+func (f *fakeIAM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := f.calls.Add(1)
+	var req iamTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		f.t.Errorf("decode request: %v", err)
+	}
+	f.checkJWT(req.JWT, "http://"+r.Host+r.URL.Path)
+	time.Sleep(f.delay)
+	if n == 2 && f.hold != nil {
+		f.holding <- struct{}{}
+		<-f.hold
+	}
+	if f.status != 0 {
+		http.Error(w, "denied", f.status)
+		return
+	}
+	json.NewEncoder(w).Encode(iamTokenResponse{
+		IAMToken:  fmt.Sprintf("t%d", n),
+		ExpiresAt: time.Now().Add(f.ttl),
+	})
+}
+
+func (f *fakeIAM) checkJWT(jwt, aud string) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		f.t.Errorf("jwt has %d parts", len(parts))
+		return
+	}
+	enc := base64.RawURLEncoding
+	var header, claims map[string]any
+	h, _ := enc.DecodeString(parts[0])
+	c, _ := enc.DecodeString(parts[1])
+	sig, _ := enc.DecodeString(parts[2])
+	json.Unmarshal(h, &header)
+	json.Unmarshal(c, &claims)
+	if header["alg"] != "PS256" || header["kid"] != "key-id" {
+		f.t.Errorf("header = %v", header)
+	}
+	if claims["iss"] != "sa-id" || claims["aud"] != aud {
+		f.t.Errorf("claims = %v, want aud %s", claims, aud)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPSS(f.pub, crypto.SHA256, digest[:], sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+		f.t.Errorf("jwt signature: %v", err)
+	}
+}
+
+func newServiceAccount(t *testing.T, iam *fakeIAM, refreshBefore time.Duration) *ServiceAccountCredentials {
+	t.Helper()
+	pk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iam.t, iam.pub = t, &pk.PublicKey
+	srv := httptest.NewServer(iam)
+	t.Cleanup(srv.Close)
+
+	c, err := NewServiceAccountCredentials(ServiceAccountOptions{
+		Key: ServiceAccountKey{
+			ID:               "key-id",
+			ServiceAccountID: "sa-id",
+			PrivateKey:       string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		},
+		TokenEndpoint: srv.URL + "/iam/v1/tokens",
+		RefreshBefore: refreshBefore,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestServiceAccountExchangesOnce(t *testing.T) {
+	iam := &fakeIAM{ttl: time.Hour, delay: 50 * time.Millisecond}
+	c := newServiceAccount(t, iam, time.Minute)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := c.Authorization(context.Background())
+			if err != nil || got != "Bearer t1" {
+				t.Errorf("Authorization() = %q, %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := iam.calls.Load(); n != 1 {
+		t.Fatalf("token exchanged %d times, want 1", n)
+	}
+}
+
+func TestServiceAccountKeepsTokenDuringRefresh(t *testing.T) {
+	iam := &fakeIAM{ttl: time.Hour, hold: make(chan struct{}), holding: make(chan struct{})}
+	// The first token is due for renewal right away.
+	c := newServiceAccount(t, iam, time.Hour-100*time.Millisecond)
+	if got, err := c.Authorization(context.Background()); err != nil || got != "Bearer t1" {
+		t.Fatalf("Authorization() = %q, %v", got, err)
+	}
+
+	<-iam.holding
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if got, err := c.Authorization(ctx); err != nil || got != "Bearer t1" {
+		t.Fatalf("Authorization() during refresh = %q, %v", got, err)
+	}
+	close(iam.hold)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, _ := c.Authorization(context.Background())
+		if got != "Bearer t1" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("token not renewed, still %q", got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServiceAccountExchangeError(t *testing.T) {
+	iam := &fakeIAM{status: http.StatusUnauthorized}
+	c := newServiceAccount(t, iam, time.Minute)
+	if _, err := c.Authorization(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("Authorization() error = %v, want status 401", err)
+	}
+}
+
+func TestServiceAccountCallerGivesUp(t *testing.T) {
+	iam := &fakeIAM{ttl: time.Hour, delay: 200 * time.Millisecond}
+	c := newServiceAccount(t, iam, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Authorization(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Authorization() error = %v, want deadline exceeded", err)
+	}
+	if got, err := c.Authorization(context.Background()); err != nil || got != "Bearer t1" {
+		t.Fatalf("Authorization() = %q, %v", got, err)
+	}
+}
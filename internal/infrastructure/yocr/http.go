@@ -0,0 +1,94 @@
+package yocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+type ycError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// doJSON performs an authorized Yandex Cloud call. A nil payload sends no
+// body. On non-2xx responses the raw body and status are returned together
+// with an error carrying the API message.
+func doJSON(ctx context.Context, hc *http.Client, creds Credentials, folderID, method, url string, payload any) (raw []byte, status int, err error) {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return nil, 0, fmt.Errorf("marshal request: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("build request: %w", err)
+	}
+	authz, err := creds.Authorization(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("credentials: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", authz)
+	req.Header.Set("x-folder-id", folderID)
+
+	res, err := hc.Do(req)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, 0, err
+		}
+		return nil, 0, &transportError{fmt.Errorf("http do: %w", err)}
+	}
+	defer res.Body.Close()
+
+	raw, readErr := io.ReadAll(res.Body)
+	if readErr != nil {
+		return nil, res.StatusCode, &transportError{fmt.Errorf("read body: %w", readErr)}
+	}
+
+	if res.StatusCode/100 != 2 {
+		var apiErr ycError
+		_ = json.Unmarshal(raw, &apiErr)
+		if apiErr.Message != "" {
+			err = fmt.Errorf("%s (code=%d)", apiErr.Message, apiErr.Code)
+		} else {
+			err = fmt.Errorf("unexpected status %d: %s", res.StatusCode, string(raw))
+		}
+		return raw, res.StatusCode, classify(res.StatusCode, err)
+	}
+
+	return raw, res.StatusCode, nil
+}
+
+// transportError is a call that failed on the wire, sending the request or
+// reading the response, as opposed to one that could not be made at all
+// (credentials, a malformed request). Only the former may succeed if tried
+// again.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string { return e.err.Error() }
+func (e *transportError) Unwrap() error { return e.err }
+
+func classify(status int, err error) error {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", recognize.ErrQuotaExceeded, err)
+	case status >= 500:
+		return fmt.Errorf("%w: %w", recognize.ErrUnavailable, err)
+	}
+	return err
+}
@@ -1,42 +1,59 @@
 package yocr
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"doc2text/internal/core/abstraction/recognize"
 )
 
+// Public endpoints of the two synchronous APIs: New speaks Vision
+// batchAnalyze, NewTextRecognizer OCR recognizeText.
+const (
+	VisionEndpoint = "https://vision.api.cloud.yandex.net/vision/v1/batchAnalyze"
+	TextEndpoint   = "https://ocr.api.cloud.yandex.net/ocr/v1/recognizeText"
+)
+
 type Options struct {
 	OcrEndpoint string
 	ApiKey      string
 	FolderID    string
 	Model       string
 	Languages   []string
+	// Credentials take precedence over ApiKey when set.
+	Credentials Credentials
+	// MinConfidence drops words and lines recognized with lower confidence.
+	// Zero disables filtering.
+	MinConfidence float64
 }
 
 type ycRecognizer struct {
-	ocrEndpoint string
-	apiKey      string
-	folderID    string
-	model       string
-	languages   []string
-	http        *http.Client
+	ocrEndpoint   string
+	creds         Credentials
+	folderID      string
+	model         string
+	languages     []string
+	minConfidence float64
+	http          *http.Client
 }
 
 func New(o Options) recognize.Recognizer {
+	if o.Credentials == nil {
+		o.Credentials = APIKey(o.ApiKey)
+	}
 	return &ycRecognizer{
-		ocrEndpoint: o.OcrEndpoint,
-		apiKey:      o.ApiKey,
-		folderID:    o.FolderID,
-		model:       o.Model,
-		languages:   o.Languages,
+		ocrEndpoint:   o.OcrEndpoint,
+		creds:         o.Credentials,
+		folderID:      o.FolderID,
+		model:         o.Model,
+		languages:     o.Languages,
+		minConfidence: o.MinConfidence,
 		http: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -59,103 +76,206 @@ func (r *ycRecognizer) Recognize(ctx context.Context, req recognize.Request) (re
 		return recognize.Response{}, err
 	}
 
-	text, err := r.parse(raw)
+	res, err := r.parse(raw)
 	if err != nil {
 		return recognize.Response{}, err
 	}
 
-	return recognize.Response{ExtractedText: text}, nil
+	minConf := r.minConfidence
+	if req.MinConfidence != nil {
+		minConf = *req.MinConfidence
+	}
+	res.Pages, res.FilteredWords, res.FilteredLines = recognize.FilterByConfidence(res.Pages, minConf)
+	res.ExtractedText = recognize.JoinText(res.Pages)
+
+	return res, nil
 }
 
 type ycRequest struct {
-	MimeType      string   `json:"mimeType"`
-	LanguageCodes []string `json:"languageCodes,omitempty"`
-	Model         string   `json:"model,omitempty"`
-	ContentB64    string   `json:"content"`
+	FolderID     string          `json:"folderId,omitempty"`
+	AnalyzeSpecs []ycAnalyzeSpec `json:"analyze_specs"`
 }
 
-type ycError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+type ycAnalyzeSpec struct {
+	ContentB64 string      `json:"content"`
+	MimeType   string      `json:"mime_type,omitempty"`
+	Features   []ycFeature `json:"features"`
+}
+
+type ycFeature struct {
+	Type       string          `json:"type"`
+	TextConfig ycTextDetection `json:"text_detection_config"`
+}
+
+type ycTextDetection struct {
+	LanguageCodes []string `json:"language_codes,omitempty"`
+	Model         string   `json:"model,omitempty"`
 }
 
 func (r *ycRecognizer) send(ctx context.Context, mimeType, contentB64 string) (raw []byte, status int, err error) {
 	payload := ycRequest{
-		MimeType:      mimeType,
-		LanguageCodes: r.languages,
-		Model:         r.model,
-		ContentB64:    contentB64,
-	}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, 0, fmt.Errorf("marshal request: %w", err)
+		FolderID: r.folderID,
+		AnalyzeSpecs: []ycAnalyzeSpec{{
+			ContentB64: contentB64,
+			MimeType:   mimeType,
+			Features: []ycFeature{{
+				Type: "TEXT_DETECTION",
+				TextConfig: ycTextDetection{
+					LanguageCodes: r.languages,
+					Model:         r.model,
+				},
+			}},
+		}},
 	}
+	return doJSON(ctx, r.http, r.creds, r.folderID, http.MethodPost, r.ocrEndpoint, payload)
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.ocrEndpoint, bytes.NewReader(body))
-	if err != nil {
-		return nil, 0, fmt.Errorf("build request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Api-Key "+r.apiKey)
-	req.Header.Set("x-folder-id", r.folderID)
+// ycInt accepts both JSON numbers and the quoted int64 values the Vision API
+// emits for coordinates and page sizes.
+type ycInt int64
 
-	res, err := r.http.Do(req)
+func (v *ycInt) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*v = 0
+		return nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-			return nil, 0, err
-		}
-		return nil, 0, fmt.Errorf("http do: %w", err)
+		return err
 	}
-	defer res.Body.Close()
+	*v = ycInt(n)
+	return nil
+}
 
-	raw, readErr := io.ReadAll(res.Body)
-	if readErr != nil {
-		return nil, res.StatusCode, fmt.Errorf("read body: %w", readErr)
-	}
+type ycVertex struct {
+	X ycInt `json:"x"`
+	Y ycInt `json:"y"`
+}
 
-	if res.StatusCode/100 != 2 {
-		var apiErr ycError
-		_ = json.Unmarshal(raw, &apiErr)
-		if apiErr.Message != "" {
-			return raw, res.StatusCode, fmt.Errorf("%s (code=%d)", apiErr.Message, apiErr.Code)
-		}
-		return raw, res.StatusCode, fmt.Errorf("unexpected status %d: %s", res.StatusCode, string(raw))
-	}
+type ycBoundingBox struct {
+	Vertices []ycVertex `json:"vertices"`
+}
+
+type ycLanguage struct {
+	LanguageCode string  `json:"languageCode"`
+	Confidence   float64 `json:"confidence"`
+}
+
+type ycWord struct {
+	BoundingBox ycBoundingBox `json:"boundingBox"`
+	Text        string        `json:"text"`
+	Confidence  float64       `json:"confidence"`
+	Languages   []ycLanguage  `json:"languages"`
+}
+
+type ycLine struct {
+	BoundingBox ycBoundingBox `json:"boundingBox"`
+	Words       []ycWord      `json:"words"`
+	Confidence  float64       `json:"confidence"`
+}
+
+type ycBlock struct {
+	BoundingBox ycBoundingBox `json:"boundingBox"`
+	Lines       []ycLine      `json:"lines"`
+	Languages   []ycLanguage  `json:"languages"`
+}
 
-	return raw, res.StatusCode, nil
+type ycPage struct {
+	Width  ycInt     `json:"width"`
+	Height ycInt     `json:"height"`
+	Blocks []ycBlock `json:"blocks"`
 }
 
 type ycResponse struct {
-	Result struct {
-		TextAnnotation struct {
-			FullText string `json:"fullText"`
-			Blocks   []struct {
-				Lines []struct {
-					Text string `json:"text"`
-				} `json:"lines"`
-			} `json:"blocks"`
-		} `json:"textAnnotation"`
-	} `json:"result"`
-}
-
-func (r *ycRecognizer) parse(raw []byte) (string, error) {
+	Results []struct {
+		Results []struct {
+			TextDetection struct {
+				Pages []ycPage `json:"pages"`
+			} `json:"textDetection"`
+			Error *ycError `json:"error"`
+		} `json:"results"`
+		Error *ycError `json:"error"`
+	} `json:"results"`
+}
+
+func (r *ycRecognizer) parse(raw []byte) (recognize.Response, error) {
 	var ycr ycResponse
 	if err := json.Unmarshal(raw, &ycr); err != nil {
-		return "", fmt.Errorf("unmarshal response: %w", err)
+		return recognize.Response{}, fmt.Errorf("unmarshal response: %w", err)
 	}
 
-	if txt := ycr.Result.TextAnnotation.FullText; txt != "" {
-		return txt, nil
+	var pages []recognize.Page
+	for _, spec := range ycr.Results {
+		if spec.Error != nil && spec.Error.Message != "" {
+			return recognize.Response{}, fmt.Errorf("%s (code=%d)", spec.Error.Message, spec.Error.Code)
+		}
+		for _, feat := range spec.Results {
+			if feat.Error != nil && feat.Error.Message != "" {
+				return recognize.Response{}, fmt.Errorf("%s (code=%d)", feat.Error.Message, feat.Error.Code)
+			}
+			for _, p := range feat.TextDetection.Pages {
+				pages = append(pages, toPage(p))
+			}
+		}
 	}
 
-	var buf bytes.Buffer
-	for _, b := range ycr.Result.TextAnnotation.Blocks {
+	return recognize.Response{ExtractedText: recognize.JoinText(pages), Pages: pages}, nil
+}
+
+func toPage(p ycPage) recognize.Page {
+	page := recognize.Page{
+		Width:  int64(p.Width),
+		Height: int64(p.Height),
+		Blocks: make([]recognize.Block, 0, len(p.Blocks)),
+	}
+	for _, b := range p.Blocks {
+		block := recognize.Block{
+			BoundingBox: toPolygon(b.BoundingBox),
+			Languages:   toLanguages(b.Languages),
+			Lines:       make([]recognize.Line, 0, len(b.Lines)),
+		}
 		for _, ln := range b.Lines {
-			if ln.Text != "" {
-				buf.WriteString(ln.Text)
-				buf.WriteByte('\n')
+			line := recognize.Line{
+				BoundingBox: toPolygon(ln.BoundingBox),
+				Confidence:  ln.Confidence,
+				Words:       make([]recognize.Word, 0, len(ln.Words)),
+			}
+			texts := make([]string, 0, len(ln.Words))
+			for _, w := range ln.Words {
+				line.Words = append(line.Words, recognize.Word{
+					Text:        w.Text,
+					BoundingBox: toPolygon(w.BoundingBox),
+					Confidence:  w.Confidence,
+					Languages:   toLanguages(w.Languages),
+				})
+				if w.Text != "" {
+					texts = append(texts, w.Text)
+				}
 			}
+			line.Text = strings.Join(texts, " ")
+			block.Lines = append(block.Lines, line)
 		}
+		page.Blocks = append(page.Blocks, block)
+	}
+	return page
+}
+
+func toPolygon(bb ycBoundingBox) recognize.Polygon {
+	poly := recognize.Polygon{Vertices: make([]recognize.Point, 0, len(bb.Vertices))}
+	for _, v := range bb.Vertices {
+		poly.Vertices = append(poly.Vertices, recognize.Point{X: int64(v.X), Y: int64(v.Y)})
+	}
+	return poly
+}
+
+func toLanguages(ls []ycLanguage) []recognize.Language {
+	if len(ls) == 0 {
+		return nil
+	}
+	out := make([]recognize.Language, 0, len(ls))
+	for _, l := range ls {
+		out = append(out, recognize.Language{Code: l.LanguageCode, Confidence: l.Confidence})
 	}
-	return buf.String(), nil
+	return out
 }
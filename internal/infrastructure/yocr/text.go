@@ -0,0 +1,217 @@
+package yocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"doc2text/internal/core/abstraction/recognize"
+)
+
+// TextOptions configure the recognizer for the ocr/v1/recognizeText API.
+// That API reports no per-element confidence, so there is no threshold here.
+type TextOptions struct {
+	OcrEndpoint string
+	FolderID    string
+	// Model is one of page, page-column-sort, handwritten, table, etc.
+	Model       string
+	Languages   []string
+	Credentials Credentials
+
+	// AsyncPDF sends PDFs through recognizeTextAsync and polls the
+	// operation instead of hitting the synchronous page limits.
+	AsyncPDF          bool
+	AsyncEndpoint     string
+	ResultEndpoint    string
+	OperationEndpoint string
+	PollInterval      time.Duration
+	MaxPollInterval   time.Duration
+}
+
+type textRecognizer struct {
+	ocrEndpoint string
+	folderID    string
+	model       string
+	languages   []string
+	creds       Credentials
+	http        *http.Client
+
+	asyncPDF          bool
+	asyncEndpoint     string
+	resultEndpoint    string
+	operationEndpoint string
+	pollInterval      time.Duration
+	maxPollInterval   time.Duration
+}
+
+func NewTextRecognizer(o TextOptions) recognize.Recognizer {
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultPollInterval
+	}
+	if o.MaxPollInterval < o.PollInterval {
+		o.MaxPollInterval = max(defaultMaxPollInterval, o.PollInterval)
+	}
+	return &textRecognizer{
+		ocrEndpoint: o.OcrEndpoint,
+		folderID:    o.FolderID,
+		model:       o.Model,
+		languages:   o.Languages,
+		creds:       o.Credentials,
+		http: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		asyncPDF:          o.AsyncPDF,
+		asyncEndpoint:     o.AsyncEndpoint,
+		resultEndpoint:    o.ResultEndpoint,
+		operationEndpoint: o.OperationEndpoint,
+		pollInterval:      o.PollInterval,
+		maxPollInterval:   o.MaxPollInterval,
+	}
+}
+
+func (r *textRecognizer) Recognize(ctx context.Context, req recognize.Request) (recognize.Response, error) {
+	if req.ContentBase64 == "" {
+		return recognize.Response{}, errors.New("empty ContentBase64")
+	}
+	if req.MimeType == "" {
+		return recognize.Response{}, errors.New("empty MimeType")
+	}
+
+	payload := rtRequest{
+		MimeType:      textMimeType(req.MimeType),
+		LanguageCodes: r.languages,
+		Model:         r.model,
+		ContentB64:    req.ContentBase64,
+	}
+	if r.asyncPDF && payload.MimeType == "PDF" {
+		return r.recognizeAsync(ctx, payload)
+	}
+
+	raw, status, err := doJSON(ctx, r.http, r.creds, r.folderID, http.MethodPost, r.ocrEndpoint, payload)
+	if err != nil {
+		if status != 0 {
+			return recognize.Response{}, fmt.Errorf("yandex ocr http %d: %w", status, err)
+		}
+		return recognize.Response{}, err
+	}
+
+	pages, err := parseTextResults(raw)
+	if err != nil {
+		return recognize.Response{}, err
+	}
+	return recognize.Response{ExtractedText: recognize.JoinText(pages), Pages: pages}, nil
+}
+
+type rtRequest struct {
+	MimeType      string   `json:"mimeType"`
+	LanguageCodes []string `json:"languageCodes,omitempty"`
+	Model         string   `json:"model,omitempty"`
+	ContentB64    string   `json:"content"`
+}
+
+type rtLine struct {
+	BoundingBox ycBoundingBox `json:"boundingBox"`
+	Text        string        `json:"text"`
+	Words       []struct {
+		BoundingBox ycBoundingBox `json:"boundingBox"`
+		Text        string        `json:"text"`
+	} `json:"words"`
+}
+
+type rtBlock struct {
+	BoundingBox ycBoundingBox `json:"boundingBox"`
+	Lines       []rtLine      `json:"lines"`
+	Languages   []ycLanguage  `json:"languages"`
+}
+
+type rtAnnotation struct {
+	Width  ycInt     `json:"width"`
+	Height ycInt     `json:"height"`
+	Blocks []rtBlock `json:"blocks"`
+}
+
+type rtResult struct {
+	Result struct {
+		TextAnnotation rtAnnotation `json:"textAnnotation"`
+		Page           ycInt        `json:"page"`
+	} `json:"result"`
+	Error *ycError `json:"error"`
+}
+
+// parseTextResults reads one or more concatenated result objects (multi-page
+// PDFs yield one per page) and returns the pages in page order.
+func parseTextResults(raw []byte) ([]recognize.Page, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	var results []rtResult
+	for {
+		var res rtResult
+		if err := dec.Decode(&res); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("unmarshal response: %w", err)
+		}
+		if res.Error != nil && res.Error.Message != "" {
+			return nil, fmt.Errorf("%s (code=%d)", res.Error.Message, res.Error.Code)
+		}
+		results = append(results, res)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Result.Page < results[j].Result.Page })
+
+	pages := make([]recognize.Page, 0, len(results))
+	for _, res := range results {
+		pages = append(pages, toTextPage(res.Result.TextAnnotation))
+	}
+	return pages, nil
+}
+
+func toTextPage(a rtAnnotation) recognize.Page {
+	page := recognize.Page{
+		Width:  int64(a.Width),
+		Height: int64(a.Height),
+		Blocks: make([]recognize.Block, 0, len(a.Blocks)),
+	}
+	for _, b := range a.Blocks {
+		block := recognize.Block{
+			BoundingBox: toPolygon(b.BoundingBox),
+			Languages:   toLanguages(b.Languages),
+			Lines:       make([]recognize.Line, 0, len(b.Lines)),
+		}
+		for _, ln := range b.Lines {
+			line := recognize.Line{
+				Text:        ln.Text,
+				BoundingBox: toPolygon(ln.BoundingBox),
+				Words:       make([]recognize.Word, 0, len(ln.Words)),
+			}
+			for _, w := range ln.Words {
+				line.Words = append(line.Words, recognize.Word{
+					Text:        w.Text,
+					BoundingBox: toPolygon(w.BoundingBox),
+				})
+			}
+			block.Lines = append(block.Lines, line)
+		}
+		page.Blocks = append(page.Blocks, block)
+	}
+	return page
+}
+
+// textMimeType maps a MIME type onto the short names recognizeText expects.
+func textMimeType(mimeType string) string {
+	switch strings.ToLower(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0])) {
+	case "image/jpeg", "image/jpg":
+		return "JPEG"
+	case "image/png":
+		return "PNG"
+	case "application/pdf":
+		return "PDF"
+	}
+	return mimeType
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"doc2text/internal/core/abstraction/cqrs"
+	"doc2text/internal/core/abstraction/logger"
+	"doc2text/internal/core/usecase/extractjobs"
+)
+
+const maxEventSize = 1 << 20
+
+type NotifyOptions struct {
+	Path string
+	// AuthToken must come as "Authorization: Bearer <token>", which is how
+	// MinIO sends its webhook auth_token. Empty accepts any caller, which
+	// the config does not allow.
+	AuthToken string
+	// Only objects of Bucket under Prefix, ending in one of Suffixes when
+	// any are given, are recognized.
+	Bucket   string
+	Prefix   string
+	Suffixes []string
+	// OutputSuffix is appended to the object key to name the text written
+	// back; empty leaves writing to sidecars. Keys ending in it or in one
+	// of IgnoreSuffixes are skipped so results do not loop.
+	OutputSuffix   string
+	IgnoreSuffixes []string
+	Logger         logger.Logger
+}
+
+// s3Event is the S3 event notification format, which MinIO also uses.
+type s3Event struct {
+	Records []struct {
+		EventName string `json:"eventName"`
+		S3        struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key       string `json:"key"`
+				ETag      string `json:"eTag"`
+				Sequencer string `json:"sequencer"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+}
+
+type notifyHandler struct {
+	bus  *cqrs.Bus
+	opts NotifyOptions
+}
+
+// ServeHTTP queues a job per created object. Jobs are keyed by bucket, key
+// and ETag, so a replayed event returns the job queued the first time. A
+// failed submit answers 503, and the sender retries the whole event.
+func (h *notifyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.opts.AuthToken != "" {
+		want := "Bearer " + h.opts.AuthToken
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	var ev s3Event
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEventSize)).Decode(&ev); err != nil {
+		http.Error(w, "malformed event: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, rec := range ev.Records {
+		if !strings.Contains(rec.EventName, "ObjectCreated:") || rec.S3.Bucket.Name != h.opts.Bucket {
+			continue
+		}
+		// Keys arrive URL-encoded, with spaces as "+".
+		key, err := url.QueryUnescape(rec.S3.Object.Key)
+		if err != nil {
+			http.Error(w, "malformed object key: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !h.matches(key) {
+			continue
+		}
+		version := strings.Trim(rec.S3.Object.ETag, `"`)
+		if version == "" {
+			version = rec.S3.Object.Sequencer
+		}
+		if version == "" {
+			// Without a version every later upload of the key would get
+			// the job of the first one back.
+			h.opts.Logger.Error("notify: %s: event has neither eTag nor sequencer, skipped", key)
+			continue
+		}
+		c := extractjobs.SubmitJob{
+			ObjectKey:      key,
+			IdempotencyKey: "s3:" + rec.S3.Bucket.Name + "/" + key + "@" + version,
+		}
+		if h.opts.OutputSuffix != "" {
+			c.OutputKey = key + h.opts.OutputSuffix
+		}
+		j, err := cqrs.Exec[extractjobs.SubmitJob, extractjobs.Job](h.bus, r.Context(), c)
+		if err != nil {
+			h.opts.Logger.Error("notify: submit %s: %v", key, err)
+			http.Error(w, "cannot queue "+key, http.StatusServiceUnavailable)
+			return
+		}
+		h.opts.Logger.Info("notify: %s -> job %s (%s)", key, j.ID, j.State)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *notifyHandler) matches(key string) bool {
+	if !strings.HasPrefix(key, h.opts.Prefix) {
+		return false
+	}
+	if h.opts.OutputSuffix != "" && strings.HasSuffix(key, h.opts.OutputSuffix) {
+		return false
+	}
+	for _, s := range h.opts.IgnoreSuffixes {
+		if strings.HasSuffix(key, s) {
+			return false
+		}
+	}
+	return len(h.opts.Suffixes) == 0 || hasSuffixFold(key, h.opts.Suffixes)
+}
+
+func hasSuffixFold(key string, suffixes []string) bool {
+	lower := strings.ToLower(key)
+	for _, s := range suffixes {
+		if strings.HasSuffix(lower, strings.ToLower(s)) {
+			return true
+		}
+	}
+	return false
+}
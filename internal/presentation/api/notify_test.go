@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"doc2text/internal/core/abstraction/cqrs"
+	"doc2text/internal/core/usecase/extractjobs"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// fakeJobs hands out one job per idempotency key, as the job store does.
+type fakeJobs struct {
+	submitted []extractjobs.SubmitJob
+	ids       map[string]string
+}
+
+func (f *fakeJobs) Handle(_ context.Context, c extractjobs.SubmitJob) (extractjobs.Job, error) {
+	f.submitted = append(f.submitted, c)
+	id, ok := f.ids[c.IdempotencyKey]
+	if !ok {
+		id = fmt.Sprintf("job-%d", len(f.ids)+1)
+		f.ids[c.IdempotencyKey] = id
+	}
+	return extractjobs.Job{ID: id, ObjectKey: c.ObjectKey}, nil
+}
+
+func newNotify(t *testing.T) (http.Handler, *fakeJobs) {
+	t.Helper()
+	jobs := &fakeJobs{ids: map[string]string{}}
+	bus := cqrs.NewBus()
+	cqrs.RegisterCommand[extractjobs.SubmitJob, extractjobs.Job](bus, jobs)
+	return NewRouter(Options{
+		HealthCheckPath: "/healthz",
+		Bus:             bus,
+		Notify: &NotifyOptions{
+			Path:           "/s3/events",
+			AuthToken:      "secret",
+			Bucket:         "docs",
+			Prefix:         "inbox/",
+			Suffixes:       []string{".pdf", ".png"},
+			OutputSuffix:   ".txt",
+			IgnoreSuffixes: []string{".ocr.json"},
+			Logger:         nopLogger{},
+		},
+	}), jobs
+}
+
+func event(key, etag, sequencer string) string {
+	return fmt.Sprintf(`{"Records":[{"eventName":"s3:ObjectCreated:Put","s3":{"bucket":{"name":"docs"},"object":{"key":%q,"eTag":%q,"sequencer":%q}}}]}`, key, etag, sequencer)
+}
+
+func post(h http.Handler, token, body string) int {
+	req := httptest.NewRequest(http.MethodPost, "/s3/events", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestNotifyRequiresToken(t *testing.T) {
+	h, jobs := newNotify(t)
+	for _, token := range []string{"", "wrong"} {
+		if code := post(h, token, event("inbox/a.pdf", "e1", "")); code != http.StatusUnauthorized {
+			t.Errorf("token %q: status %d, want 401", token, code)
+		}
+	}
+	if len(jobs.submitted) != 0 {
+		t.Fatalf("submitted %d jobs without auth", len(jobs.submitted))
+	}
+}
+
+func TestNotifyDecodesKey(t *testing.T) {
+	h, jobs := newNotify(t)
+	if code := post(h, "secret", event("inbox/My+Scan%C3%A9+%281%29.PDF", "e1", "")); code != http.StatusAccepted {
+		t.Fatalf("status %d", code)
+	}
+	if len(jobs.submitted) != 1 {
+		t.Fatalf("submitted %d jobs, want 1", len(jobs.submitted))
+	}
+	c := jobs.submitted[0]
+	if c.ObjectKey != "inbox/My Scané (1).PDF" || c.OutputKey != "inbox/My Scané (1).PDF.txt" {
+		t.Fatalf("submitted %+v", c)
+	}
+	if code := post(h, "secret", event("inbox/%zz.pdf", "e1", "")); code != http.StatusBadRequest {
+		t.Fatalf("malformed key: status %d, want 400", code)
+	}
+}
+
+func TestNotifyReplayIsIdempotent(t *testing.T) {
+	h, jobs := newNotify(t)
+	post(h, "secret", event("inbox/a.pdf", `"e1"`, "s1"))
+	post(h, "secret", event("inbox/a.pdf", `"e1"`, "s2"))
+	post(h, "secret", event("inbox/a.pdf", `"e2"`, "s3"))
+	// MinIO may omit the ETag; the sequencer then tells uploads apart.
+	post(h, "secret", event("inbox/a.pdf", "", "s4"))
+
+	if len(jobs.submitted) != 4 {
+		t.Fatalf("submitted %d jobs, want 4", len(jobs.submitted))
+	}
+	keys := make([]string, len(jobs.submitted))
+	for i, c := range jobs.submitted {
+		keys[i] = c.IdempotencyKey
+	}
+	if keys[0] != keys[1] || keys[0] != "s3:docs/inbox/a.pdf@e1" {
+		t.Fatalf("replayed event keys = %q", keys[:2])
+	}
+	if keys[2] == keys[0] || keys[3] == keys[2] || keys[3] != "s3:docs/inbox/a.pdf@s4" {
+		t.Fatalf("new uploads share a key: %q", keys)
+	}
+	if len(jobs.ids) != 3 {
+		t.Fatalf("%d jobs created, want 3", len(jobs.ids))
+	}
+}
+
+func TestNotifySkipsUnversionedRecord(t *testing.T) {
+	h, jobs := newNotify(t)
+	if code := post(h, "secret", event("inbox/a.pdf", "", "")); code != http.StatusAccepted {
+		t.Fatalf("status %d", code)
+	}
+	if len(jobs.submitted) != 0 {
+		t.Fatalf("submitted %+v for a record without eTag or sequencer", jobs.submitted)
+	}
+}
+
+func TestNotifyMatches(t *testing.T) {
+	h := &notifyHandler{opts: NotifyOptions{
+		Prefix:         "inbox/",
+		Suffixes:       []string{".pdf", ".png"},
+		OutputSuffix:   ".txt",
+		IgnoreSuffixes: []string{".ocr.json"},
+	}}
+	for key, want := range map[string]bool{
+		"inbox/a.pdf":          true,
+		"inbox/sub/b.PNG":      true,
+		"outbox/a.pdf":         false,
+		"inbox/a.docx":         false,
+		"inbox/a.pdf.txt":      false,
+		"inbox/a.pdf.ocr.json": false,
+	} {
+		if got := h.matches(key); got != want {
+			t.Errorf("matches(%q) = %t, want %t", key, got, want)
+		}
+	}
+
+	any := &notifyHandler{opts: NotifyOptions{Prefix: "inbox/", OutputSuffix: ".txt"}}
+	if !any.matches("inbox/a.docx") || any.matches("inbox/a.txt") {
+		t.Error("without suffixes every key but the output should match")
+	}
+}
+
+func TestNotifyIgnoresOtherEvents(t *testing.T) {
+	h, jobs := newNotify(t)
+	body := `{"Records":[
+		{"eventName":"s3:ObjectRemoved:Delete","s3":{"bucket":{"name":"docs"},"object":{"key":"inbox/a.pdf","eTag":"e1"}}},
+		{"eventName":"s3:ObjectCreated:Put","s3":{"bucket":{"name":"other"},"object":{"key":"inbox/a.pdf","eTag":"e1"}}}]}`
+	if code := post(h, "secret", body); code != http.StatusAccepted {
+		t.Fatalf("status %d", code)
+	}
+	if len(jobs.submitted) != 0 {
+		t.Fatalf("submitted %+v", jobs.submitted)
+	}
+}
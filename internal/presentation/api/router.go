@@ -2,10 +2,15 @@ package api
 
 import (
 	"net/http"
+
+	"doc2text/internal/core/abstraction/cqrs"
 )
 
 type Options struct {
 	HealthCheckPath string
+	// Notify, if set, serves bucket notifications; Bus takes the jobs.
+	Notify *NotifyOptions
+	Bus    *cqrs.Bus
 }
 
 func NewRouter(opt Options) *http.ServeMux {
@@ -17,5 +22,9 @@ func NewRouter(opt Options) *http.ServeMux {
 		_, _ = w.Write([]byte("ok"))
 	})
 
+	if opt.Notify != nil {
+		mux.Handle("POST "+opt.Notify.Path, &notifyHandler{bus: opt.Bus, opts: *opt.Notify})
+	}
+
 	return mux
 }
@@ -23,9 +23,45 @@ const (
 )
 
 func NewUnaryAuthInterceptor(cfg config.OIDC) (grpc.UnaryServerInterceptor, error) {
-	if cfg.Issuer == "" || cfg.JWKSURL == "" || cfg.Audience == "" {
+	authenticate := newAuthenticator(cfg)
+	if authenticate == nil {
 		return nil, nil
 	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}, nil
+}
+
+// NewStreamAuthInterceptor guards streaming RPCs the same way.
+func NewStreamAuthInterceptor(cfg config.OIDC) (grpc.StreamServerInterceptor, error) {
+	authenticate := newAuthenticator(cfg)
+	if authenticate == nil {
+		return nil, nil
+	}
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authedStream{ServerStream: ss, ctx: ctx})
+	}, nil
+}
+
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authedStream) Context() context.Context { return s.ctx }
+
+func newAuthenticator(cfg config.OIDC) func(ctx context.Context) (context.Context, error) {
+	if cfg.Issuer == "" || cfg.JWKSURL == "" || cfg.Audience == "" {
+		return nil
+	}
 
 	keySet := oidc.NewRemoteKeySet(context.Background(), cfg.JWKSURL)
 	verifier := oidc.NewVerifier(cfg.Issuer, keySet, &oidc.Config{ClientID: cfg.Audience})
@@ -39,7 +75,7 @@ func NewUnaryAuthInterceptor(cfg config.OIDC) (grpc.UnaryServerInterceptor, erro
 		Iss      string      `json:"iss"`
 	}
 
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return func(ctx context.Context) (context.Context, error) {
 		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
 			return nil, status.Error(codes.Unauthenticated, "missing metadata")
@@ -83,7 +119,6 @@ func NewUnaryAuthInterceptor(cfg config.OIDC) (grpc.UnaryServerInterceptor, erro
 		if claims.ClientID != "" {
 			ctx = context.WithValue(ctx, CtxClientID, claims.ClientID)
 		}
-
-		return handler(ctx, req)
-	}, nil
+		return ctx, nil
+	}
 }
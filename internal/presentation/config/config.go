@@ -5,26 +5,150 @@ import (
 	"time"
 
 	"github.com/caarlos0/env/v11"
+	"github.com/dustin/go-humanize"
 	"github.com/go-playground/validator/v10"
 )
 
 type GRpcServer struct {
-	Addr string `env:"ADDR" envDefault:":8080" validate:"required"`
+	// Disable to serve only from queues.
+	Enabled       bool     `env:"ENABLED" envDefault:"true"`
+	Addr          string   `env:"ADDR" envDefault:":8080" validate:"required"`
+	MaxUploadSize ByteSize `env:"MAX_UPLOAD_SIZE" envDefault:"32MiB" validate:"gt=0"`
 }
 
 type HttpServer struct {
 	Addr string `env:"ADDR"               envDefault:":8090" validate:"required"`
 }
 
+// Yandex configures the OCR client. An empty Endpoint picks the public
+// endpoint of the selected API.
 type Yandex struct {
-	APIKey        string        `env:"API_KEY"`
-	IAMToken      string        `env:"IAM_TOKEN"`
-	FolderID      string        `env:"FOLDER_ID"`
-	Endpoint      string        `env:"ENDPOINT"      envDefault:"https://vision.api.cloud.yandex.net/vision/v1/batchAnalyze"`
-	Model         string        `env:"DEFAULT_MODEL" envDefault:"page"`
-	MinConfidence float64       `env:"MIN_CONFIDENCE" envDefault:"0.6" validate:"gte=0,lte=1"`
-	HTTPTimeout   time.Duration `env:"HTTP_TIMEOUT"  envDefault:"15s" validate:"gt=0"`
-	Languages     []string      `env:"LANGUAGES" envSeparator:"," envDefault:"ru,en"`
+	APIKey            string        `env:"API_KEY"`
+	IAMToken          string        `env:"IAM_TOKEN"`
+	SAKeyFile         string        `env:"SA_KEY_FILE"`
+	IAMEndpoint       string        `env:"IAM_ENDPOINT"  envDefault:"https://iam.api.cloud.yandex.net/iam/v1/tokens" validate:"url"`
+	FolderID          string        `env:"FOLDER_ID"`
+	API               string        `env:"API"           envDefault:"recognizeText" validate:"oneof=batchAnalyze recognizeText"`
+	Endpoint          string        `env:"ENDPOINT"`
+	AsyncPDF          bool          `env:"ASYNC_PDF"     envDefault:"false"`
+	AsyncEndpoint     string        `env:"ASYNC_ENDPOINT" envDefault:"https://ocr.api.cloud.yandex.net/ocr/v1/recognizeTextAsync"`
+	ResultEndpoint    string        `env:"RESULT_ENDPOINT" envDefault:"https://ocr.api.cloud.yandex.net/ocr/v1/getRecognition"`
+	OperationEndpoint string        `env:"OPERATION_ENDPOINT" envDefault:"https://operation.api.cloud.yandex.net/operations"`
+	PollInterval      time.Duration `env:"POLL_INTERVAL" envDefault:"1s" validate:"gt=0"`
+	MaxPollInterval   time.Duration `env:"MAX_POLL_INTERVAL" envDefault:"15s" validate:"gtefield=PollInterval"`
+	Model             string        `env:"DEFAULT_MODEL" envDefault:"page"`
+	MinConfidence     float64       `env:"MIN_CONFIDENCE" envDefault:"0.6" validate:"gte=0,lte=1"`
+	HTTPTimeout       time.Duration `env:"HTTP_TIMEOUT"  envDefault:"15s" validate:"gt=0"`
+	Languages         []string      `env:"LANGUAGES" envSeparator:"," envDefault:"ru,en"`
+	// Zero picks the documented limit of the selected API.
+	MaxImageBytes  ByteSize `env:"MAX_IMAGE_BYTES"`
+	MaxImagePixels int64    `env:"MAX_IMAGE_PIXELS" envDefault:"20000000" validate:"gte=0"`
+}
+
+type Recognizer struct {
+	Backend    string   `env:"BACKEND"     envDefault:"yandex" validate:"oneof=yandex tesseract"`
+	Fallback   []string `env:"FALLBACK"    envSeparator:"," validate:"dive,oneof=yandex tesseract"`
+	FallbackOn []string `env:"FALLBACK_ON" envSeparator:"," envDefault:"quota,unavailable,timeout" validate:"dive,oneof=quota unavailable timeout unsupported any"`
+	Routes     []string `env:"ROUTES"      envSeparator:";"`
+}
+
+type Tesseract struct {
+	Binary        string   `env:"BINARY"         envDefault:"tesseract"`
+	Languages     []string `env:"LANGUAGES"      envSeparator:"," envDefault:"rus,eng"`
+	PSM           int      `env:"PSM"            envDefault:"3" validate:"gte=0,lte=13"`
+	MinConfidence float64  `env:"MIN_CONFIDENCE" envDefault:"0.6" validate:"gte=0,lte=1"`
+	MaxImageSide  int      `env:"MAX_IMAGE_SIDE" envDefault:"0" validate:"gte=0"`
+}
+
+type Extract struct {
+	PDFTextLayer    bool `env:"PDF_TEXT_LAYER"   envDefault:"true"`
+	PDFMinChars     int  `env:"PDF_MIN_CHARS"    envDefault:"16" validate:"gte=1"`
+	PDFSplit        bool `env:"PDF_SPLIT"        envDefault:"true"`
+	PageConcurrency int  `env:"PAGE_CONCURRENCY" envDefault:"4" validate:"gte=1"`
+	Office          bool `env:"OFFICE"           envDefault:"true"`
+	Text            bool `env:"TEXT"             envDefault:"true"`
+}
+
+type Archive struct {
+	Enabled      bool     `env:"ENABLED"        envDefault:"true"`
+	MaxEntries   int      `env:"MAX_ENTRIES"    envDefault:"1000" validate:"gte=1"`
+	MaxTotalSize ByteSize `env:"MAX_TOTAL_SIZE" envDefault:"512MiB" validate:"gt=0"`
+	MaxDepth     int      `env:"MAX_DEPTH"      envDefault:"3" validate:"gte=1"`
+}
+
+type Image struct {
+	Normalize     bool   `env:"NORMALIZE"      envDefault:"true"`
+	MaxPages      int    `env:"MAX_PAGES"      envDefault:"50" validate:"gte=1"`
+	HEICConverter string `env:"HEIC_CONVERTER"`
+	MaxPixels     int64  `env:"MAX_PIXELS"     envDefault:"50000000" validate:"gte=0"`
+	// Retry at 90/180/270° when the mean word confidence is below this.
+	AutoRotate              bool    `env:"AUTO_ROTATE"                envDefault:"false"`
+	AutoRotateMinConfidence float64 `env:"AUTO_ROTATE_MIN_CONFIDENCE" envDefault:"0.5" validate:"gte=0,lte=1"`
+	Deskew                  bool    `env:"DESKEW"                     envDefault:"false"`
+	MaxSkew                 float64 `env:"MAX_SKEW"                   envDefault:"10" validate:"gt=0,lte=45"`
+}
+
+type Batch struct {
+	MaxItems    int `env:"MAX_ITEMS"   envDefault:"100" validate:"gte=1"`
+	Concurrency int `env:"CONCURRENCY" envDefault:"4"   validate:"gte=1"`
+}
+
+type Jobs struct {
+	Enabled   bool   `env:"ENABLED"    envDefault:"false"`
+	StorePath string `env:"STORE_PATH" envDefault:"doc2text-jobs.db" validate:"required"`
+	Workers   int    `env:"WORKERS"    envDefault:"2" validate:"gte=1"`
+	// Timeout bounds one run; ResultTTL is how long finished jobs are kept.
+	Timeout      time.Duration `env:"TIMEOUT"       envDefault:"30m" validate:"gt=0"`
+	ResultTTL    time.Duration `env:"RESULT_TTL"    envDefault:"24h" validate:"gt=0"`
+	PollInterval time.Duration `env:"POLL_INTERVAL" envDefault:"1m"  validate:"gt=0"`
+}
+
+// Webhook configures job callbacks; they are off while Secret is empty.
+type Webhook struct {
+	Secret      string        `env:"SECRET"`
+	Timeout     time.Duration `env:"TIMEOUT"       envDefault:"10s" validate:"gt=0"`
+	MaxAttempts int           `env:"MAX_ATTEMPTS"  envDefault:"8"   validate:"gte=1"`
+	Backoff     time.Duration `env:"BACKOFF"       envDefault:"5s"  validate:"gt=0"`
+	MaxBackoff  time.Duration `env:"MAX_BACKOFF"   envDefault:"10m" validate:"gtefield=Backoff"`
+	// AllowedHosts is required with callbacks on: otherwise any caller
+	// could have the service post to internal addresses.
+	AllowedHosts []string `env:"ALLOWED_HOSTS" envSeparator:"," validate:"required_with=Secret"`
+}
+
+// Kafka enables the queue worker mode next to gRPC.
+type Kafka struct {
+	Enabled         bool          `env:"ENABLED"           envDefault:"false"`
+	Brokers         []string      `env:"BROKERS"           envSeparator:"," validate:"required_if=Enabled true"`
+	GroupID         string        `env:"GROUP_ID"          envDefault:"doc2text"`
+	RequestTopic    string        `env:"REQUEST_TOPIC"     envDefault:"doc2text.requests"`
+	ResultTopic     string        `env:"RESULT_TOPIC"      envDefault:"doc2text.results"`
+	DeadLetterTopic string        `env:"DEAD_LETTER_TOPIC" envDefault:"doc2text.requests.dlq"`
+	Consumers       int           `env:"CONSUMERS"         envDefault:"1"  validate:"gte=1"`
+	MaxAttempts     int           `env:"MAX_ATTEMPTS"      envDefault:"3"  validate:"gte=1"`
+	Backoff         time.Duration `env:"BACKOFF"           envDefault:"2s" validate:"gt=0"`
+}
+
+// NATS enables core request/reply and JetStream consumers next to or
+// instead of gRPC; an empty subject turns its consumer off.
+type NATS struct {
+	Enabled        bool   `env:"ENABLED"         envDefault:"false"`
+	URL            string `env:"URL"             envDefault:"nats://127.0.0.1:4222" validate:"required_if=Enabled true"`
+	CredsFile      string `env:"CREDS_FILE"`
+	RequestSubject string `env:"REQUEST_SUBJECT" envDefault:"doc2text.ocr.request"`
+	QueueGroup     string `env:"QUEUE_GROUP"     envDefault:"doc2text"`
+	// JetStream side: requests are read from JobSubject through a durable
+	// consumer, results and dead letters are published back to the stream.
+	Stream            string        `env:"STREAM"              envDefault:"DOC2TEXT"`
+	CreateStream      bool          `env:"CREATE_STREAM"       envDefault:"true"`
+	StreamMaxAge      time.Duration `env:"STREAM_MAX_AGE"      envDefault:"72h" validate:"gte=0"`
+	JobSubject        string        `env:"JOB_SUBJECT"         envDefault:"doc2text.ocr.jobs"`
+	ResultSubject     string        `env:"RESULT_SUBJECT"      envDefault:"doc2text.ocr.results"`
+	DeadLetterSubject string        `env:"DEAD_LETTER_SUBJECT" envDefault:"doc2text.ocr.dlq"`
+	Durable           string        `env:"DURABLE"             envDefault:"doc2text" validate:"required_with=JobSubject"`
+	MaxDeliver        int           `env:"MAX_DELIVER"         envDefault:"5"   validate:"gte=1"`
+	AckWait           time.Duration `env:"ACK_WAIT"            envDefault:"30s" validate:"gte=1s"`
+	Backoff           time.Duration `env:"BACKOFF"             envDefault:"5s"  validate:"gt=0"`
+	Consumers         int           `env:"CONSUMERS"           envDefault:"1"   validate:"gte=1"`
 }
 
 type S3 struct {
@@ -35,6 +159,38 @@ type S3 struct {
 	UseSSL    bool   `env:"USE_SSL" envDefault:"false"`
 }
 
+// Sidecar stores the text and result of every object read from S3 as
+// <Prefix><key>.txt and <Prefix><key>.ocr.json, in Bucket or the source one.
+type Sidecar struct {
+	Enabled bool   `env:"ENABLED" envDefault:"false"`
+	Bucket  string `env:"BUCKET"`
+	Prefix  string `env:"PREFIX"`
+}
+
+// Notify accepts bucket notifications on the HTTP server and queues new
+// objects as jobs whose text is written back next to them.
+type Notify struct {
+	Enabled      bool     `env:"ENABLED"       envDefault:"false"`
+	Path         string   `env:"PATH"          envDefault:"/s3/events" validate:"startswith=/"`
+	AuthToken    string   `env:"AUTH_TOKEN"    validate:"required_if=Enabled true"`
+	Prefix       string   `env:"PREFIX"        envDefault:"inbox/"`
+	Suffixes     []string `env:"SUFFIXES"      envSeparator:","`
+	OutputSuffix string   `env:"OUTPUT_SUFFIX" envDefault:".txt" validate:"required"`
+}
+
+// Cache reuses recognizer results for content already seen with the same
+// settings; entries are keyed by the SHA-256 of the content.
+type Cache struct {
+	Backend  string        `env:"BACKEND"   envDefault:"none" validate:"oneof=none memory disk s3"`
+	TTL      time.Duration `env:"TTL"       envDefault:"720h" validate:"gte=0"`
+	MaxBytes ByteSize      `env:"MAX_BYTES" envDefault:"256MiB" validate:"gt=0"`
+	Dir      string        `env:"DIR"       envDefault:"doc2text-cache"`
+	// The s3 backend keeps entries under S3Prefix in S3Bucket, by default
+	// the document bucket.
+	S3Bucket string `env:"S3_BUCKET"`
+	S3Prefix string `env:"S3_PREFIX" envDefault:"doc2text-cache/"`
+}
+
 type OIDC struct {
 	Issuer      string `env:"ISSUER"      validate:"required_with=JWKSURL Audience ExpectedAzp"`
 	JWKSURL     string `env:"JWKS_URL"    validate:"required_with=Issuer Audience ExpectedAzp,url"`
@@ -45,11 +201,36 @@ type OIDC struct {
 type Config struct {
 	GRpcServer GRpcServer `envPrefix:"G_RPC_SERVER_DOC2TEXT_"`
 	HttpServer HttpServer `envPrefix:"HTTP_SERVER_DOC2TEXT_"`
+	Recognizer Recognizer `envPrefix:"RECOGNIZER_"`
 	Yandex     Yandex     `envPrefix:"YC_"`
+	Tesseract  Tesseract  `envPrefix:"TESSERACT_"`
+	Extract    Extract    `envPrefix:"EXTRACT_"`
+	Archive    Archive    `envPrefix:"ARCHIVE_"`
+	Image      Image      `envPrefix:"IMAGE_"`
+	Batch      Batch      `envPrefix:"BATCH_"`
+	Jobs       Jobs       `envPrefix:"JOBS_"`
+	Webhook    Webhook    `envPrefix:"WEBHOOK_"`
+	Kafka      Kafka      `envPrefix:"KAFKA_"`
+	NATS       NATS       `envPrefix:"NATS_"`
 	S3         S3         `envPrefix:"S3_"`
+	Sidecar    Sidecar    `envPrefix:"S3_SIDECAR_"`
+	Notify     Notify     `envPrefix:"S3_NOTIFY_"`
+	Cache      Cache      `envPrefix:"CACHE_"`
 	OIDC       OIDC       `envPrefix:"OIDC_DOC2TEXT_"`
 }
 
+// ByteSize is a size in bytes read from values like "512MiB" or "10MB".
+type ByteSize uint64
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	n, err := humanize.ParseBytes(string(text))
+	if err != nil {
+		return err
+	}
+	*b = ByteSize(n)
+	return nil
+}
+
 func Load() (*Config, error) {
 	var c Config
 	if err := env.Parse(&c); err != nil {
@@ -59,6 +240,9 @@ func Load() (*Config, error) {
 	if err := v.Struct(c); err != nil {
 		return nil, fmt.Errorf("config validate: %w", err)
 	}
+	if c.Notify.Enabled && !c.Jobs.Enabled {
+		return nil, fmt.Errorf("config validate: S3_NOTIFY_ENABLED needs JOBS_ENABLED")
+	}
 
 	return &c, nil
 }
@@ -9,6 +9,8 @@ package ocrv1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,28 +23,1721 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type JobState int32
+
+const (
+	JobState_JOB_STATE_UNSPECIFIED JobState = 0
+	JobState_JOB_STATE_QUEUED      JobState = 1
+	JobState_JOB_STATE_RUNNING     JobState = 2
+	JobState_JOB_STATE_SUCCEEDED   JobState = 3
+	JobState_JOB_STATE_FAILED      JobState = 4
+	JobState_JOB_STATE_CANCELLED   JobState = 5
+)
+
+// Enum value maps for JobState.
+var (
+	JobState_name = map[int32]string{
+		0: "JOB_STATE_UNSPECIFIED",
+		1: "JOB_STATE_QUEUED",
+		2: "JOB_STATE_RUNNING",
+		3: "JOB_STATE_SUCCEEDED",
+		4: "JOB_STATE_FAILED",
+		5: "JOB_STATE_CANCELLED",
+	}
+	JobState_value = map[string]int32{
+		"JOB_STATE_UNSPECIFIED": 0,
+		"JOB_STATE_QUEUED":      1,
+		"JOB_STATE_RUNNING":     2,
+		"JOB_STATE_SUCCEEDED":   3,
+		"JOB_STATE_FAILED":      4,
+		"JOB_STATE_CANCELLED":   5,
+	}
+)
+
+func (x JobState) Enum() *JobState {
+	p := new(JobState)
+	*p = x
+	return p
+}
+
+func (x JobState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobState) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_enumTypes[0].Descriptor()
+}
+
+func (JobState) Type() protoreflect.EnumType {
+	return &file_internal_presentation_proto_ocr_v1_ocr_proto_enumTypes[0]
+}
+
+func (x JobState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobState.Descriptor instead.
+func (JobState) EnumDescriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{0}
+}
+
 type ParseRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Objectkey string                 `protobuf:"bytes,1,opt,name=objectkey,proto3" json:"objectkey,omitempty"`
+	// Overrides YC_MIN_CONFIDENCE for this request; 0 disables filtering.
+	MinConfidence *float64 `protobuf:"fixed64,2,opt,name=min_confidence,json=minConfidence,proto3,oneof" json:"min_confidence,omitempty"`
+	// Recognizer backend to try first (e.g. "yandex", "tesseract").
+	Backend       string `protobuf:"bytes,3,opt,name=backend,proto3" json:"backend,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseRequest) Reset() {
+	*x = ParseRequest{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseRequest) ProtoMessage() {}
+
+func (x *ParseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseRequest.ProtoReflect.Descriptor instead.
+func (*ParseRequest) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ParseRequest) GetObjectkey() string {
+	if x != nil {
+		return x.Objectkey
+	}
+	return ""
+}
+
+func (x *ParseRequest) GetMinConfidence() float64 {
+	if x != nil && x.MinConfidence != nil {
+		return *x.MinConfidence
+	}
+	return 0
+}
+
+func (x *ParseRequest) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+type BatchProcessRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Objectkeys []string               `protobuf:"bytes,1,rep,name=objectkeys,proto3" json:"objectkeys,omitempty"`
+	// Apply to every item, as in ParseRequest.
+	MinConfidence *float64 `protobuf:"fixed64,2,opt,name=min_confidence,json=minConfidence,proto3,oneof" json:"min_confidence,omitempty"`
+	Backend       string   `protobuf:"bytes,3,opt,name=backend,proto3" json:"backend,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchProcessRequest) Reset() {
+	*x = BatchProcessRequest{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchProcessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchProcessRequest) ProtoMessage() {}
+
+func (x *BatchProcessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchProcessRequest.ProtoReflect.Descriptor instead.
+func (*BatchProcessRequest) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchProcessRequest) GetObjectkeys() []string {
+	if x != nil {
+		return x.Objectkeys
+	}
+	return nil
+}
+
+func (x *BatchProcessRequest) GetMinConfidence() float64 {
+	if x != nil && x.MinConfidence != nil {
+		return *x.MinConfidence
+	}
+	return 0
+}
+
+func (x *BatchProcessRequest) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+type BatchProcessResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*BatchItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchProcessResponse) Reset() {
+	*x = BatchProcessResponse{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchProcessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchProcessResponse) ProtoMessage() {}
+
+func (x *BatchProcessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchProcessResponse.ProtoReflect.Descriptor instead.
+func (*BatchProcessResponse) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchProcessResponse) GetItems() []*BatchItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type BatchItem struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Objectkey string                 `protobuf:"bytes,1,opt,name=objectkey,proto3" json:"objectkey,omitempty"`
+	// Set when the item succeeded.
+	Result *ParseResponse `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	// gRPC status code and message of the item's failure; code is 0 (OK)
+	// on success.
+	Code          int32  `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchItem) Reset() {
+	*x = BatchItem{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchItem) ProtoMessage() {}
+
+func (x *BatchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchItem.ProtoReflect.Descriptor instead.
+func (*BatchItem) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchItem) GetObjectkey() string {
+	if x != nil {
+		return x.Objectkey
+	}
+	return ""
+}
+
+func (x *BatchItem) GetResult() *ParseResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *BatchItem) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// SubmitJobRequest extends ParseRequest, with which it is wire compatible.
+type SubmitJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Objectkey     string                 `protobuf:"bytes,1,opt,name=objectkey,proto3" json:"objectkey,omitempty"`
+	MinConfidence *float64               `protobuf:"fixed64,2,opt,name=min_confidence,json=minConfidence,proto3,oneof" json:"min_confidence,omitempty"`
+	Backend       string                 `protobuf:"bytes,3,opt,name=backend,proto3" json:"backend,omitempty"`
+	// http(s) URL that receives the finished job as JSON (the Job message,
+	// result included) in a POST signed with X-Doc2Text-Signature.
+	CallbackUrl   string `protobuf:"bytes,4,opt,name=callback_url,json=callbackUrl,proto3" json:"callback_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitJobRequest) Reset() {
+	*x = SubmitJobRequest{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitJobRequest) ProtoMessage() {}
+
+func (x *SubmitJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitJobRequest.ProtoReflect.Descriptor instead.
+func (*SubmitJobRequest) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SubmitJobRequest) GetObjectkey() string {
+	if x != nil {
+		return x.Objectkey
+	}
+	return ""
+}
+
+func (x *SubmitJobRequest) GetMinConfidence() float64 {
+	if x != nil && x.MinConfidence != nil {
+		return *x.MinConfidence
+	}
+	return 0
+}
+
+func (x *SubmitJobRequest) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *SubmitJobRequest) GetCallbackUrl() string {
+	if x != nil {
+		return x.CallbackUrl
+	}
+	return ""
+}
+
+type Job struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	State     JobState               `protobuf:"varint,2,opt,name=state,proto3,enum=ocr.v1.JobState" json:"state,omitempty"`
+	Objectkey string                 `protobuf:"bytes,3,opt,name=objectkey,proto3" json:"objectkey,omitempty"`
+	// Set once the job succeeded.
+	Result *ParseResponse `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`
+	// Why the job failed.
+	Error      string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt  *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	// When the finished job and its result are deleted.
+	ExpiresAt   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	CallbackUrl string                 `protobuf:"bytes,10,opt,name=callback_url,json=callbackUrl,proto3" json:"callback_url,omitempty"`
+	// "pending", "delivered" or "failed" once the job has finished.
+	CallbackState string      `protobuf:"bytes,11,opt,name=callback_state,json=callbackState,proto3" json:"callback_state,omitempty"`
+	Deliveries    []*Delivery `protobuf:"bytes,12,rep,name=deliveries,proto3" json:"deliveries,omitempty"`
+	// Where the text is written, for jobs queued by bucket notifications.
+	OutputKey     string `protobuf:"bytes,13,opt,name=output_key,json=outputKey,proto3" json:"output_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *Job) GetObjectkey() string {
+	if x != nil {
+		return x.Objectkey
+	}
+	return ""
+}
+
+func (x *Job) GetResult() *ParseResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *Job) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Job) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Job) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *Job) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Job) GetCallbackUrl() string {
+	if x != nil {
+		return x.CallbackUrl
+	}
+	return ""
+}
+
+func (x *Job) GetCallbackState() string {
+	if x != nil {
+		return x.CallbackState
+	}
+	return ""
+}
+
+func (x *Job) GetDeliveries() []*Delivery {
+	if x != nil {
+		return x.Deliveries
+	}
+	return nil
+}
+
+func (x *Job) GetOutputKey() string {
+	if x != nil {
+		return x.OutputKey
+	}
+	return ""
+}
+
+// Delivery is one attempt to POST the job to its callback URL.
+type Delivery struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	At       *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=at,proto3" json:"at,omitempty"`
+	Duration *durationpb.Duration   `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	// HTTP status of the response; 0 when there was none.
+	Status        int32  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Delivery) Reset() {
+	*x = Delivery{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Delivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Delivery) ProtoMessage() {}
+
+func (x *Delivery) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Delivery.ProtoReflect.Descriptor instead.
+func (*Delivery) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Delivery) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+func (x *Delivery) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *Delivery) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *Delivery) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CancelJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelJobRequest) Reset() {
+	*x = CancelJobRequest{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobRequest) ProtoMessage() {}
+
+func (x *CancelJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobRequest.ProtoReflect.Descriptor instead.
+func (*CancelJobRequest) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CancelJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListJobsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unspecified lists jobs in every state.
+	State JobState `protobuf:"varint,1,opt,name=state,proto3,enum=ocr.v1.JobState" json:"state,omitempty"`
+	// Defaults to 50, at most 500.
+	PageSize      int32  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsRequest) ProtoMessage() {}
+
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListJobsRequest) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *ListJobsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListJobsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListJobsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Jobs  []*Job                 `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListJobsResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+func (x *ListJobsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type UploadRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*UploadRequest_Header
+	//	*UploadRequest_Chunk
+	Payload       isUploadRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadRequest) Reset() {
+	*x = UploadRequest{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadRequest) ProtoMessage() {}
+
+func (x *UploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadRequest.ProtoReflect.Descriptor instead.
+func (*UploadRequest) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UploadRequest) GetPayload() isUploadRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *UploadRequest) GetHeader() *UploadHeader {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadRequest_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *UploadRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isUploadRequest_Payload interface {
+	isUploadRequest_Payload()
+}
+
+type UploadRequest_Header struct {
+	Header *UploadHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type UploadRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*UploadRequest_Header) isUploadRequest_Payload() {}
+
+func (*UploadRequest_Chunk) isUploadRequest_Payload() {}
+
+type UploadHeader struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// File name, used to tell the type when mime_type is empty.
+	Filename string `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	MimeType string `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	// Expected size in bytes, if known; lets oversized uploads fail early.
+	Size          int64    `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	MinConfidence *float64 `protobuf:"fixed64,4,opt,name=min_confidence,json=minConfidence,proto3,oneof" json:"min_confidence,omitempty"`
+	Backend       string   `protobuf:"bytes,5,opt,name=backend,proto3" json:"backend,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadHeader) Reset() {
+	*x = UploadHeader{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadHeader) ProtoMessage() {}
+
+func (x *UploadHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadHeader.ProtoReflect.Descriptor instead.
+func (*UploadHeader) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UploadHeader) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *UploadHeader) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *UploadHeader) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *UploadHeader) GetMinConfidence() float64 {
+	if x != nil && x.MinConfidence != nil {
+		return *x.MinConfidence
+	}
+	return 0
+}
+
+func (x *UploadHeader) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+type ParseResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Text  string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Pages []*Page                `protobuf:"bytes,2,rep,name=pages,proto3" json:"pages,omitempty"`
+	// Number of words and lines dropped by the confidence threshold.
+	FilteredWords int32 `protobuf:"varint,3,opt,name=filtered_words,json=filteredWords,proto3" json:"filtered_words,omitempty"`
+	FilteredLines int32 `protobuf:"varint,4,opt,name=filtered_lines,json=filteredLines,proto3" json:"filtered_lines,omitempty"`
+	// Recognizer backend that produced the result.
+	Backend string `protobuf:"bytes,5,opt,name=backend,proto3" json:"backend,omitempty"`
+	// Per-file results when the object is an archive; pages is empty then.
+	Entries []*Entry `protobuf:"bytes,6,rep,name=entries,proto3" json:"entries,omitempty"`
+	// True when all OCR in the result was served from the cache.
+	Cached        bool `protobuf:"varint,7,opt,name=cached,proto3" json:"cached,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseResponse) Reset() {
+	*x = ParseResponse{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseResponse) ProtoMessage() {}
+
+func (x *ParseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseResponse.ProtoReflect.Descriptor instead.
+func (*ParseResponse) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ParseResponse) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ParseResponse) GetPages() []*Page {
+	if x != nil {
+		return x.Pages
+	}
+	return nil
+}
+
+func (x *ParseResponse) GetFilteredWords() int32 {
+	if x != nil {
+		return x.FilteredWords
+	}
+	return 0
+}
+
+func (x *ParseResponse) GetFilteredLines() int32 {
+	if x != nil {
+		return x.FilteredLines
+	}
+	return 0
+}
+
+func (x *ParseResponse) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *ParseResponse) GetEntries() []*Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ParseResponse) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+type ProcessStreamResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*ProcessStreamResponse_Page
+	//	*ProcessStreamResponse_Summary
+	Event         isProcessStreamResponse_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessStreamResponse) Reset() {
+	*x = ProcessStreamResponse{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessStreamResponse) ProtoMessage() {}
+
+func (x *ProcessStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessStreamResponse.ProtoReflect.Descriptor instead.
+func (*ProcessStreamResponse) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ProcessStreamResponse) GetEvent() isProcessStreamResponse_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ProcessStreamResponse) GetPage() *PageEvent {
+	if x != nil {
+		if x, ok := x.Event.(*ProcessStreamResponse_Page); ok {
+			return x.Page
+		}
+	}
+	return nil
+}
+
+func (x *ProcessStreamResponse) GetSummary() *StreamSummary {
+	if x != nil {
+		if x, ok := x.Event.(*ProcessStreamResponse_Summary); ok {
+			return x.Summary
+		}
+	}
+	return nil
+}
+
+type isProcessStreamResponse_Event interface {
+	isProcessStreamResponse_Event()
+}
+
+type ProcessStreamResponse_Page struct {
+	Page *PageEvent `protobuf:"bytes,1,opt,name=page,proto3,oneof"`
+}
+
+type ProcessStreamResponse_Summary struct {
+	Summary *StreamSummary `protobuf:"bytes,2,opt,name=summary,proto3,oneof"`
+}
+
+func (*ProcessStreamResponse_Page) isProcessStreamResponse_Event() {}
+
+func (*ProcessStreamResponse_Summary) isProcessStreamResponse_Event() {}
+
+type PageEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Archive path of the file the page belongs to; empty for plain files.
+	Entry string `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	// Position of the page in its file, from 0, and the file's page count
+	// ("page index+1 of total"). Pages read from a PDF text layer may arrive
+	// before the scanned pages that precede them.
+	Index   int32  `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Total   int32  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Text    string `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	Page    *Page  `protobuf:"bytes,5,opt,name=page,proto3" json:"page,omitempty"`
+	Backend string `protobuf:"bytes,6,opt,name=backend,proto3" json:"backend,omitempty"`
+	// Why the page could not be recognized; text and page are empty then.
+	// A failed archive entry as a whole is reported with total = 0.
+	Error         string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PageEvent) Reset() {
+	*x = PageEvent{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PageEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PageEvent) ProtoMessage() {}
+
+func (x *PageEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PageEvent.ProtoReflect.Descriptor instead.
+func (*PageEvent) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PageEvent) GetEntry() string {
+	if x != nil {
+		return x.Entry
+	}
+	return ""
+}
+
+func (x *PageEvent) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *PageEvent) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *PageEvent) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *PageEvent) GetPage() *Page {
+	if x != nil {
+		return x.Page
+	}
+	return nil
+}
+
+func (x *PageEvent) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *PageEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type StreamSummary struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Pages sent, failed ones included.
+	Pages         int32  `protobuf:"varint,1,opt,name=pages,proto3" json:"pages,omitempty"`
+	FailedPages   int32  `protobuf:"varint,2,opt,name=failed_pages,json=failedPages,proto3" json:"failed_pages,omitempty"`
+	FilteredWords int32  `protobuf:"varint,3,opt,name=filtered_words,json=filteredWords,proto3" json:"filtered_words,omitempty"`
+	FilteredLines int32  `protobuf:"varint,4,opt,name=filtered_lines,json=filteredLines,proto3" json:"filtered_lines,omitempty"`
+	Backend       string `protobuf:"bytes,5,opt,name=backend,proto3" json:"backend,omitempty"`
+	Cached        bool   `protobuf:"varint,6,opt,name=cached,proto3" json:"cached,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamSummary) Reset() {
+	*x = StreamSummary{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamSummary) ProtoMessage() {}
+
+func (x *StreamSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamSummary.ProtoReflect.Descriptor instead.
+func (*StreamSummary) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StreamSummary) GetPages() int32 {
+	if x != nil {
+		return x.Pages
+	}
+	return 0
+}
+
+func (x *StreamSummary) GetFailedPages() int32 {
+	if x != nil {
+		return x.FailedPages
+	}
+	return 0
+}
+
+func (x *StreamSummary) GetFilteredWords() int32 {
+	if x != nil {
+		return x.FilteredWords
+	}
+	return 0
+}
+
+func (x *StreamSummary) GetFilteredLines() int32 {
+	if x != nil {
+		return x.FilteredLines
+	}
+	return 0
+}
+
+func (x *StreamSummary) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *StreamSummary) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+// OcrRequest is the message consumed in queue worker mode, as JSON.
+type OcrRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Echoed in the result; falls back to the correlation-id header, then to
+	// the message key.
+	CorrelationId string   `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	Objectkey     string   `protobuf:"bytes,2,opt,name=objectkey,proto3" json:"objectkey,omitempty"`
+	MinConfidence *float64 `protobuf:"fixed64,3,opt,name=min_confidence,json=minConfidence,proto3,oneof" json:"min_confidence,omitempty"`
+	Backend       string   `protobuf:"bytes,4,opt,name=backend,proto3" json:"backend,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OcrRequest) Reset() {
+	*x = OcrRequest{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OcrRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OcrRequest) ProtoMessage() {}
+
+func (x *OcrRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OcrRequest.ProtoReflect.Descriptor instead.
+func (*OcrRequest) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *OcrRequest) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *OcrRequest) GetObjectkey() string {
+	if x != nil {
+		return x.Objectkey
+	}
+	return ""
+}
+
+func (x *OcrRequest) GetMinConfidence() float64 {
+	if x != nil && x.MinConfidence != nil {
+		return *x.MinConfidence
+	}
+	return 0
+}
+
+func (x *OcrRequest) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+// OcrResult is published for every OcrRequest, as JSON, keyed by the
+// correlation ID. Exactly one of response and error is set.
+type OcrResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	Objectkey     string                 `protobuf:"bytes,2,opt,name=objectkey,proto3" json:"objectkey,omitempty"`
+	Response      *ParseResponse         `protobuf:"bytes,3,opt,name=response,proto3" json:"response,omitempty"`
+	Error         *OcrError              `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OcrResult) Reset() {
+	*x = OcrResult{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OcrResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OcrResult) ProtoMessage() {}
+
+func (x *OcrResult) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OcrResult.ProtoReflect.Descriptor instead.
+func (*OcrResult) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *OcrResult) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *OcrResult) GetObjectkey() string {
+	if x != nil {
+		return x.Objectkey
+	}
+	return ""
+}
+
+func (x *OcrResult) GetResponse() *ParseResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *OcrResult) GetError() *OcrError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type OcrError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// gRPC status code name, e.g. "InvalidArgument" or "Unavailable".
+	Code          string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OcrError) Reset() {
+	*x = OcrError{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OcrError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OcrError) ProtoMessage() {}
+
+func (x *OcrError) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OcrError.ProtoReflect.Descriptor instead.
+func (*OcrError) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *OcrError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *OcrError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type Entry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path inside the archive, nested archives included ("a.zip/b.png").
+	Path     string  `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	MimeType string  `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Text     string  `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	Pages    []*Page `protobuf:"bytes,4,rep,name=pages,proto3" json:"pages,omitempty"`
+	Backend  string  `protobuf:"bytes,5,opt,name=backend,proto3" json:"backend,omitempty"`
+	// Why the file could not be processed; empty on success.
+	Error         string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Cached        bool   `protobuf:"varint,7,opt,name=cached,proto3" json:"cached,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entry) Reset() {
+	*x = Entry{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entry) ProtoMessage() {}
+
+func (x *Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
+func (*Entry) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Entry) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Entry) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *Entry) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Entry) GetPages() []*Page {
+	if x != nil {
+		return x.Pages
+	}
+	return nil
+}
+
+func (x *Entry) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *Entry) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Entry) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+type Page struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Width  int64                  `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height int64                  `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	Blocks []*Block               `protobuf:"bytes,3,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	// How the page text was obtained: "ocr", "text_layer" or "document".
+	Source string `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	// Sheet name, "slide N", etc. for documents read without OCR.
+	Label string `protobuf:"bytes,5,opt,name=label,proto3" json:"label,omitempty"`
+	// Degrees clockwise the image was turned before recognition (EXIF
+	// orientation, auto-rotation) and the deskew angle that was corrected.
+	// Bounding boxes refer to the turned image.
+	Rotation      int32   `protobuf:"varint,6,opt,name=rotation,proto3" json:"rotation,omitempty"`
+	Skew          float64 `protobuf:"fixed64,7,opt,name=skew,proto3" json:"skew,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Page) Reset() {
+	*x = Page{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Page) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Page) ProtoMessage() {}
+
+func (x *Page) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Page.ProtoReflect.Descriptor instead.
+func (*Page) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Page) GetWidth() int64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Page) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Page) GetBlocks() []*Block {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *Page) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Page) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Page) GetRotation() int32 {
+	if x != nil {
+		return x.Rotation
+	}
+	return 0
+}
+
+func (x *Page) GetSkew() float64 {
+	if x != nil {
+		return x.Skew
+	}
+	return 0
+}
+
+type Block struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Objectkey     string                 `protobuf:"bytes,1,opt,name=objectkey,proto3" json:"objectkey,omitempty"`
+	BoundingBox   *BoundingBox           `protobuf:"bytes,1,opt,name=bounding_box,json=boundingBox,proto3" json:"bounding_box,omitempty"`
+	Lines         []*Line                `protobuf:"bytes,2,rep,name=lines,proto3" json:"lines,omitempty"`
+	Languages     []*DetectedLanguage    `protobuf:"bytes,3,rep,name=languages,proto3" json:"languages,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ParseRequest) Reset() {
-	*x = ParseRequest{}
-	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[0]
+func (x *Block) Reset() {
+	*x = Block{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ParseRequest) String() string {
+func (x *Block) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ParseRequest) ProtoMessage() {}
+func (*Block) ProtoMessage() {}
 
-func (x *ParseRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[0]
+func (x *Block) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -53,40 +1748,125 @@ func (x *ParseRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ParseRequest.ProtoReflect.Descriptor instead.
-func (*ParseRequest) Descriptor() ([]byte, []int) {
-	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use Block.ProtoReflect.Descriptor instead.
+func (*Block) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *ParseRequest) GetObjectkey() string {
+func (x *Block) GetBoundingBox() *BoundingBox {
 	if x != nil {
-		return x.Objectkey
+		return x.BoundingBox
+	}
+	return nil
+}
+
+func (x *Block) GetLines() []*Line {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *Block) GetLanguages() []*DetectedLanguage {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+type Line struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	BoundingBox   *BoundingBox           `protobuf:"bytes,2,opt,name=bounding_box,json=boundingBox,proto3" json:"bounding_box,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,3,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Words         []*Word                `protobuf:"bytes,4,rep,name=words,proto3" json:"words,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Line) Reset() {
+	*x = Line{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Line) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Line) ProtoMessage() {}
+
+func (x *Line) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Line.ProtoReflect.Descriptor instead.
+func (*Line) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Line) GetText() string {
+	if x != nil {
+		return x.Text
 	}
 	return ""
 }
 
-type ParseResponse struct {
+func (x *Line) GetBoundingBox() *BoundingBox {
+	if x != nil {
+		return x.BoundingBox
+	}
+	return nil
+}
+
+func (x *Line) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Line) GetWords() []*Word {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+type Word struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	BoundingBox   *BoundingBox           `protobuf:"bytes,2,opt,name=bounding_box,json=boundingBox,proto3" json:"bounding_box,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,3,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Languages     []*DetectedLanguage    `protobuf:"bytes,4,rep,name=languages,proto3" json:"languages,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ParseResponse) Reset() {
-	*x = ParseResponse{}
-	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[1]
+func (x *Word) Reset() {
+	*x = Word{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ParseResponse) String() string {
+func (x *Word) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ParseResponse) ProtoMessage() {}
+func (*Word) ProtoMessage() {}
 
-func (x *ParseResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[1]
+func (x *Word) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -97,30 +1877,370 @@ func (x *ParseResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ParseResponse.ProtoReflect.Descriptor instead.
-func (*ParseResponse) Descriptor() ([]byte, []int) {
-	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use Word.ProtoReflect.Descriptor instead.
+func (*Word) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *ParseResponse) GetText() string {
+func (x *Word) GetText() string {
 	if x != nil {
 		return x.Text
 	}
 	return ""
 }
 
+func (x *Word) GetBoundingBox() *BoundingBox {
+	if x != nil {
+		return x.BoundingBox
+	}
+	return nil
+}
+
+func (x *Word) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Word) GetLanguages() []*DetectedLanguage {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+type BoundingBox struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vertices      []*Vertex              `protobuf:"bytes,1,rep,name=vertices,proto3" json:"vertices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BoundingBox) Reset() {
+	*x = BoundingBox{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BoundingBox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BoundingBox) ProtoMessage() {}
+
+func (x *BoundingBox) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BoundingBox.ProtoReflect.Descriptor instead.
+func (*BoundingBox) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *BoundingBox) GetVertices() []*Vertex {
+	if x != nil {
+		return x.Vertices
+	}
+	return nil
+}
+
+type Vertex struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             int64                  `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int64                  `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Vertex) Reset() {
+	*x = Vertex{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Vertex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vertex) ProtoMessage() {}
+
+func (x *Vertex) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vertex.ProtoReflect.Descriptor instead.
+func (*Vertex) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Vertex) GetX() int64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Vertex) GetY() int64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+type DetectedLanguage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LanguageCode  string                 `protobuf:"bytes,1,opt,name=language_code,json=languageCode,proto3" json:"language_code,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,2,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetectedLanguage) Reset() {
+	*x = DetectedLanguage{}
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetectedLanguage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectedLanguage) ProtoMessage() {}
+
+func (x *DetectedLanguage) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectedLanguage.ProtoReflect.Descriptor instead.
+func (*DetectedLanguage) Descriptor() ([]byte, []int) {
+	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DetectedLanguage) GetLanguageCode() string {
+	if x != nil {
+		return x.LanguageCode
+	}
+	return ""
+}
+
+func (x *DetectedLanguage) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
 var File_internal_presentation_proto_ocr_v1_ocr_proto protoreflect.FileDescriptor
 
 const file_internal_presentation_proto_ocr_v1_ocr_proto_rawDesc = "" +
 	"\n" +
-	",internal/presentation/proto/ocr/v1/ocr.proto\x12\x06ocr.v1\",\n" +
+	",internal/presentation/proto/ocr/v1/ocr.proto\x12\x06ocr.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x85\x01\n" +
 	"\fParseRequest\x12\x1c\n" +
-	"\tobjectkey\x18\x01 \x01(\tR\tobjectkey\"#\n" +
+	"\tobjectkey\x18\x01 \x01(\tR\tobjectkey\x12*\n" +
+	"\x0emin_confidence\x18\x02 \x01(\x01H\x00R\rminConfidence\x88\x01\x01\x12\x18\n" +
+	"\abackend\x18\x03 \x01(\tR\abackendB\x11\n" +
+	"\x0f_min_confidence\"\x8e\x01\n" +
+	"\x13BatchProcessRequest\x12\x1e\n" +
+	"\n" +
+	"objectkeys\x18\x01 \x03(\tR\n" +
+	"objectkeys\x12*\n" +
+	"\x0emin_confidence\x18\x02 \x01(\x01H\x00R\rminConfidence\x88\x01\x01\x12\x18\n" +
+	"\abackend\x18\x03 \x01(\tR\abackendB\x11\n" +
+	"\x0f_min_confidence\"?\n" +
+	"\x14BatchProcessResponse\x12'\n" +
+	"\x05items\x18\x01 \x03(\v2\x11.ocr.v1.BatchItemR\x05items\"\x82\x01\n" +
+	"\tBatchItem\x12\x1c\n" +
+	"\tobjectkey\x18\x01 \x01(\tR\tobjectkey\x12-\n" +
+	"\x06result\x18\x02 \x01(\v2\x15.ocr.v1.ParseResponseR\x06result\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\x05R\x04code\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\xac\x01\n" +
+	"\x10SubmitJobRequest\x12\x1c\n" +
+	"\tobjectkey\x18\x01 \x01(\tR\tobjectkey\x12*\n" +
+	"\x0emin_confidence\x18\x02 \x01(\x01H\x00R\rminConfidence\x88\x01\x01\x12\x18\n" +
+	"\abackend\x18\x03 \x01(\tR\abackend\x12!\n" +
+	"\fcallback_url\x18\x04 \x01(\tR\vcallbackUrlB\x11\n" +
+	"\x0f_min_confidence\"\xa9\x04\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12&\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x10.ocr.v1.JobStateR\x05state\x12\x1c\n" +
+	"\tobjectkey\x18\x03 \x01(\tR\tobjectkey\x12-\n" +
+	"\x06result\x18\x04 \x01(\v2\x15.ocr.v1.ParseResponseR\x06result\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"started_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12!\n" +
+	"\fcallback_url\x18\n" +
+	" \x01(\tR\vcallbackUrl\x12%\n" +
+	"\x0ecallback_state\x18\v \x01(\tR\rcallbackState\x120\n" +
+	"\n" +
+	"deliveries\x18\f \x03(\v2\x10.ocr.v1.DeliveryR\n" +
+	"deliveries\x12\x1d\n" +
+	"\n" +
+	"output_key\x18\r \x01(\tR\toutputKey\"\x9b\x01\n" +
+	"\bDelivery\x12*\n" +
+	"\x02at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\x125\n" +
+	"\bduration\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x05R\x06status\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x1f\n" +
+	"\rGetJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\"\n" +
+	"\x10CancelJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"u\n" +
+	"\x0fListJobsRequest\x12&\n" +
+	"\x05state\x18\x01 \x01(\x0e2\x10.ocr.v1.JobStateR\x05state\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"[\n" +
+	"\x10ListJobsResponse\x12\x1f\n" +
+	"\x04jobs\x18\x01 \x03(\v2\v.ocr.v1.JobR\x04jobs\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"b\n" +
+	"\rUploadRequest\x12.\n" +
+	"\x06header\x18\x01 \x01(\v2\x14.ocr.v1.UploadHeaderH\x00R\x06header\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\t\n" +
+	"\apayload\"\xb4\x01\n" +
+	"\fUploadHeader\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1b\n" +
+	"\tmime_type\x18\x02 \x01(\tR\bmimeType\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12*\n" +
+	"\x0emin_confidence\x18\x04 \x01(\x01H\x00R\rminConfidence\x88\x01\x01\x12\x18\n" +
+	"\abackend\x18\x05 \x01(\tR\abackendB\x11\n" +
+	"\x0f_min_confidence\"\xf0\x01\n" +
 	"\rParseResponse\x12\x12\n" +
-	"\x04text\x18\x01 \x01(\tR\x04text2D\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\"\n" +
+	"\x05pages\x18\x02 \x03(\v2\f.ocr.v1.PageR\x05pages\x12%\n" +
+	"\x0efiltered_words\x18\x03 \x01(\x05R\rfilteredWords\x12%\n" +
+	"\x0efiltered_lines\x18\x04 \x01(\x05R\rfilteredLines\x12\x18\n" +
+	"\abackend\x18\x05 \x01(\tR\abackend\x12'\n" +
+	"\aentries\x18\x06 \x03(\v2\r.ocr.v1.EntryR\aentries\x12\x16\n" +
+	"\x06cached\x18\a \x01(\bR\x06cached\"|\n" +
+	"\x15ProcessStreamResponse\x12'\n" +
+	"\x04page\x18\x01 \x01(\v2\x11.ocr.v1.PageEventH\x00R\x04page\x121\n" +
+	"\asummary\x18\x02 \x01(\v2\x15.ocr.v1.StreamSummaryH\x00R\asummaryB\a\n" +
+	"\x05event\"\xb3\x01\n" +
+	"\tPageEvent\x12\x14\n" +
+	"\x05entry\x18\x01 \x01(\tR\x05entry\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x05R\x05index\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\x12\x12\n" +
+	"\x04text\x18\x04 \x01(\tR\x04text\x12 \n" +
+	"\x04page\x18\x05 \x01(\v2\f.ocr.v1.PageR\x04page\x12\x18\n" +
+	"\abackend\x18\x06 \x01(\tR\abackend\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\xc8\x01\n" +
+	"\rStreamSummary\x12\x14\n" +
+	"\x05pages\x18\x01 \x01(\x05R\x05pages\x12!\n" +
+	"\ffailed_pages\x18\x02 \x01(\x05R\vfailedPages\x12%\n" +
+	"\x0efiltered_words\x18\x03 \x01(\x05R\rfilteredWords\x12%\n" +
+	"\x0efiltered_lines\x18\x04 \x01(\x05R\rfilteredLines\x12\x18\n" +
+	"\abackend\x18\x05 \x01(\tR\abackend\x12\x16\n" +
+	"\x06cached\x18\x06 \x01(\bR\x06cached\"\xaa\x01\n" +
+	"\n" +
+	"OcrRequest\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12\x1c\n" +
+	"\tobjectkey\x18\x02 \x01(\tR\tobjectkey\x12*\n" +
+	"\x0emin_confidence\x18\x03 \x01(\x01H\x00R\rminConfidence\x88\x01\x01\x12\x18\n" +
+	"\abackend\x18\x04 \x01(\tR\abackendB\x11\n" +
+	"\x0f_min_confidence\"\xab\x01\n" +
+	"\tOcrResult\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12\x1c\n" +
+	"\tobjectkey\x18\x02 \x01(\tR\tobjectkey\x121\n" +
+	"\bresponse\x18\x03 \x01(\v2\x15.ocr.v1.ParseResponseR\bresponse\x12&\n" +
+	"\x05error\x18\x04 \x01(\v2\x10.ocr.v1.OcrErrorR\x05error\"8\n" +
+	"\bOcrError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xb8\x01\n" +
+	"\x05Entry\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1b\n" +
+	"\tmime_type\x18\x02 \x01(\tR\bmimeType\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\x12\"\n" +
+	"\x05pages\x18\x04 \x03(\v2\f.ocr.v1.PageR\x05pages\x12\x18\n" +
+	"\abackend\x18\x05 \x01(\tR\abackend\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12\x16\n" +
+	"\x06cached\x18\a \x01(\bR\x06cached\"\xb9\x01\n" +
+	"\x04Page\x12\x14\n" +
+	"\x05width\x18\x01 \x01(\x03R\x05width\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\x03R\x06height\x12%\n" +
+	"\x06blocks\x18\x03 \x03(\v2\r.ocr.v1.BlockR\x06blocks\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x14\n" +
+	"\x05label\x18\x05 \x01(\tR\x05label\x12\x1a\n" +
+	"\brotation\x18\x06 \x01(\x05R\brotation\x12\x12\n" +
+	"\x04skew\x18\a \x01(\x01R\x04skew\"\x9b\x01\n" +
+	"\x05Block\x126\n" +
+	"\fbounding_box\x18\x01 \x01(\v2\x13.ocr.v1.BoundingBoxR\vboundingBox\x12\"\n" +
+	"\x05lines\x18\x02 \x03(\v2\f.ocr.v1.LineR\x05lines\x126\n" +
+	"\tlanguages\x18\x03 \x03(\v2\x18.ocr.v1.DetectedLanguageR\tlanguages\"\x96\x01\n" +
+	"\x04Line\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x126\n" +
+	"\fbounding_box\x18\x02 \x01(\v2\x13.ocr.v1.BoundingBoxR\vboundingBox\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x03 \x01(\x01R\n" +
+	"confidence\x12\"\n" +
+	"\x05words\x18\x04 \x03(\v2\f.ocr.v1.WordR\x05words\"\xaa\x01\n" +
+	"\x04Word\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x126\n" +
+	"\fbounding_box\x18\x02 \x01(\v2\x13.ocr.v1.BoundingBoxR\vboundingBox\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x03 \x01(\x01R\n" +
+	"confidence\x126\n" +
+	"\tlanguages\x18\x04 \x03(\v2\x18.ocr.v1.DetectedLanguageR\tlanguages\"9\n" +
+	"\vBoundingBox\x12*\n" +
+	"\bvertices\x18\x01 \x03(\v2\x0e.ocr.v1.VertexR\bvertices\"$\n" +
+	"\x06Vertex\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x03R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x03R\x01y\"W\n" +
+	"\x10DetectedLanguage\x12#\n" +
+	"\rlanguage_code\x18\x01 \x01(\tR\flanguageCode\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x02 \x01(\x01R\n" +
+	"confidence*\x9a\x01\n" +
+	"\bJobState\x12\x19\n" +
+	"\x15JOB_STATE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10JOB_STATE_QUEUED\x10\x01\x12\x15\n" +
+	"\x11JOB_STATE_RUNNING\x10\x02\x12\x17\n" +
+	"\x13JOB_STATE_SUCCEEDED\x10\x03\x12\x14\n" +
+	"\x10JOB_STATE_FAILED\x10\x04\x12\x17\n" +
+	"\x13JOB_STATE_CANCELLED\x10\x052\xe6\x03\n" +
 	"\n" +
 	"OcrService\x126\n" +
-	"\aProcess\x12\x14.ocr.v1.ParseRequest\x1a\x15.ocr.v1.ParseResponseB3Z1doc2text/internal/presentation/proto/ocr/v1;ocrv1b\x06proto3"
+	"\aProcess\x12\x14.ocr.v1.ParseRequest\x1a\x15.ocr.v1.ParseResponse\x128\n" +
+	"\x06Upload\x12\x15.ocr.v1.UploadRequest\x1a\x15.ocr.v1.ParseResponse(\x01\x12F\n" +
+	"\rProcessStream\x12\x14.ocr.v1.ParseRequest\x1a\x1d.ocr.v1.ProcessStreamResponse0\x01\x12I\n" +
+	"\fBatchProcess\x12\x1b.ocr.v1.BatchProcessRequest\x1a\x1c.ocr.v1.BatchProcessResponse\x122\n" +
+	"\tSubmitJob\x12\x18.ocr.v1.SubmitJobRequest\x1a\v.ocr.v1.Job\x12,\n" +
+	"\x06GetJob\x12\x15.ocr.v1.GetJobRequest\x1a\v.ocr.v1.Job\x122\n" +
+	"\tCancelJob\x12\x18.ocr.v1.CancelJobRequest\x1a\v.ocr.v1.Job\x12=\n" +
+	"\bListJobs\x12\x17.ocr.v1.ListJobsRequest\x1a\x18.ocr.v1.ListJobsResponseB3Z1doc2text/internal/presentation/proto/ocr/v1;ocrv1b\x06proto3"
 
 var (
 	file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescOnce sync.Once
@@ -134,19 +2254,94 @@ func file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescGZIP() []byte {
 	return file_internal_presentation_proto_ocr_v1_ocr_proto_rawDescData
 }
 
-var file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_internal_presentation_proto_ocr_v1_ocr_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
 var file_internal_presentation_proto_ocr_v1_ocr_proto_goTypes = []any{
-	(*ParseRequest)(nil),  // 0: ocr.v1.ParseRequest
-	(*ParseResponse)(nil), // 1: ocr.v1.ParseResponse
+	(JobState)(0),                 // 0: ocr.v1.JobState
+	(*ParseRequest)(nil),          // 1: ocr.v1.ParseRequest
+	(*BatchProcessRequest)(nil),   // 2: ocr.v1.BatchProcessRequest
+	(*BatchProcessResponse)(nil),  // 3: ocr.v1.BatchProcessResponse
+	(*BatchItem)(nil),             // 4: ocr.v1.BatchItem
+	(*SubmitJobRequest)(nil),      // 5: ocr.v1.SubmitJobRequest
+	(*Job)(nil),                   // 6: ocr.v1.Job
+	(*Delivery)(nil),              // 7: ocr.v1.Delivery
+	(*GetJobRequest)(nil),         // 8: ocr.v1.GetJobRequest
+	(*CancelJobRequest)(nil),      // 9: ocr.v1.CancelJobRequest
+	(*ListJobsRequest)(nil),       // 10: ocr.v1.ListJobsRequest
+	(*ListJobsResponse)(nil),      // 11: ocr.v1.ListJobsResponse
+	(*UploadRequest)(nil),         // 12: ocr.v1.UploadRequest
+	(*UploadHeader)(nil),          // 13: ocr.v1.UploadHeader
+	(*ParseResponse)(nil),         // 14: ocr.v1.ParseResponse
+	(*ProcessStreamResponse)(nil), // 15: ocr.v1.ProcessStreamResponse
+	(*PageEvent)(nil),             // 16: ocr.v1.PageEvent
+	(*StreamSummary)(nil),         // 17: ocr.v1.StreamSummary
+	(*OcrRequest)(nil),            // 18: ocr.v1.OcrRequest
+	(*OcrResult)(nil),             // 19: ocr.v1.OcrResult
+	(*OcrError)(nil),              // 20: ocr.v1.OcrError
+	(*Entry)(nil),                 // 21: ocr.v1.Entry
+	(*Page)(nil),                  // 22: ocr.v1.Page
+	(*Block)(nil),                 // 23: ocr.v1.Block
+	(*Line)(nil),                  // 24: ocr.v1.Line
+	(*Word)(nil),                  // 25: ocr.v1.Word
+	(*BoundingBox)(nil),           // 26: ocr.v1.BoundingBox
+	(*Vertex)(nil),                // 27: ocr.v1.Vertex
+	(*DetectedLanguage)(nil),      // 28: ocr.v1.DetectedLanguage
+	(*timestamppb.Timestamp)(nil), // 29: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 30: google.protobuf.Duration
 }
 var file_internal_presentation_proto_ocr_v1_ocr_proto_depIdxs = []int32{
-	0, // 0: ocr.v1.OcrService.Process:input_type -> ocr.v1.ParseRequest
-	1, // 1: ocr.v1.OcrService.Process:output_type -> ocr.v1.ParseResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	4,  // 0: ocr.v1.BatchProcessResponse.items:type_name -> ocr.v1.BatchItem
+	14, // 1: ocr.v1.BatchItem.result:type_name -> ocr.v1.ParseResponse
+	0,  // 2: ocr.v1.Job.state:type_name -> ocr.v1.JobState
+	14, // 3: ocr.v1.Job.result:type_name -> ocr.v1.ParseResponse
+	29, // 4: ocr.v1.Job.created_at:type_name -> google.protobuf.Timestamp
+	29, // 5: ocr.v1.Job.started_at:type_name -> google.protobuf.Timestamp
+	29, // 6: ocr.v1.Job.finished_at:type_name -> google.protobuf.Timestamp
+	29, // 7: ocr.v1.Job.expires_at:type_name -> google.protobuf.Timestamp
+	7,  // 8: ocr.v1.Job.deliveries:type_name -> ocr.v1.Delivery
+	29, // 9: ocr.v1.Delivery.at:type_name -> google.protobuf.Timestamp
+	30, // 10: ocr.v1.Delivery.duration:type_name -> google.protobuf.Duration
+	0,  // 11: ocr.v1.ListJobsRequest.state:type_name -> ocr.v1.JobState
+	6,  // 12: ocr.v1.ListJobsResponse.jobs:type_name -> ocr.v1.Job
+	13, // 13: ocr.v1.UploadRequest.header:type_name -> ocr.v1.UploadHeader
+	22, // 14: ocr.v1.ParseResponse.pages:type_name -> ocr.v1.Page
+	21, // 15: ocr.v1.ParseResponse.entries:type_name -> ocr.v1.Entry
+	16, // 16: ocr.v1.ProcessStreamResponse.page:type_name -> ocr.v1.PageEvent
+	17, // 17: ocr.v1.ProcessStreamResponse.summary:type_name -> ocr.v1.StreamSummary
+	22, // 18: ocr.v1.PageEvent.page:type_name -> ocr.v1.Page
+	14, // 19: ocr.v1.OcrResult.response:type_name -> ocr.v1.ParseResponse
+	20, // 20: ocr.v1.OcrResult.error:type_name -> ocr.v1.OcrError
+	22, // 21: ocr.v1.Entry.pages:type_name -> ocr.v1.Page
+	23, // 22: ocr.v1.Page.blocks:type_name -> ocr.v1.Block
+	26, // 23: ocr.v1.Block.bounding_box:type_name -> ocr.v1.BoundingBox
+	24, // 24: ocr.v1.Block.lines:type_name -> ocr.v1.Line
+	28, // 25: ocr.v1.Block.languages:type_name -> ocr.v1.DetectedLanguage
+	26, // 26: ocr.v1.Line.bounding_box:type_name -> ocr.v1.BoundingBox
+	25, // 27: ocr.v1.Line.words:type_name -> ocr.v1.Word
+	26, // 28: ocr.v1.Word.bounding_box:type_name -> ocr.v1.BoundingBox
+	28, // 29: ocr.v1.Word.languages:type_name -> ocr.v1.DetectedLanguage
+	27, // 30: ocr.v1.BoundingBox.vertices:type_name -> ocr.v1.Vertex
+	1,  // 31: ocr.v1.OcrService.Process:input_type -> ocr.v1.ParseRequest
+	12, // 32: ocr.v1.OcrService.Upload:input_type -> ocr.v1.UploadRequest
+	1,  // 33: ocr.v1.OcrService.ProcessStream:input_type -> ocr.v1.ParseRequest
+	2,  // 34: ocr.v1.OcrService.BatchProcess:input_type -> ocr.v1.BatchProcessRequest
+	5,  // 35: ocr.v1.OcrService.SubmitJob:input_type -> ocr.v1.SubmitJobRequest
+	8,  // 36: ocr.v1.OcrService.GetJob:input_type -> ocr.v1.GetJobRequest
+	9,  // 37: ocr.v1.OcrService.CancelJob:input_type -> ocr.v1.CancelJobRequest
+	10, // 38: ocr.v1.OcrService.ListJobs:input_type -> ocr.v1.ListJobsRequest
+	14, // 39: ocr.v1.OcrService.Process:output_type -> ocr.v1.ParseResponse
+	14, // 40: ocr.v1.OcrService.Upload:output_type -> ocr.v1.ParseResponse
+	15, // 41: ocr.v1.OcrService.ProcessStream:output_type -> ocr.v1.ProcessStreamResponse
+	3,  // 42: ocr.v1.OcrService.BatchProcess:output_type -> ocr.v1.BatchProcessResponse
+	6,  // 43: ocr.v1.OcrService.SubmitJob:output_type -> ocr.v1.Job
+	6,  // 44: ocr.v1.OcrService.GetJob:output_type -> ocr.v1.Job
+	6,  // 45: ocr.v1.OcrService.CancelJob:output_type -> ocr.v1.Job
+	11, // 46: ocr.v1.OcrService.ListJobs:output_type -> ocr.v1.ListJobsResponse
+	39, // [39:47] is the sub-list for method output_type
+	31, // [31:39] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
 }
 
 func init() { file_internal_presentation_proto_ocr_v1_ocr_proto_init() }
@@ -154,18 +2349,32 @@ func file_internal_presentation_proto_ocr_v1_ocr_proto_init() {
 	if File_internal_presentation_proto_ocr_v1_ocr_proto != nil {
 		return
 	}
+	file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[0].OneofWrappers = []any{}
+	file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[1].OneofWrappers = []any{}
+	file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[4].OneofWrappers = []any{}
+	file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[11].OneofWrappers = []any{
+		(*UploadRequest_Header)(nil),
+		(*UploadRequest_Chunk)(nil),
+	}
+	file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[12].OneofWrappers = []any{}
+	file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[14].OneofWrappers = []any{
+		(*ProcessStreamResponse_Page)(nil),
+		(*ProcessStreamResponse_Summary)(nil),
+	}
+	file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes[17].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_presentation_proto_ocr_v1_ocr_proto_rawDesc), len(file_internal_presentation_proto_ocr_v1_ocr_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      1,
+			NumMessages:   28,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_internal_presentation_proto_ocr_v1_ocr_proto_goTypes,
 		DependencyIndexes: file_internal_presentation_proto_ocr_v1_ocr_proto_depIdxs,
+		EnumInfos:         file_internal_presentation_proto_ocr_v1_ocr_proto_enumTypes,
 		MessageInfos:      file_internal_presentation_proto_ocr_v1_ocr_proto_msgTypes,
 	}.Build()
 	File_internal_presentation_proto_ocr_v1_ocr_proto = out.File
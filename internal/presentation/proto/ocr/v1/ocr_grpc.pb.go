@@ -10,11 +10,31 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	OcrService_Process_FullMethodName = "/ocr.v1.OcrService/Process"
+	OcrService_Process_FullMethodName       = "/ocr.v1.OcrService/Process"
+	OcrService_Upload_FullMethodName        = "/ocr.v1.OcrService/Upload"
+	OcrService_ProcessStream_FullMethodName = "/ocr.v1.OcrService/ProcessStream"
+	OcrService_BatchProcess_FullMethodName  = "/ocr.v1.OcrService/BatchProcess"
+	OcrService_SubmitJob_FullMethodName     = "/ocr.v1.OcrService/SubmitJob"
+	OcrService_GetJob_FullMethodName        = "/ocr.v1.OcrService/GetJob"
+	OcrService_CancelJob_FullMethodName     = "/ocr.v1.OcrService/CancelJob"
+	OcrService_ListJobs_FullMethodName      = "/ocr.v1.OcrService/ListJobs"
 )
 
 type OcrServiceClient interface {
 	Process(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error)
+
+	Upload(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadRequest, ParseResponse], error)
+
+	ProcessStream(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProcessStreamResponse], error)
+
+	BatchProcess(ctx context.Context, in *BatchProcessRequest, opts ...grpc.CallOption) (*BatchProcessResponse, error)
+
+	SubmitJob(ctx context.Context, in *SubmitJobRequest, opts ...grpc.CallOption) (*Job, error)
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+
+	CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*Job, error)
+
+	ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error)
 }
 
 type ocrServiceClient struct {
@@ -35,8 +55,101 @@ func (c *ocrServiceClient) Process(ctx context.Context, in *ParseRequest, opts .
 	return out, nil
 }
 
+func (c *ocrServiceClient) Upload(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadRequest, ParseResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OcrService_ServiceDesc.Streams[0], OcrService_Upload_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadRequest, ParseResponse]{ClientStream: stream}
+	return x, nil
+}
+
+type OcrService_UploadClient = grpc.ClientStreamingClient[UploadRequest, ParseResponse]
+
+func (c *ocrServiceClient) ProcessStream(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProcessStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OcrService_ServiceDesc.Streams[1], OcrService_ProcessStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ParseRequest, ProcessStreamResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OcrService_ProcessStreamClient = grpc.ServerStreamingClient[ProcessStreamResponse]
+
+func (c *ocrServiceClient) BatchProcess(ctx context.Context, in *BatchProcessRequest, opts ...grpc.CallOption) (*BatchProcessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchProcessResponse)
+	err := c.cc.Invoke(ctx, OcrService_BatchProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ocrServiceClient) SubmitJob(ctx context.Context, in *SubmitJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, OcrService_SubmitJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ocrServiceClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, OcrService_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ocrServiceClient) CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, OcrService_CancelJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ocrServiceClient) ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListJobsResponse)
+	err := c.cc.Invoke(ctx, OcrService_ListJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 type OcrServiceServer interface {
 	Process(context.Context, *ParseRequest) (*ParseResponse, error)
+
+	Upload(grpc.ClientStreamingServer[UploadRequest, ParseResponse]) error
+
+	ProcessStream(*ParseRequest, grpc.ServerStreamingServer[ProcessStreamResponse]) error
+
+	BatchProcess(context.Context, *BatchProcessRequest) (*BatchProcessResponse, error)
+
+	SubmitJob(context.Context, *SubmitJobRequest) (*Job, error)
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+
+	CancelJob(context.Context, *CancelJobRequest) (*Job, error)
+
+	ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error)
 	mustEmbedUnimplementedOcrServiceServer()
 }
 
@@ -45,6 +158,27 @@ type UnimplementedOcrServiceServer struct{}
 func (UnimplementedOcrServiceServer) Process(context.Context, *ParseRequest) (*ParseResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Process not implemented")
 }
+func (UnimplementedOcrServiceServer) Upload(grpc.ClientStreamingServer[UploadRequest, ParseResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Upload not implemented")
+}
+func (UnimplementedOcrServiceServer) ProcessStream(*ParseRequest, grpc.ServerStreamingServer[ProcessStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ProcessStream not implemented")
+}
+func (UnimplementedOcrServiceServer) BatchProcess(context.Context, *BatchProcessRequest) (*BatchProcessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchProcess not implemented")
+}
+func (UnimplementedOcrServiceServer) SubmitJob(context.Context, *SubmitJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitJob not implemented")
+}
+func (UnimplementedOcrServiceServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedOcrServiceServer) CancelJob(context.Context, *CancelJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelJob not implemented")
+}
+func (UnimplementedOcrServiceServer) ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListJobs not implemented")
+}
 func (UnimplementedOcrServiceServer) mustEmbedUnimplementedOcrServiceServer() {}
 func (UnimplementedOcrServiceServer) testEmbeddedByValue()                    {}
 
@@ -78,6 +212,112 @@ func _OcrService_Process_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OcrService_Upload_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(OcrServiceServer).Upload(&grpc.GenericServerStream[UploadRequest, ParseResponse]{ServerStream: stream})
+}
+
+type OcrService_UploadServer = grpc.ClientStreamingServer[UploadRequest, ParseResponse]
+
+func _OcrService_ProcessStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ParseRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OcrServiceServer).ProcessStream(m, &grpc.GenericServerStream[ParseRequest, ProcessStreamResponse]{ServerStream: stream})
+}
+
+type OcrService_ProcessStreamServer = grpc.ServerStreamingServer[ProcessStreamResponse]
+
+func _OcrService_BatchProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OcrServiceServer).BatchProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OcrService_BatchProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OcrServiceServer).BatchProcess(ctx, req.(*BatchProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OcrService_SubmitJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OcrServiceServer).SubmitJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OcrService_SubmitJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OcrServiceServer).SubmitJob(ctx, req.(*SubmitJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OcrService_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OcrServiceServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OcrService_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OcrServiceServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OcrService_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OcrServiceServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OcrService_CancelJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OcrServiceServer).CancelJob(ctx, req.(*CancelJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OcrService_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OcrServiceServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OcrService_ListJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OcrServiceServer).ListJobs(ctx, req.(*ListJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var OcrService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "ocr.v1.OcrService",
 	HandlerType: (*OcrServiceServer)(nil),
@@ -86,7 +326,38 @@ var OcrService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Process",
 			Handler:    _OcrService_Process_Handler,
 		},
+		{
+			MethodName: "BatchProcess",
+			Handler:    _OcrService_BatchProcess_Handler,
+		},
+		{
+			MethodName: "SubmitJob",
+			Handler:    _OcrService_SubmitJob_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _OcrService_GetJob_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _OcrService_CancelJob_Handler,
+		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _OcrService_ListJobs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Upload",
+			Handler:       _OcrService_Upload_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ProcessStream",
+			Handler:       _OcrService_ProcessStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/presentation/proto/ocr/v1/ocr.proto",
 }
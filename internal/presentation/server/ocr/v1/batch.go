@@ -0,0 +1,67 @@
+package ocr
+
+import (
+	"context"
+	"strings"
+
+	"doc2text/internal/core/abstraction/cqrs"
+	"doc2text/internal/core/usecase/extracttext"
+	ocrv1 "doc2text/internal/presentation/proto/ocr/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Service) BatchProcess(ctx context.Context, req *ocrv1.BatchProcessRequest) (*ocrv1.BatchProcessResponse, error) {
+	keys := req.GetObjectkeys()
+	if len(keys) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "objectkeys is required")
+	}
+	if s.maxBatchItems > 0 && len(keys) > s.maxBatchItems {
+		return nil, status.Errorf(codes.InvalidArgument, "batch of %d items exceeds the limit of %d", len(keys), s.maxBatchItems)
+	}
+
+	base := extracttext.Query{Backend: strings.TrimSpace(req.GetBackend())}
+	if req.MinConfidence != nil {
+		minConf, err := validMinConfidence(req.GetMinConfidence())
+		if err != nil {
+			return nil, err
+		}
+		base.MinConfidence = &minConf
+	}
+
+	// Blank keys fail on their own; the rest still run.
+	out := make([]*ocrv1.BatchItem, len(keys))
+	var (
+		queries []extracttext.Query
+		index   []int
+	)
+	for i, key := range keys {
+		key = strings.TrimSpace(key)
+		out[i] = &ocrv1.BatchItem{Objectkey: key}
+		if key == "" {
+			out[i].Code = int32(codes.InvalidArgument)
+			out[i].Error = "objectkey is required"
+			continue
+		}
+		q := base
+		q.ObjectKey = key
+		queries = append(queries, q)
+		index = append(index, i)
+	}
+
+	res, err := cqrs.Ask[extracttext.BatchQuery, extracttext.BatchResult](s.bus, ctx, extracttext.BatchQuery{Items: queries})
+	if err != nil {
+		return nil, err
+	}
+	for j, item := range res.Items {
+		o := out[index[j]]
+		if item.Err != nil {
+			st := status.Convert(statusError(item.Err))
+			o.Code = int32(st.Code())
+			o.Error = st.Message()
+			continue
+		}
+		o.Result = toProtoResponse(item.Result)
+	}
+	return &ocrv1.BatchProcessResponse{Items: out}, nil
+}
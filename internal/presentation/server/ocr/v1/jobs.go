@@ -0,0 +1,194 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"doc2text/internal/core/abstraction/cqrs"
+	"doc2text/internal/core/abstraction/job"
+	"doc2text/internal/core/usecase/extractjobs"
+	ocrv1 "doc2text/internal/presentation/proto/ocr/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxJobsPageSize = 500
+
+var jobStates = map[job.State]ocrv1.JobState{
+	job.StateQueued:    ocrv1.JobState_JOB_STATE_QUEUED,
+	job.StateRunning:   ocrv1.JobState_JOB_STATE_RUNNING,
+	job.StateSucceeded: ocrv1.JobState_JOB_STATE_SUCCEEDED,
+	job.StateFailed:    ocrv1.JobState_JOB_STATE_FAILED,
+	job.StateCancelled: ocrv1.JobState_JOB_STATE_CANCELLED,
+}
+
+func (s *Service) SubmitJob(ctx context.Context, req *ocrv1.SubmitJobRequest) (*ocrv1.Job, error) {
+	if !s.jobs {
+		return nil, errJobsDisabled
+	}
+	q, err := parseQuery(&ocrv1.ParseRequest{
+		Objectkey:     req.GetObjectkey(),
+		MinConfidence: req.MinConfidence,
+		Backend:       req.GetBackend(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	callbackURL, err := s.validCallbackURL(req.GetCallbackUrl())
+	if err != nil {
+		return nil, err
+	}
+	j, err := cqrs.Exec[extractjobs.SubmitJob, extractjobs.Job](s.bus, ctx, extractjobs.SubmitJob{
+		ObjectKey:     q.ObjectKey,
+		MinConfidence: q.MinConfidence,
+		Backend:       q.Backend,
+		CallbackURL:   callbackURL,
+	})
+	if errors.Is(err, extractjobs.ErrCallbacksDisabled) {
+		return nil, status.Error(codes.FailedPrecondition, "callbacks are disabled (WEBHOOK_SECRET is not set)")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toProtoJob(j), nil
+}
+
+func (s *Service) GetJob(ctx context.Context, req *ocrv1.GetJobRequest) (*ocrv1.Job, error) {
+	if !s.jobs {
+		return nil, errJobsDisabled
+	}
+	id, err := jobID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	j, err := cqrs.Ask[extractjobs.GetJob, extractjobs.Job](s.bus, ctx, extractjobs.GetJob{ID: id})
+	if err != nil {
+		return nil, jobError(err)
+	}
+	return toProtoJob(j), nil
+}
+
+func (s *Service) CancelJob(ctx context.Context, req *ocrv1.CancelJobRequest) (*ocrv1.Job, error) {
+	if !s.jobs {
+		return nil, errJobsDisabled
+	}
+	id, err := jobID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	j, err := cqrs.Exec[extractjobs.CancelJob, extractjobs.Job](s.bus, ctx, extractjobs.CancelJob{ID: id})
+	if err != nil {
+		return nil, jobError(err)
+	}
+	return toProtoJob(j), nil
+}
+
+func (s *Service) ListJobs(ctx context.Context, req *ocrv1.ListJobsRequest) (*ocrv1.ListJobsResponse, error) {
+	if !s.jobs {
+		return nil, errJobsDisabled
+	}
+	q := extractjobs.ListJobs{Limit: int(req.GetPageSize()), After: req.GetPageToken()}
+	if q.Limit < 0 || q.Limit > maxJobsPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be within [0, %d]", maxJobsPageSize)
+	}
+	if st := req.GetState(); st != ocrv1.JobState_JOB_STATE_UNSPECIFIED {
+		for k, v := range jobStates {
+			if v == st {
+				q.State = k
+			}
+		}
+		if q.State == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown state %v", st)
+		}
+	}
+	list, err := cqrs.Ask[extractjobs.ListJobs, extractjobs.JobList](s.bus, ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	out := &ocrv1.ListJobsResponse{Jobs: make([]*ocrv1.Job, 0, len(list.Jobs)), NextPageToken: list.Next}
+	for _, j := range list.Jobs {
+		out.Jobs = append(out.Jobs, toProtoJob(j))
+	}
+	return out, nil
+}
+
+var errJobsDisabled = status.Error(codes.Unimplemented, "job API is disabled (JOBS_ENABLED=false)")
+
+// validCallbackURL accepts absolute http(s) URLs, limited to the allowed
+// hosts when any are configured.
+func (s *Service) validCallbackURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", status.Error(codes.InvalidArgument, "callback_url must be an absolute http(s) URL")
+	}
+	if len(s.callbackHosts) > 0 && !slices.Contains(s.callbackHosts, strings.ToLower(u.Hostname())) {
+		return "", status.Errorf(codes.InvalidArgument, "callback host %q is not allowed", u.Hostname())
+	}
+	return raw, nil
+}
+
+func jobID(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", status.Error(codes.InvalidArgument, "id is required")
+	}
+	return id, nil
+}
+
+func jobError(err error) error {
+	if errors.Is(err, job.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return err
+}
+
+func toProtoJob(j extractjobs.Job) *ocrv1.Job {
+	out := &ocrv1.Job{
+		Id:         j.ID,
+		State:      jobStates[j.State],
+		Objectkey:  j.ObjectKey,
+		Error:      j.Error,
+		CreatedAt:  toProtoTime(j.CreatedAt),
+		StartedAt:  toProtoTime(j.StartedAt),
+		FinishedAt: toProtoTime(j.FinishedAt),
+		ExpiresAt:  toProtoTime(j.ExpiresAt),
+		OutputKey:  j.OutputKey,
+	}
+	if j.Result != nil {
+		out.Result = toProtoResponse(*j.Result)
+	}
+	out.CallbackUrl = j.CallbackURL
+	out.CallbackState = j.Callback
+	for _, d := range j.Deliveries {
+		out.Deliveries = append(out.Deliveries, &ocrv1.Delivery{
+			At:       toProtoTime(d.At),
+			Duration: durationpb.New(d.Duration),
+			Status:   int32(d.Status),
+			Error:    d.Error,
+		})
+	}
+	return out
+}
+
+// JobJSON renders a job the way GetJob returns it, for callback bodies.
+func JobJSON(j extractjobs.Job) ([]byte, error) {
+	return protojson.Marshal(toProtoJob(j))
+}
+
+func toProtoTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
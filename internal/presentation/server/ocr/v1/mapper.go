@@ -0,0 +1,104 @@
+package ocr
+
+import (
+	"doc2text/internal/core/abstraction/recognize"
+	"doc2text/internal/core/usecase/extracttext"
+	ocrv1 "doc2text/internal/presentation/proto/ocr/v1"
+)
+
+func toProtoEntries(entries []extracttext.Entry) []*ocrv1.Entry {
+	out := make([]*ocrv1.Entry, 0, len(entries))
+	for _, e := range entries {
+		entry := &ocrv1.Entry{
+			Path:     e.Path,
+			MimeType: e.MimeType,
+			Text:     e.Result.Text,
+			Pages:    toProtoPages(e.Result.Pages),
+			Backend:  e.Result.Backend,
+			Cached:   e.Result.Cached,
+		}
+		if e.Err != nil {
+			entry.Error = e.Err.Error()
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func toProtoPageEvent(ev extracttext.PageEvent) *ocrv1.PageEvent {
+	out := &ocrv1.PageEvent{
+		Entry:   ev.Entry,
+		Index:   int32(ev.Index),
+		Total:   int32(ev.Total),
+		Backend: ev.Backend,
+	}
+	if ev.Err != nil {
+		out.Error = ev.Err.Error()
+		return out
+	}
+	out.Text = ev.Text
+	out.Page = toProtoPage(ev.Page)
+	return out
+}
+
+func toProtoPages(pages []recognize.Page) []*ocrv1.Page {
+	out := make([]*ocrv1.Page, 0, len(pages))
+	for _, p := range pages {
+		out = append(out, toProtoPage(p))
+	}
+	return out
+}
+
+func toProtoPage(p recognize.Page) *ocrv1.Page {
+	page := &ocrv1.Page{
+		Width:    p.Width,
+		Height:   p.Height,
+		Source:   p.Source,
+		Label:    p.Label,
+		Rotation: int32(p.Rotation),
+		Skew:     p.Skew,
+		Blocks:   make([]*ocrv1.Block, 0, len(p.Blocks)),
+	}
+	for _, b := range p.Blocks {
+		block := &ocrv1.Block{
+			BoundingBox: toProtoBox(b.BoundingBox),
+			Languages:   toProtoLanguages(b.Languages),
+			Lines:       make([]*ocrv1.Line, 0, len(b.Lines)),
+		}
+		for _, ln := range b.Lines {
+			line := &ocrv1.Line{
+				Text:        ln.Text,
+				BoundingBox: toProtoBox(ln.BoundingBox),
+				Confidence:  ln.Confidence,
+				Words:       make([]*ocrv1.Word, 0, len(ln.Words)),
+			}
+			for _, w := range ln.Words {
+				line.Words = append(line.Words, &ocrv1.Word{
+					Text:        w.Text,
+					BoundingBox: toProtoBox(w.BoundingBox),
+					Confidence:  w.Confidence,
+					Languages:   toProtoLanguages(w.Languages),
+				})
+			}
+			block.Lines = append(block.Lines, line)
+		}
+		page.Blocks = append(page.Blocks, block)
+	}
+	return page
+}
+
+func toProtoBox(p recognize.Polygon) *ocrv1.BoundingBox {
+	box := &ocrv1.BoundingBox{Vertices: make([]*ocrv1.Vertex, 0, len(p.Vertices))}
+	for _, v := range p.Vertices {
+		box.Vertices = append(box.Vertices, &ocrv1.Vertex{X: v.X, Y: v.Y})
+	}
+	return box
+}
+
+func toProtoLanguages(ls []recognize.Language) []*ocrv1.DetectedLanguage {
+	out := make([]*ocrv1.DetectedLanguage, 0, len(ls))
+	for _, l := range ls {
+		out = append(out, &ocrv1.DetectedLanguage{LanguageCode: l.Code, Confidence: l.Confidence})
+	}
+	return out
+}
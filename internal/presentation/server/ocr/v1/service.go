@@ -2,33 +2,117 @@ package ocr
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"doc2text/internal/core/abstraction/cqrs"
+	"doc2text/internal/core/abstraction/normalize"
+	"doc2text/internal/core/abstraction/recognize"
+	"doc2text/internal/core/abstraction/unpack"
 	"doc2text/internal/core/usecase/extracttext"
 	ocrv1 "doc2text/internal/presentation/proto/ocr/v1"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
+type Options struct {
+	// MaxUploadSize caps the bytes accepted by Upload.
+	MaxUploadSize int64
+	// MaxBatchItems caps the object keys accepted by BatchProcess.
+	MaxBatchItems int
+	// Jobs enables the job API; its handlers must be on the bus.
+	Jobs bool
+	// CallbackHosts limits the hosts of job callback URLs; empty allows any.
+	CallbackHosts []string
+}
+
 type Service struct {
 	ocrv1.UnimplementedOcrServiceServer
-	bus *cqrs.Bus
+	bus           *cqrs.Bus
+	maxUploadSize int64
+	maxBatchItems int
+	jobs          bool
+	callbackHosts []string
 }
 
-func New(bus *cqrs.Bus) *Service { return &Service{bus: bus} }
+func New(bus *cqrs.Bus, o Options) *Service {
+	hosts := make([]string, 0, len(o.CallbackHosts))
+	for _, h := range o.CallbackHosts {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return &Service{bus: bus, maxUploadSize: o.MaxUploadSize, maxBatchItems: o.MaxBatchItems, jobs: o.Jobs, callbackHosts: hosts}
+}
 
 func (s *Service) Process(ctx context.Context, req *ocrv1.ParseRequest) (*ocrv1.ParseResponse, error) {
+	q, err := parseQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.ask(ctx, q)
+}
+
+func parseQuery(req *ocrv1.ParseRequest) (extracttext.Query, error) {
 	objectKey := strings.TrimSpace(req.GetObjectkey())
 	if objectKey == "" {
-		return nil, status.Error(codes.InvalidArgument, "objectkey is required")
+		return extracttext.Query{}, status.Error(codes.InvalidArgument, "objectkey is required")
 	}
 
-	q := extracttext.Query{ObjectKey: objectKey}
+	q := extracttext.Query{ObjectKey: objectKey, Backend: strings.TrimSpace(req.GetBackend())}
+	if req.MinConfidence != nil {
+		minConf, err := validMinConfidence(req.GetMinConfidence())
+		if err != nil {
+			return extracttext.Query{}, err
+		}
+		q.MinConfidence = &minConf
+	}
+	return q, nil
+}
 
+func (s *Service) ask(ctx context.Context, q extracttext.Query) (*ocrv1.ParseResponse, error) {
 	res, err := cqrs.Ask[extracttext.Query, extracttext.Result](s.bus, ctx, q)
 	if err != nil {
-		return nil, err
+		return nil, statusError(err)
+	}
+	return toProtoResponse(res), nil
+}
+
+func toProtoResponse(res extracttext.Result) *ocrv1.ParseResponse {
+	return &ocrv1.ParseResponse{
+		Text:          res.Text,
+		Pages:         toProtoPages(res.Pages),
+		FilteredWords: int32(res.FilteredWords),
+		FilteredLines: int32(res.FilteredLines),
+		Backend:       res.Backend,
+		Entries:       toProtoEntries(res.Entries),
+		Cached:        res.Cached,
+	}
+}
+
+// ResultJSON renders a result the way Process returns it, for sidecars.
+func ResultJSON(res extracttext.Result) ([]byte, error) {
+	return protojson.Marshal(toProtoResponse(res))
+}
+
+// statusError reports errors caused by the file itself, or by a backend hint
+// naming no backend, as InvalidArgument, and a converter missing from the
+// deployment as FailedPrecondition.
+func statusError(err error) error {
+	if errors.Is(err, unpack.ErrLimitExceeded) || errors.Is(err, recognize.ErrTooLarge) || errors.Is(err, recognize.ErrUnsupportedType) ||
+		errors.Is(err, recognize.ErrUnknownBackend) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, normalize.ErrConverterMissing) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
+
+func validMinConfidence(v float64) (float64, error) {
+	if v < 0 || v > 1 {
+		return 0, status.Error(codes.InvalidArgument, "min_confidence must be within [0, 1]")
 	}
-	return &ocrv1.ParseResponse{Text: res.Text}, nil
+	return v, nil
 }
@@ -0,0 +1,28 @@
+package ocr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"doc2text/internal/core/abstraction/normalize"
+	"doc2text/internal/core/abstraction/recognize"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusError(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want codes.Code
+	}{
+		{fmt.Errorf("imagenorm: %w: HEIC converter is not configured", recognize.ErrUnsupportedType), codes.InvalidArgument},
+		{fmt.Errorf("ocrchain: %w %q", recognize.ErrUnknownBackend, "x"), codes.InvalidArgument},
+		{fmt.Errorf("imagenorm: %w: magick", normalize.ErrConverterMissing), codes.FailedPrecondition},
+		{errors.New("boom"), codes.Unknown},
+	} {
+		if got := status.Code(statusError(tc.err)); got != tc.want {
+			t.Errorf("statusError(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
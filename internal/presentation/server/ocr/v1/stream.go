@@ -0,0 +1,58 @@
+package ocr
+
+import (
+	"context"
+
+	"doc2text/internal/core/abstraction/cqrs"
+	"doc2text/internal/core/usecase/extracttext"
+	ocrv1 "doc2text/internal/presentation/proto/ocr/v1"
+	"google.golang.org/grpc"
+)
+
+func (s *Service) ProcessStream(req *ocrv1.ParseRequest, stream grpc.ServerStreamingServer[ocrv1.ProcessStreamResponse]) error {
+	q, err := parseQuery(req)
+	if err != nil {
+		return err
+	}
+
+	// Pages are sent from inside the handler; a failed send stops it.
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	var (
+		sendErr       error
+		pages, failed int
+	)
+	q.OnPage = func(ev extracttext.PageEvent) {
+		if sendErr != nil {
+			return
+		}
+		pages++
+		if ev.Err != nil {
+			failed++
+		}
+		sendErr = stream.Send(&ocrv1.ProcessStreamResponse{
+			Event: &ocrv1.ProcessStreamResponse_Page{Page: toProtoPageEvent(ev)},
+		})
+		if sendErr != nil {
+			cancel()
+		}
+	}
+
+	res, err := cqrs.Ask[extracttext.Query, extracttext.Result](s.bus, ctx, q)
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return statusError(err)
+	}
+	return stream.Send(&ocrv1.ProcessStreamResponse{
+		Event: &ocrv1.ProcessStreamResponse_Summary{Summary: &ocrv1.StreamSummary{
+			Pages:         int32(pages),
+			FailedPages:   int32(failed),
+			FilteredWords: int32(res.FilteredWords),
+			FilteredLines: int32(res.FilteredLines),
+			Backend:       res.Backend,
+			Cached:        res.Cached,
+		}},
+	})
+}
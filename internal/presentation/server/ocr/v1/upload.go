@@ -0,0 +1,78 @@
+package ocr
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"doc2text/internal/core/usecase/extracttext"
+	"doc2text/internal/infrastructure/mimetypes"
+	ocrv1 "doc2text/internal/presentation/proto/ocr/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Service) Upload(stream grpc.ClientStreamingServer[ocrv1.UploadRequest, ocrv1.ParseResponse]) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "upload header is required")
+	}
+	if err != nil {
+		return err
+	}
+	hdr := first.GetHeader()
+	if hdr == nil {
+		return status.Error(codes.InvalidArgument, "the first message must carry the upload header")
+	}
+	if s.maxUploadSize > 0 && hdr.GetSize() > s.maxUploadSize {
+		return status.Errorf(codes.ResourceExhausted, "upload of %d bytes exceeds the limit of %d", hdr.GetSize(), s.maxUploadSize)
+	}
+
+	q := extracttext.Query{Backend: strings.TrimSpace(hdr.GetBackend())}
+	if hdr.MinConfidence != nil {
+		minConf, err := validMinConfidence(hdr.GetMinConfidence())
+		if err != nil {
+			return err
+		}
+		q.MinConfidence = &minConf
+	}
+
+	var buf bytes.Buffer
+	if hdr.GetSize() > 0 {
+		buf.Grow(int(hdr.GetSize()))
+	}
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if msg.GetHeader() != nil {
+			return status.Error(codes.InvalidArgument, "upload header sent twice")
+		}
+		if s.maxUploadSize > 0 && int64(buf.Len()+len(msg.GetChunk())) > s.maxUploadSize {
+			return status.Errorf(codes.ResourceExhausted, "upload exceeds the limit of %d bytes", s.maxUploadSize)
+		}
+		buf.Write(msg.GetChunk())
+	}
+	if buf.Len() == 0 {
+		return status.Error(codes.InvalidArgument, "upload is empty")
+	}
+
+	name := strings.TrimSpace(hdr.GetFilename())
+	mimeType := strings.TrimSpace(hdr.GetMimeType())
+	if mimeType == "" || mimeType == mimetypes.Default {
+		mimeType = mimetypes.Detect(name, buf.Bytes())
+	}
+	q.Upload = &extracttext.Upload{Name: name, MimeType: mimeType, Content: buf.Bytes()}
+
+	res, err := s.ask(stream.Context(), q)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(res)
+}
@@ -0,0 +1,242 @@
+package ocr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"doc2text/internal/core/abstraction/cqrs"
+	"doc2text/internal/core/abstraction/logger"
+	"doc2text/internal/core/abstraction/queue"
+	"doc2text/internal/core/usecase/extracttext"
+	ocrv1 "doc2text/internal/presentation/proto/ocr/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Message headers set on results and dead letters.
+const (
+	HeaderCorrelationID = "correlation-id"
+	HeaderError         = "error"
+)
+
+const maxBackoff = 30 * time.Second
+
+type WorkerOptions struct {
+	// ResultTopic may be empty when results only go back to requesters.
+	ResultTopic     string
+	DeadLetterTopic string
+	// MaxAttempts bounds runs of a request that keeps failing for reasons
+	// other than the file itself; Backoff is the pause between them. When
+	// the broker can redeliver, retries are naked back to it with a
+	// doubling delay instead of being run in place.
+	MaxAttempts int
+	Backoff     time.Duration
+	Logger      logger.Logger
+}
+
+// Worker serves OcrRequests from a queue instead of gRPC. A request is
+// acked only after its result, and its dead letter if any, are published,
+// so every request gets at least one result. Request/reply messages are
+// also answered directly.
+type Worker struct {
+	bus  *cqrs.Bus
+	sub  queue.Subscription
+	pub  queue.Publisher
+	opts WorkerOptions
+}
+
+func NewWorker(bus *cqrs.Bus, sub queue.Subscription, pub queue.Publisher, o WorkerOptions) *Worker {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 1
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = time.Second
+	}
+	return &Worker{bus: bus, sub: sub, pub: pub, opts: o}
+}
+
+// Run handles messages until ctx is done.
+func (w *Worker) Run(ctx context.Context) error {
+	for {
+		d, err := w.sub.Receive(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			w.opts.Logger.Error("worker: receive: %v", err)
+			if !sleep(ctx, w.opts.Backoff) {
+				return nil
+			}
+			continue
+		}
+		if !w.handle(ctx, d) {
+			// Interrupted; the message stays unacked and comes back, sooner
+			// when the broker takes it back right away.
+			if d.Nak != nil {
+				if err := d.Nak(context.Background(), 0); err != nil {
+					w.opts.Logger.Error("worker: nak: %v", err)
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// handle reports false when ctx ends before the outcome is published.
+func (w *Worker) handle(ctx context.Context, d queue.Delivery) bool {
+	req := &ocrv1.OcrRequest{}
+	if err := protojson.Unmarshal(d.Value, req); err != nil {
+		w.opts.Logger.Error("worker: decode request (key=%q): %v", d.Key, err)
+		err = fmt.Errorf("malformed request: %w", err)
+		out := &ocrv1.OcrResult{
+			CorrelationId: correlationID(nil, d.Message),
+			Error:         &ocrv1.OcrError{Code: codes.InvalidArgument.String(), Message: err.Error()},
+		}
+		return w.reply(ctx, d, out) && w.deadLetter(ctx, d.Message, err) && w.ack(ctx, d)
+	}
+	corr := correlationID(req, d.Message)
+	out := &ocrv1.OcrResult{CorrelationId: corr, Objectkey: req.GetObjectkey()}
+
+	q, err := parseQuery(&ocrv1.ParseRequest{
+		Objectkey:     req.GetObjectkey(),
+		MinConfidence: req.MinConfidence,
+		Backend:       req.GetBackend(),
+	})
+	var res extracttext.Result
+	for attempt := max(d.Attempt, 1); err == nil; attempt++ {
+		res, err = cqrs.Ask[extracttext.Query, extracttext.Result](w.bus, ctx, q)
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+		err = statusError(err)
+		if status.Code(err) == codes.InvalidArgument || attempt >= w.opts.MaxAttempts {
+			break
+		}
+		w.opts.Logger.Error("worker: %s (attempt %d): %v", corr, attempt, err)
+		if d.Nak != nil {
+			if nerr := d.Nak(ctx, w.redeliveryDelay(attempt)); nerr == nil {
+				return true
+			} else if ctx.Err() == nil {
+				w.opts.Logger.Error("worker: nak %s: %v", corr, nerr)
+			}
+		}
+		if !sleep(ctx, w.opts.Backoff) {
+			return false
+		}
+		err = nil
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		st := status.Convert(err)
+		out.Error = &ocrv1.OcrError{Code: st.Code().String(), Message: st.Message()}
+	} else {
+		out.Response = toProtoResponse(res)
+	}
+	if !w.reply(ctx, d, out) {
+		return false
+	}
+	// Requests that failed for other reasons than the file are kept for
+	// replay.
+	if err != nil && status.Code(err) != codes.InvalidArgument && !w.deadLetter(ctx, d.Message, err) {
+		return false
+	}
+	return w.ack(ctx, d)
+}
+
+// reply answers the requester, if one waits, and publishes the result.
+func (w *Worker) reply(ctx context.Context, d queue.Delivery, out *ocrv1.OcrResult) bool {
+	corr := out.GetCorrelationId()
+	body, err := protojson.Marshal(out)
+	if err != nil {
+		w.opts.Logger.Error("worker: encode result %s: %v", corr, err)
+		return w.deadLetter(ctx, d.Message, err)
+	}
+	msg := queue.Message{Key: []byte(corr), Value: body, Headers: map[string]string{HeaderCorrelationID: corr}}
+	if d.Respond != nil {
+		// The requester gives up on its own; a lost reply is not retried.
+		if err := d.Respond(ctx, msg); err != nil && ctx.Err() == nil {
+			w.opts.Logger.Error("worker: reply %s: %v", corr, err)
+		}
+	}
+	if w.opts.ResultTopic == "" {
+		return ctx.Err() == nil
+	}
+	return w.publish(ctx, w.opts.ResultTopic, msg)
+}
+
+// redeliveryDelay doubles Backoff with every attempt, up to maxBackoff.
+func (w *Worker) redeliveryDelay(attempt int) time.Duration {
+	delay := w.opts.Backoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+func (w *Worker) ack(ctx context.Context, d queue.Delivery) bool {
+	if err := d.Ack(ctx); err != nil && ctx.Err() == nil {
+		w.opts.Logger.Error("worker: ack: %v", err)
+	}
+	return true
+}
+
+func (w *Worker) deadLetter(ctx context.Context, m queue.Message, cause error) bool {
+	if w.opts.DeadLetterTopic == "" {
+		return true
+	}
+	headers := make(map[string]string, len(m.Headers)+1)
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+	headers[HeaderError] = cause.Error()
+	return w.publish(ctx, w.opts.DeadLetterTopic, queue.Message{Key: m.Key, Value: m.Value, Headers: headers})
+}
+
+// publish retries until the broker takes the message or ctx ends.
+func (w *Worker) publish(ctx context.Context, topic string, m queue.Message) bool {
+	delay := w.opts.Backoff
+	for {
+		err := w.pub.Publish(ctx, topic, m)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		w.opts.Logger.Error("worker: publish to %s: %v", topic, err)
+		if !sleep(ctx, delay) {
+			return false
+		}
+		delay = min(delay*2, maxBackoff)
+	}
+}
+
+func correlationID(req *ocrv1.OcrRequest, m queue.Message) string {
+	if id := strings.TrimSpace(req.GetCorrelationId()); id != "" {
+		return id
+	}
+	if id := strings.TrimSpace(m.Headers[HeaderCorrelationID]); id != "" {
+		return id
+	}
+	return string(m.Key)
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
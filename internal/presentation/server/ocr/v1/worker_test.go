@@ -0,0 +1,294 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"doc2text/internal/core/abstraction/cqrs"
+	"doc2text/internal/core/abstraction/queue"
+	"doc2text/internal/core/abstraction/recognize"
+	"doc2text/internal/core/usecase/extracttext"
+	ocrv1 "doc2text/internal/presentation/proto/ocr/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// fakeBroker is the subscription and the publisher of a worker. Everything
+// the worker does to it and to its deliveries goes to one log, so tests can
+// check the order.
+type fakeBroker struct {
+	mu          sync.Mutex
+	log         []string
+	published   map[string][]queue.Message
+	failPublish int // publishes to fail before accepting one
+	pending     chan queue.Delivery
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{published: map[string][]queue.Message{}, pending: make(chan queue.Delivery, 8)}
+}
+
+func (b *fakeBroker) record(event string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.log = append(b.log, event)
+}
+
+func (b *fakeBroker) events() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Join(b.log, " ")
+}
+
+func (b *fakeBroker) Receive(ctx context.Context) (queue.Delivery, error) {
+	select {
+	case d := <-b.pending:
+		return d, nil
+	case <-ctx.Done():
+		return queue.Delivery{}, ctx.Err()
+	}
+}
+
+func (b *fakeBroker) Publish(_ context.Context, topic string, m queue.Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failPublish > 0 {
+		b.failPublish--
+		b.log = append(b.log, "fail:"+topic)
+		return errors.New("broker unavailable")
+	}
+	b.log = append(b.log, "publish:"+topic)
+	b.published[topic] = append(b.published[topic], m)
+	return nil
+}
+
+func (b *fakeBroker) Close() error { return nil }
+
+// delivery builds a message whose Ack, Nak (when nak is set) and Respond
+// are logged by b.
+func (b *fakeBroker) delivery(value string, attempt int, nak bool) queue.Delivery {
+	d := queue.Delivery{
+		Message: queue.Message{Key: []byte("key-1"), Value: []byte(value), Headers: map[string]string{HeaderCorrelationID: "corr-1"}},
+		Attempt: attempt,
+		Ack: func(context.Context) error {
+			b.record("ack")
+			return nil
+		},
+		Respond: func(context.Context, queue.Message) error {
+			b.record("respond")
+			return nil
+		},
+	}
+	if nak {
+		d.Nak = func(_ context.Context, delay time.Duration) error {
+			b.record(fmt.Sprintf("nak:%v", delay))
+			return nil
+		}
+	}
+	return d
+}
+
+func (b *fakeBroker) result(t *testing.T) *ocrv1.OcrResult {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	msgs := b.published["results"]
+	if len(msgs) != 1 {
+		t.Fatalf("%d results published, want 1", len(msgs))
+	}
+	out := &ocrv1.OcrResult{}
+	if err := protojson.Unmarshal(msgs[0].Value, out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// scriptedHandler answers queries with errs in turn, then with a result.
+type scriptedHandler struct {
+	mu    sync.Mutex
+	errs  []error
+	calls int
+}
+
+func (h *scriptedHandler) Handle(ctx context.Context, q extracttext.Query) (extracttext.Result, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	if h.calls <= len(h.errs) {
+		return extracttext.Result{}, h.errs[h.calls-1]
+	}
+	return extracttext.Result{Text: "text of " + q.ObjectKey}, nil
+}
+
+func newTestWorker(h cqrs.QueryHandler[extracttext.Query, extracttext.Result], b *fakeBroker, maxAttempts int) *Worker {
+	bus := cqrs.NewBus()
+	cqrs.RegisterQuery[extracttext.Query, extracttext.Result](bus, h)
+	return NewWorker(bus, b, b, WorkerOptions{
+		ResultTopic:     "results",
+		DeadLetterTopic: "dlq",
+		MaxAttempts:     maxAttempts,
+		Backoff:         time.Millisecond,
+		Logger:          nopLogger{},
+	})
+}
+
+const request = `{"objectkey":"a.pdf"}`
+
+func TestWorkerAcksAfterPublish(t *testing.T) {
+	b := newFakeBroker()
+	b.failPublish = 2
+	w := newTestWorker(&scriptedHandler{}, b, 1)
+	if !w.handle(context.Background(), b.delivery(request, 1, true)) {
+		t.Fatal("handle() = false")
+	}
+	if got, want := b.events(), "respond fail:results fail:results publish:results ack"; got != want {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+	out := b.result(t)
+	if out.GetCorrelationId() != "corr-1" || out.GetError() != nil || out.GetResponse().GetText() != "text of a.pdf" {
+		t.Fatalf("result = %v", out)
+	}
+}
+
+func TestWorkerDeadLetters(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		err    error
+		code   codes.Code
+		events string
+	}{
+		{"failure", errors.New("boom"), codes.Unknown, "respond publish:results publish:dlq ack"},
+		// Retrying a file that is too large cannot help, nor can replaying it.
+		{"invalid file", fmt.Errorf("imagenorm: %w", recognize.ErrTooLarge), codes.InvalidArgument, "respond publish:results ack"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newFakeBroker()
+			w := newTestWorker(&scriptedHandler{errs: []error{tc.err}}, b, 1)
+			if !w.handle(context.Background(), b.delivery(request, 1, true)) {
+				t.Fatal("handle() = false")
+			}
+			if got := b.events(); got != tc.events {
+				t.Fatalf("events = %q, want %q", got, tc.events)
+			}
+			if out := b.result(t); out.GetError().GetCode() != tc.code.String() || out.GetResponse() != nil {
+				t.Fatalf("result = %v, want code %v", out, tc.code)
+			}
+			if dl := b.published["dlq"]; len(dl) == 1 {
+				if string(dl[0].Value) != request || dl[0].Headers[HeaderError] != "boom" || dl[0].Headers[HeaderCorrelationID] != "corr-1" {
+					t.Fatalf("dead letter = %+v", dl[0])
+				}
+			}
+		})
+	}
+}
+
+func TestWorkerRetriesInPlace(t *testing.T) {
+	b := newFakeBroker()
+	h := &scriptedHandler{errs: []error{errors.New("busy"), errors.New("busy")}}
+	w := newTestWorker(h, b, 3)
+	// Without Nak the broker cannot delay a redelivery, so the worker
+	// retries itself.
+	if !w.handle(context.Background(), b.delivery(request, 0, false)) {
+		t.Fatal("handle() = false")
+	}
+	if h.calls != 3 {
+		t.Fatalf("handler ran %d times, want 3", h.calls)
+	}
+	if got, want := b.events(), "respond publish:results ack"; got != want {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+	if out := b.result(t); out.GetError() != nil {
+		t.Fatalf("result = %v", out)
+	}
+}
+
+func TestWorkerNaksWithDelay(t *testing.T) {
+	for _, tc := range []struct {
+		attempt int
+		events  string
+	}{
+		{1, "nak:1ms"},
+		{2, "nak:2ms"},
+		// The last attempt gets a result and a dead letter instead.
+		{3, "respond publish:results publish:dlq ack"},
+	} {
+		b := newFakeBroker()
+		h := &scriptedHandler{errs: []error{errors.New("busy")}}
+		w := newTestWorker(h, b, 3)
+		if !w.handle(context.Background(), b.delivery(request, tc.attempt, true)) {
+			t.Fatal("handle() = false")
+		}
+		if got := b.events(); got != tc.events {
+			t.Errorf("attempt %d: events = %q, want %q", tc.attempt, got, tc.events)
+		}
+		if h.calls != 1 {
+			t.Errorf("attempt %d: handler ran %d times, want 1", tc.attempt, h.calls)
+		}
+	}
+}
+
+// blockingQuery waits for the context of the query to end.
+type blockingQuery chan struct{}
+
+func (h blockingQuery) Handle(ctx context.Context, _ extracttext.Query) (extracttext.Result, error) {
+	close(h)
+	<-ctx.Done()
+	return extracttext.Result{}, ctx.Err()
+}
+
+func TestWorkerNaksOnShutdown(t *testing.T) {
+	b := newFakeBroker()
+	started := make(blockingQuery)
+	w := newTestWorker(started, b, 3)
+	b.pending <- b.delivery(request, 1, true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+	<-started
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run() = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after shutdown")
+	}
+	// Handed back at once, with nothing published or acked.
+	if got, want := b.events(), "nak:0s"; got != want {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+}
+
+func TestWorkerMalformedRequest(t *testing.T) {
+	b := newFakeBroker()
+	h := &scriptedHandler{}
+	w := newTestWorker(h, b, 3)
+	if !w.handle(context.Background(), b.delivery("{not json", 1, true)) {
+		t.Fatal("handle() = false")
+	}
+	if got, want := b.events(), "respond publish:results publish:dlq ack"; got != want {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+	if h.calls != 0 {
+		t.Fatalf("handler ran %d times for a malformed request", h.calls)
+	}
+	out := b.result(t)
+	if out.GetCorrelationId() != "corr-1" || out.GetError().GetCode() != codes.InvalidArgument.String() {
+		t.Fatalf("result = %v", out)
+	}
+	if dl := b.published["dlq"]; string(dl[0].Value) != "{not json" || !strings.Contains(dl[0].Headers[HeaderError], "malformed request") {
+		t.Fatalf("dead letter = %+v", dl[0])
+	}
+}